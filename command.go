@@ -0,0 +1,569 @@
+package main
+
+import (
+	"blog/internal/backup"
+	"blog/internal/dto/requests"
+	"blog/internal/handler"
+	"blog/internal/job"
+	"blog/internal/service"
+	"blog/internal/transfer"
+	"blog/internal/utils"
+	"blog/pkg/common"
+	"blog/pkg/configs"
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// 进程的退出码
+const (
+	exitOK      = 0 // 执行成功
+	exitFailure = 1 // 执行失败
+	exitUsage   = 2 // 参数错误
+)
+
+// command 命令行子命令
+type command struct {
+	name   string
+	usage  string                  // 用法说明
+	needs  resource                // 执行前需要初始化的资源
+	server bool                    // 启动服务，连接由服务关闭时释放
+	run    func(args []string) int // 返回进程的退出码
+}
+
+// commands 所有子命令，不带参数时执行 serve
+var commands = []command{
+	{name: "serve", usage: "启动服务", needs: useAll, server: true, run: serveCommand},
+	{name: "create-user", usage: "创建账号", needs: useDB, run: createUserCommand},
+	{name: "reset-password", usage: "重置账号密码", needs: useDB | useCache, run: resetPasswordCommand},
+	{name: "reindex", usage: "重建博客搜索索引", needs: useDB | useCache | useSearch, run: reindexCommand},
+	{name: "flush-views", usage: "将缓存中的浏览量写入数据库", needs: useDB | useCache, run: flushViewsCommand},
+	{name: "backup", usage: "备份数据库、上传文件和日志", needs: useDB | useCache, run: backupCommand},
+	{name: "restore", usage: "从备份文件恢复", needs: useDB | useCache, run: restoreCommand},
+	{name: "verify", usage: "校验备份文件", run: verifyCommand},
+	{name: "decrypt", usage: "解密加密的备份文件", run: decryptCommand},
+	{name: "export", usage: "导出站点数据", needs: useDB, run: exportCommand},
+	{name: "import", usage: "导入站点数据", needs: useDB | useCache, run: importCommand},
+	{name: "migrate", usage: "执行、回滚数据库迁移或查看迁移状态", needs: useDB, run: migrateCommand},
+}
+
+// runCommand 加载配置并初始化子命令需要的资源后执行，返回退出码；
+// 子命令不直接退出进程，由 main 在关闭连接、写入日志之后再退出
+func runCommand(name string, args []string) int {
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage(os.Stdout)
+		return exitOK
+	}
+
+	i := slices.IndexFunc(commands, func(c command) bool { return c.name == name })
+	if i < 0 {
+		fmt.Fprintf(os.Stderr, "未知命令: %s\n", name)
+		printUsage(os.Stderr)
+		return exitUsage
+	}
+
+	cmd := commands[i]
+	setup(cmd.needs)
+	if !cmd.server {
+		defer teardown()
+	}
+	return cmd.run(args)
+}
+
+// printUsage 输出所有子命令的用法
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "用法: blog [命令] [参数]，不带命令时启动服务\n可用命令:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-16s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintln(w, "使用 blog <命令> -h 查看命令的参数")
+}
+
+// roleNames 命令行中的角色名称
+var roleNames = map[string]common.RoleId{
+	"user":  common.UserRoleId,
+	"admin": common.AdminRoleId,
+	"super": common.SuperAdminRoleId,
+}
+
+// createUserCommand 直接创建账号，邮箱视为已验证，不指定密码时生成随机密码
+// 用法: blog create-user [-role user|admin|super] [-nickname 昵称] [-password 密码] <账号> <邮箱>
+func createUserCommand(args []string) int {
+	fs := flag.NewFlagSet("create-user", flag.ContinueOnError)
+	role := fs.String("role", "user", "角色: user、admin 或 super")
+	nickname := fs.String("nickname", "", "昵称，默认与账号相同")
+	password := fs.String("password", "", "密码，默认生成随机密码")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	roleID, ok := roleNames[*role]
+	if fs.NArg() != 2 || !ok {
+		fmt.Fprintln(os.Stderr, "用法: blog create-user [-role user|admin|super] [-nickname 昵称] [-password 密码] <账号> <邮箱>")
+		return exitUsage
+	}
+	if !requireDB() || !requireSchema() {
+		return exitFailure
+	}
+
+	newPassword, ok := randomPassword(*password)
+	if !ok {
+		return exitFailure
+	}
+	req := requests.CreateUserRequest{
+		Username: fs.Arg(0),
+		Email:    fs.Arg(1),
+		NickName: cmp.Or(*nickname, fs.Arg(0)),
+		Password: newPassword,
+		RoleID:   uint(roleID),
+	}
+	if !checkRequest(req) {
+		return exitUsage
+	}
+
+	user, err := service.NewUserService().CreateUser(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "创建账号失败: %v\n", err)
+		return exitFailure
+	}
+
+	fmt.Printf("创建账号成功，ID %d，账号 %s，角色 %s\n", user.ID, user.Username, *role)
+	if *password == "" {
+		fmt.Printf("密码: %s\n", req.Password)
+	}
+	return exitOK
+}
+
+// resetPasswordCommand 重置账号密码，已登录的会话失效，不指定密码时生成随机密码
+// 用法: blog reset-password [-password 新密码] <账号>
+func resetPasswordCommand(args []string) int {
+	fs := flag.NewFlagSet("reset-password", flag.ContinueOnError)
+	password := fs.String("password", "", "新密码，默认生成随机密码")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "用法: blog reset-password [-password 新密码] <账号>")
+		return exitUsage
+	}
+	if !requireDB() || !requireSchema() {
+		return exitFailure
+	}
+
+	newPassword, ok := randomPassword(*password)
+	if !ok {
+		return exitFailure
+	}
+	if len(newPassword) < 8 || len(newPassword) > 16 {
+		fmt.Fprintln(os.Stderr, "密码要在8-16个字符之间")
+		return exitUsage
+	}
+
+	if err := service.NewUserService().ResetPasswordByUsername(fs.Arg(0), newPassword); err != nil {
+		fmt.Fprintf(os.Stderr, "重置密码失败: %v\n", err)
+		return exitFailure
+	}
+
+	fmt.Println("重置密码成功")
+	if *password == "" {
+		fmt.Printf("新密码: %s\n", newPassword)
+	}
+	return exitOK
+}
+
+// reindexCommand 清空并重建博客搜索索引
+// 用法: blog reindex
+func reindexCommand(args []string) int {
+	if code, ok := parseFlags(flag.NewFlagSet("reindex", flag.ContinueOnError), args); !ok {
+		return code
+	}
+	if !requireDB() || !requireSchema() {
+		return exitFailure
+	}
+
+	if err := service.NewBlogService().InitSearch(); err != nil {
+		fmt.Fprintf(os.Stderr, "重建搜索索引失败: %v\n", err)
+		return exitFailure
+	}
+	fmt.Println("重建搜索索引完成")
+	return exitOK
+}
+
+// flushViewsCommand 将缓存中的浏览量写入数据库，与每日的浏览量落库任务共用任务锁
+// 用法: blog flush-views
+func flushViewsCommand(args []string) int {
+	if code, ok := parseFlags(flag.NewFlagSet("flush-views", flag.ContinueOnError), args); !ok {
+		return code
+	}
+	if !requireDB() || !requireSchema() {
+		return exitFailure
+	}
+
+	if configs.CONFIG.Redis.Disable {
+		fmt.Fprintln(os.Stderr, "未启用Redis，浏览量保存在服务进程内，请通过后台的任务管理执行")
+		return exitFailure
+	}
+
+	err := service.NewBlogService().InitEyeCount()
+	if errors.Is(err, job.ErrRunning) {
+		fmt.Fprintln(os.Stderr, "浏览量落库任务正在执行，请稍后再试")
+		return exitFailure
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "写入浏览量失败: %v\n", err)
+		return exitFailure
+	}
+	fmt.Println("写入浏览量完成")
+	return exitOK
+}
+
+// backupCommand 立即备份数据库、上传文件和日志，选项默认使用配置文件中的备份配置
+// 用法: blog backup [-compression gzip|zstd] [-include 模式,...] [-exclude 模式,...]
+func backupCommand(args []string) int {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	compression := fs.String("compression", "", "压缩方式: gzip 或 zstd")
+	include := fs.String("include", "", "只备份匹配的文件，多个用逗号分隔")
+	exclude := fs.String("exclude", "", "排除匹配的文件，多个用逗号分隔")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "用法: blog backup [-compression gzip|zstd] [-include 模式,...] [-exclude 模式,...]")
+		return exitUsage
+	}
+	if !requireDB() {
+		return exitFailure
+	}
+
+	req := requests.BackupRequest{
+		Compression: *compression,
+		Include:     splitList(*include),
+		Exclude:     splitList(*exclude),
+	}
+	if !checkRequest(req) {
+		return exitUsage
+	}
+
+	progress, err := service.NewBackupService().Backup(req)
+	if progress == nil || progress.Status != service.BackupSuccess {
+		fmt.Fprintf(os.Stderr, "备份失败: %v\n", err)
+		return exitFailure
+	}
+
+	fmt.Printf("备份完成: %s，大小 %d 字节\n", filepath.Join(configs.CONFIG.Backup.GetDir(), progress.Name), progress.Size)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "复制或清理备份失败: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// restoreCommand 从备份文件恢复数据库和文件，恢复数据库前需要先执行 blog migrate up 创建表结构
+// 用法: blog restore [-force] [-skip-db] [-skip-files] [-passphrase 口令] <备份文件>
+func restoreCommand(args []string) int {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	passphrase := fs.String("passphrase", "", "加密备份的口令，默认使用环境变量或配置文件中的口令")
+	force := fs.Bool("force", false, "目标数据库已有数据时仍然覆盖")
+	skipDB := fs.Bool("skip-db", false, "不恢复数据库")
+	skipFiles := fs.Bool("skip-files", false, "不恢复上传文件和日志")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "用法: blog restore [-force] [-skip-db] [-skip-files] [-passphrase 口令] <备份文件>")
+		return exitUsage
+	}
+	if !*skipDB && (!requireDB() || !requireSchema()) {
+		return exitFailure
+	}
+
+	result, err := service.NewBackupService().Restore(context.Background(), fs.Arg(0), service.RestoreOptions{
+		Database: !*skipDB,
+		Files:    !*skipFiles,
+		Force:    *force,
+
+		Passphrase: *passphrase,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "恢复失败: %v\n", err)
+		return exitFailure
+	}
+
+	fmt.Printf("恢复完成，文件 %d 个\n", result.Files)
+	for table, rows := range result.Tables {
+		fmt.Printf("  %s: %d 行\n", table, rows)
+	}
+	fmt.Println("如使用 Redis，请清空缓存后再启动服务")
+	return exitOK
+}
+
+// verifyCommand 校验备份文件的完整性，加密备份同时校验口令
+// 用法: blog verify [-passphrase 口令] <备份文件>
+func verifyCommand(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	passphrase := fs.String("passphrase", "", "加密备份的口令，默认使用环境变量或配置文件中的口令")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "用法: blog verify [-passphrase 口令] <备份文件>")
+		return exitUsage
+	}
+
+	manifest, err := service.NewBackupService().Verify(fs.Arg(0), *passphrase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "校验失败: %v\n", err)
+		return exitFailure
+	}
+
+	fmt.Printf("校验通过，创建于 %s，文件 %d 个，数据表 %d 张\n", manifest.CreatedAt.Format(time.DateTime), len(manifest.Files), len(manifest.Tables))
+	return exitOK
+}
+
+// decryptCommand 将加密备份解密为普通的压缩归档，用于在其他环境中手动恢复
+// 用法: blog decrypt [-passphrase 口令] [-o 输出文件] <备份文件>
+func decryptCommand(args []string) int {
+	fs := flag.NewFlagSet("decrypt", flag.ContinueOnError)
+	passphrase := fs.String("passphrase", "", "加密备份的口令，默认使用环境变量或配置文件中的口令")
+	output := fs.String("o", "", "输出文件，默认为去掉 .enc 后缀的文件名")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 1 || (*output == "" && !backup.Encrypted(fs.Arg(0))) {
+		fmt.Fprintln(os.Stderr, "用法: blog decrypt [-passphrase 口令] [-o 输出文件] <备份文件>")
+		return exitUsage
+	}
+	if *output == "" {
+		*output = strings.TrimSuffix(fs.Arg(0), backup.EncryptedExt)
+	}
+
+	if err := service.NewBackupService().Decrypt(fs.Arg(0), *output, *passphrase); err != nil {
+		fmt.Fprintf(os.Stderr, "解密失败: %v\n", err)
+		return exitFailure
+	}
+	fmt.Printf("解密完成: %s\n", *output)
+	return exitOK
+}
+
+// exportCommand 导出站点数据为 zip，每张表一个 NDJSON 文件
+// 用法: blog export [-tables 表名,...] [-o 输出文件]
+func exportCommand(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	tables := fs.String("tables", "", "需要导出的表，多个用逗号分隔，默认导出全部")
+	output := fs.String("o", "", "输出文件，默认为当前目录下的 export-时间.zip")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "用法: blog export [-tables 表名,...] [-o 输出文件]")
+		return exitUsage
+	}
+	if !requireDB() || !requireSchema() {
+		return exitFailure
+	}
+
+	var transferService = service.NewTransferService()
+	names := splitList(*tables)
+	if err := transferService.CheckTables(names); err != nil {
+		fmt.Fprintf(os.Stderr, "%v，可导出的表: %s\n", err, strings.Join(transferService.Tables(), ", "))
+		return exitUsage
+	}
+	if *output == "" {
+		*output = "export-" + time.Now().Format("20060102-150405") + transfer.Ext
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "创建输出文件失败: %v\n", err)
+		return exitFailure
+	}
+	manifest, err := transferService.Export(context.Background(), file, names)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*output)
+		fmt.Fprintf(os.Stderr, "导出失败: %v\n", err)
+		return exitFailure
+	}
+
+	fmt.Printf("导出完成: %s，数据表 %d 张\n", *output, len(manifest.Tables))
+	return exitOK
+}
+
+// importCommand 导入站点数据，与备份和恢复共用任务锁
+// 用法: blog import [-strategy skip|overwrite|merge] [-dry-run] <导出文件>
+func importCommand(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	strategy := fs.String("strategy", transfer.Skip, "已存在数据的处理策略: skip、overwrite 或 merge")
+	dryRun := fs.Bool("dry-run", false, "只生成报告，不写入数据")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "用法: blog import [-strategy skip|overwrite|merge] [-dry-run] <导出文件>")
+		return exitUsage
+	}
+	if !requireDB() || !requireSchema() {
+		return exitFailure
+	}
+
+	req := requests.ImportRequest{Strategy: *strategy, DryRun: *dryRun}
+	if !checkRequest(req) {
+		return exitUsage
+	}
+
+	report, err := service.NewTransferService().Import(context.Background(), fs.Arg(0), transfer.ImportOptions{Strategy: req.Strategy, DryRun: req.DryRun})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "导入失败: %v\n", err)
+		return exitFailure
+	}
+
+	if report.DryRun {
+		fmt.Printf("预览完成，策略 %s，未写入数据\n", report.Strategy)
+	} else {
+		fmt.Printf("导入完成，策略 %s\n", report.Strategy)
+	}
+	for _, table := range report.Tables {
+		fmt.Printf("  %s: 共 %d 行，新增 %d，更新 %d，跳过 %d，失败 %d\n", table.Table, table.Total, table.Inserted, table.Updated, table.Skipped, table.Failed)
+		for _, msg := range table.Errors {
+			fmt.Printf("    %s\n", msg)
+		}
+	}
+	if len(report.Unknown) > 0 {
+		fmt.Printf("不支持导入的表: %s\n", strings.Join(report.Unknown, ", "))
+	}
+	return exitOK
+}
+
+// migrateCommand 执行、回滚数据库迁移或查看迁移状态
+// 用法: blog migrate up | down [-steps n] | status
+func migrateCommand(args []string) int {
+	const usage = "用法: blog migrate up | down [-steps n] | status"
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		return exitUsage
+	}
+	if !requireDB() {
+		return exitFailure
+	}
+
+	var migration = service.NewMigrationService()
+	switch args[0] {
+	case "up":
+		if err := migration.Up(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "迁移失败: %v\n", err)
+			return exitFailure
+		}
+		fmt.Println("数据库已是最新版本")
+	case "down":
+		fs := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		steps := fs.Int("steps", 1, "回滚的迁移数量")
+		if code, ok := parseFlags(fs, args[1:]); !ok {
+			return code
+		}
+		if *steps < 1 || fs.NArg() != 0 {
+			fmt.Fprintln(os.Stderr, usage)
+			return exitUsage
+		}
+		if err := migration.Down(context.Background(), *steps); err != nil {
+			fmt.Fprintf(os.Stderr, "回滚失败: %v\n", err)
+			return exitFailure
+		}
+		fmt.Println("回滚完成")
+	case "status":
+		statuses, err := migration.Status()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "获取迁移状态失败: %v\n", err)
+			return exitFailure
+		}
+		for _, status := range statuses {
+			state, appliedAt := "未执行", ""
+			switch {
+			case status.Unknown:
+				state = "未知"
+			case status.Applied:
+				state = "已执行"
+			}
+			if status.Applied {
+				appliedAt = time.Unix(status.AppliedAt, 0).Format(time.DateTime)
+			}
+			fmt.Printf("%d  %-24s %-6s %s\n", status.Version, status.Name, state, appliedAt)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		return exitUsage
+	}
+	return exitOK
+}
+
+// parseFlags 解析子命令的参数，-h 输出参数说明后返回 exitOK，参数错误时返回 exitUsage
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	err := fs.Parse(args)
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK, false
+	case err != nil:
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+// checkRequest 校验命令行参数组成的请求，失败时输出错误信息
+func checkRequest(req any) bool {
+	if errs := handler.Validate(req); len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", err.FailedField, err.Message)
+		}
+		return false
+	}
+	return true
+}
+
+// requireSchema 数据库不是最新版本时不执行，避免按旧的表结构写入或导出数据
+func requireSchema() bool {
+	if err := service.NewMigrationService().Check(); err != nil {
+		fmt.Fprintf(os.Stderr, "数据库结构检查失败，请先执行 blog migrate up: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// randomPassword 生成12位的随机密码，password 不为空时直接返回
+func randomPassword(password string) (string, bool) {
+	if password != "" {
+		return password, true
+	}
+	password, err := utils.RandomToken(6)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "生成随机密码失败: %v\n", err)
+		return "", false
+	}
+	return password, true
+}
+
+// splitList 拆分逗号分隔的参数，忽略空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,488 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"gorm.io/gorm"
+)
+
+// ManifestVersion 备份清单的格式版本
+const ManifestVersion = 1
+
+// ManifestName 备份清单在归档中的文件名，作为最后一个条目写入
+const ManifestName = "manifest.json"
+
+// DatabaseDir 数据库导出在归档中的目录，每张表一个 NDJSON 文件
+const DatabaseDir = "database"
+
+// 压缩方式
+const (
+	Gzip = "gzip"
+	Zstd = "zstd"
+)
+
+// 备份阶段
+const (
+	PhaseScan     = "scan"     //统计文件
+	PhaseFiles    = "files"    //打包文件
+	PhaseDatabase = "database" //导出数据库
+	PhaseFinish   = "finish"   //写入清单
+)
+
+// progressInterval 两次进度回调的最小间隔，阶段变化时不受限制
+const progressInterval = time.Millisecond * 500
+
+// Source 需要备份的目录，Name 为归档中的目录名
+type Source struct {
+	Name string
+	Dir  string
+}
+
+// Options 备份选项
+type Options struct {
+	Path        string   // 输出文件路径
+	Compression string   // 压缩方式，默认 gzip
+	Sources     []Source // 需要备份的目录
+	DB          *gorm.DB // 需要导出的数据库，为 nil 时不导出
+	Include     []string // 只备份匹配的文件，为空时备份全部
+	Exclude     []string // 排除匹配的文件
+	Passphrase  string   // 加密口令，为空时不加密
+	Progress    func(Progress)
+}
+
+// Progress 备份进度
+type Progress struct {
+	Phase      string `json:"phase"`
+	Files      int    `json:"files"`
+	TotalFiles int    `json:"totalFiles"`
+	Bytes      int64  `json:"bytes"`
+	TotalBytes int64  `json:"totalBytes"`
+	Tables     int    `json:"tables"`
+	Current    string `json:"current"`
+}
+
+// Manifest 备份清单，记录每个文件的大小和 SHA-256
+type Manifest struct {
+	Version     int              `json:"version"`
+	CreatedAt   time.Time        `json:"createdAt"`
+	Compression string           `json:"compression"`
+	Files       []ManifestFile   `json:"files"`
+	Tables      map[string]int64 `json:"tables,omitempty"` // 各表导出的行数
+}
+
+// ManifestFile 清单中的文件
+type ManifestFile struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	SHA256  string    `json:"sha256"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// file 扫描到的待备份文件
+type file struct {
+	name string // 归档中的路径
+	path string // 本地路径
+	info fs.FileInfo
+}
+
+// builder 一次备份的状态
+type builder struct {
+	opts     Options
+	tw       *tar.Writer
+	manifest Manifest
+	progress Progress
+	reported time.Time
+}
+
+// Build 将目录和数据库导出打包为一个压缩归档，并在末尾写入清单；失败时删除未完成的文件
+func Build(ctx context.Context, opts Options) (*Manifest, error) {
+	if opts.Compression == "" {
+		opts.Compression = Gzip
+	}
+
+	tmp := opts.Path + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &builder{
+		opts:     opts,
+		manifest: Manifest{Version: ManifestVersion, CreatedAt: time.Now(), Compression: opts.Compression},
+	}
+
+	err = b.build(ctx, out)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, opts.Path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	return &b.manifest, nil
+}
+
+func (b *builder) build(ctx context.Context, out io.Writer) error {
+	var ew io.WriteCloser
+	if b.opts.Passphrase != "" {
+		var err error
+		if ew, err = NewEncryptWriter(out, b.opts.Passphrase); err != nil {
+			return err
+		}
+		out = ew
+	}
+
+	zw, err := NewCompressor(out, b.opts.Compression)
+	if err != nil {
+		return err
+	}
+	b.tw = tar.NewWriter(zw)
+
+	files, err := b.scan()
+	if err != nil {
+		return err
+	}
+
+	b.report(PhaseFiles, "")
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := b.addFile(f); err != nil {
+			return fmt.Errorf("打包 %s 失败: %w", f.path, err)
+		}
+	}
+
+	if b.opts.DB != nil {
+		b.report(PhaseDatabase, "")
+		if err := b.exportDatabase(ctx); err != nil {
+			return err
+		}
+	}
+
+	b.report(PhaseFinish, ManifestName)
+	data, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := b.write(ManifestName, b.manifest.CreatedAt, int64(len(data)), strings.NewReader(string(data))); err != nil {
+		return err
+	}
+
+	if err := b.tw.Close(); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if ew != nil {
+		return ew.Close()
+	}
+	return nil
+}
+
+// scan 遍历所有目录，统计需要备份的文件数量和大小
+func (b *builder) scan() ([]file, error) {
+	b.report(PhaseScan, "")
+
+	// 备份目录可能位于需要备份的目录内，需要跳过
+	output, _ := filepath.Abs(b.opts.Path)
+	outputDir := filepath.Dir(output)
+
+	var files []file
+	for _, src := range b.opts.Sources {
+		if _, err := os.Stat(src.Dir); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		err := filepath.WalkDir(src.Dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && p != src.Dir {
+				if abs, _ := filepath.Abs(p); abs == outputDir {
+					return filepath.SkipDir
+				}
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			if abs, _ := filepath.Abs(p); abs == output || abs == output+".tmp" {
+				return nil
+			}
+
+			rel, err := filepath.Rel(src.Dir, p)
+			if err != nil {
+				return err
+			}
+			name := path.Join(src.Name, filepath.ToSlash(rel))
+			if !Selected(name, b.opts.Include, b.opts.Exclude) {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			files = append(files, file{name: name, path: p, info: info})
+			b.progress.TotalFiles++
+			b.progress.TotalBytes += info.Size()
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// addFile 写入一个本地文件，按扫描时的大小写入，避免文件在打包期间被追加导致长度不一致
+func (b *builder) addFile(f file) error {
+	src, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	b.report(PhaseFiles, f.name)
+	if err := b.write(f.name, f.info.ModTime(), f.info.Size(), io.LimitReader(src, f.info.Size())); err != nil {
+		return err
+	}
+	b.progress.Files++
+	return nil
+}
+
+// write 写入一个条目并记录到清单
+func (b *builder) write(name string, modTime time.Time, size int64, r io.Reader) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    size,
+		ModTime: modTime,
+		Format:  tar.FormatPAX,
+	}
+	if err := b.tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(b.tw, hash, progressWriter{b}), r)
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return fmt.Errorf("文件大小变化，期望 %d 字节，实际 %d 字节", size, n)
+	}
+
+	if name != ManifestName {
+		b.manifest.Files = append(b.manifest.Files, ManifestFile{
+			Path:    name,
+			Size:    size,
+			SHA256:  hex.EncodeToString(hash.Sum(nil)),
+			ModTime: modTime,
+		})
+	}
+	return nil
+}
+
+// Snapshot 在同一个可重复读的只读事务中执行 fn，fn 中的所有查询看到的是事务开始时的同一份数据，
+// 逐表导出时表之间的关联不会因为导出期间的写入而不一致
+func Snapshot(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return db.WithContext(ctx).Transaction(fn, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+}
+
+// exportDatabase 在同一个快照中逐表导出为 NDJSON，先写入临时文件以获得条目大小
+func (b *builder) exportDatabase(ctx context.Context) error {
+	return Snapshot(ctx, b.opts.DB, func(tx *gorm.DB) error {
+		tables, err := tx.Migrator().GetTables()
+		if err != nil {
+			return err
+		}
+		slices.Sort(tables)
+
+		b.manifest.Tables = make(map[string]int64, len(tables))
+		for _, table := range tables {
+			name := tableEntry(table)
+			if !Selected(name, b.opts.Include, b.opts.Exclude) {
+				continue
+			}
+			b.report(PhaseDatabase, name)
+			if err := b.exportTable(tx, table, name); err != nil {
+				return fmt.Errorf("导出表 %s 失败: %w", table, err)
+			}
+			b.progress.Tables++
+		}
+		return nil
+	})
+}
+
+func (b *builder) exportTable(tx *gorm.DB, table, name string) error {
+	tmp, err := os.CreateTemp("", "backup-*.ndjson")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	rows, err := tx.Table(table).Rows()
+	if err != nil {
+		return err
+	}
+	count, err := WriteRows(rows, tmp)
+	if err != nil {
+		return err
+	}
+	b.manifest.Tables[table] = count
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return b.write(name, time.Now(), size, tmp)
+}
+
+// WriteRows 将查询结果逐行编码为 JSON 写入 w，返回行数
+func WriteRows(rows *sql.Rows, w io.Writer) (int64, error) {
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		count  int64
+		values = make([]any, len(columns))
+		ptrs   = make([]any, len(columns))
+		enc    = json.NewEncoder(w)
+	)
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return count, err
+		}
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			if data, ok := values[i].([]byte); ok {
+				row[column] = string(data)
+			} else {
+				row[column] = values[i]
+			}
+		}
+		if err := enc.Encode(row); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, rows.Err()
+}
+
+// report 回调当前进度，同一阶段内按间隔节流
+func (b *builder) report(phase, current string) {
+	if b.opts.Progress == nil {
+		return
+	}
+	now := time.Now()
+	if phase == b.progress.Phase && now.Sub(b.reported) < progressInterval {
+		b.progress.Current = current
+		return
+	}
+	b.progress.Phase = phase
+	b.progress.Current = current
+	b.reported = now
+	b.opts.Progress(b.progress)
+}
+
+// progressWriter 统计已写入的字节数
+type progressWriter struct {
+	b *builder
+}
+
+func (p progressWriter) Write(data []byte) (int, error) {
+	if p.b.progress.Phase == PhaseFiles {
+		p.b.progress.Bytes += int64(len(data))
+	}
+	return len(data), nil
+}
+
+// Selected 判断归档路径是否需要备份，exclude 优先于 include，include 为空时全部包含；
+// 不含 / 的规则与每一级目录名和文件名匹配，含 / 的规则与完整路径及其各级父目录匹配
+func Selected(name string, include, exclude []string) bool {
+	for _, pattern := range exclude {
+		if match(pattern, name) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, pattern := range include {
+		if match(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func match(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		for _, part := range strings.Split(name, "/") {
+			if ok, _ := path.Match(pattern, part); ok {
+				return true
+			}
+		}
+		return false
+	}
+	for p := name; p != "." && p != "/"; p = path.Dir(p) {
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCompressor 按压缩方式包装输出
+func NewCompressor(w io.Writer, compression string) (io.WriteCloser, error) {
+	switch compression {
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	case Zstd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("不支持的压缩方式: %s", compression)
+	}
+}
+
+// Ext 压缩方式对应的文件扩展名
+func Ext(compression string) string {
+	if compression == Zstd {
+		return ".tar.zst"
+	}
+	return ".tar.gz"
+}
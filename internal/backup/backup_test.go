@@ -0,0 +1,209 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// testDB 连接 BLOG_TEST_DSN 指定的 PostgreSQL 测试库，未设置时跳过
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("BLOG_TEST_DSN")
+	if dsn == "" {
+		t.Skip("未设置 BLOG_TEST_DSN")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	uploads := filepath.Join(dir, "uploads")
+	os.MkdirAll(filepath.Join(uploads, "cache"), 0755)
+	os.WriteFile(filepath.Join(uploads, "a.png"), []byte("image"), 0644)
+	os.WriteFile(filepath.Join(uploads, "b.txt"), []byte("text"), 0644)
+	os.WriteFile(filepath.Join(uploads, "cache", "c.png"), []byte("cached"), 0644)
+
+	output := filepath.Join(uploads, "backup.tar.gz")
+	manifest, err := Build(context.Background(), Options{
+		Path:    output,
+		Sources: []Source{{Name: "uploads", Dir: uploads}},
+		Include: []string{"*.png"},
+		Exclude: []string{"uploads/cache"},
+	})
+	if err != nil {
+		t.Fatalf("Build err = %v", err)
+	}
+	if len(manifest.Files) != 1 || manifest.Files[0].Path != "uploads/a.png" {
+		t.Fatalf("manifest files = %+v", manifest.Files)
+	}
+
+	f, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashes := map[string]string{}
+	var saved Manifest
+	tr := tar.NewReader(zr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Name == ManifestName {
+			if err := json.NewDecoder(tr).Decode(&saved); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		hash := sha256.New()
+		io.Copy(hash, tr)
+		hashes[header.Name] = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	if len(saved.Files) != 1 || hashes["uploads/a.png"] != saved.Files[0].SHA256 {
+		t.Fatalf("清单与归档内容不一致, manifest = %+v, hashes = %v", saved.Files, hashes)
+	}
+}
+
+func TestSelected(t *testing.T) {
+	cases := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    bool
+	}{
+		{"uploads/a.png", nil, nil, true},
+		{"uploads/a.png", []string{"*.jpg"}, nil, false},
+		{"logs/2024/app.log", []string{"logs"}, nil, true},
+		{"database/job_runs.ndjson", nil, []string{"database/job_runs.*"}, false},
+		{"uploads/tmp/a.png", []string{"*.png"}, []string{"tmp"}, false},
+	}
+	for _, c := range cases {
+		if got := Selected(c.name, c.include, c.exclude); got != c.want {
+			t.Errorf("Selected(%q, %v, %v) = %v, want %v", c.name, c.include, c.exclude, got, c.want)
+		}
+	}
+}
+
+func TestRestoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	uploads := filepath.Join(dir, "uploads")
+	os.MkdirAll(filepath.Join(uploads, "2024"), 0755)
+	os.WriteFile(filepath.Join(uploads, "2024", "a.png"), []byte("image"), 0644)
+
+	output := filepath.Join(dir, Name(time.Now())+Ext(Zstd))
+	if _, err := Build(context.Background(), Options{
+		Path:        output,
+		Compression: Zstd,
+		Sources:     []Source{{Name: "uploads", Dir: uploads}},
+	}); err != nil {
+		t.Fatalf("Build err = %v", err)
+	}
+
+	target := filepath.Join(dir, "restored")
+	result, err := Restore(context.Background(), output, RestoreOptions{Dirs: map[string]string{"uploads": target}})
+	if err != nil {
+		t.Fatalf("Restore err = %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(target, "2024", "a.png"))
+	if result.Files != 1 || string(data) != "image" {
+		t.Fatalf("files = %d, data = %q", result.Files, data)
+	}
+
+	// 归档被截断时校验失败
+	raw, _ := os.ReadFile(output)
+	os.WriteFile(output, raw[:len(raw)/2], 0644)
+	if _, err := VerifyFile(output, ""); err == nil {
+		t.Fatal("截断的归档应校验失败")
+	}
+}
+
+func TestRestoreDatabase(t *testing.T) {
+	db := testDB(t)
+	drop := func() {
+		db.Exec("DROP TABLE IF EXISTS restore_test_child, restore_test_parent, restore_test_skip")
+	}
+	drop()
+	t.Cleanup(drop)
+
+	// 子表按名称排在父表之前，恢复时需要按外键顺序写入
+	for _, sql := range []string{
+		"CREATE TABLE restore_test_parent (id bigserial PRIMARY KEY, name text)",
+		"CREATE TABLE restore_test_child (id bigserial PRIMARY KEY, parent_id bigint NOT NULL REFERENCES restore_test_parent(id))",
+		"CREATE TABLE restore_test_skip (id bigserial PRIMARY KEY, name text)",
+		"INSERT INTO restore_test_parent (id, name) VALUES (1, 'a'), (2, 'b')",
+		"INSERT INTO restore_test_child (id, parent_id) VALUES (1, 2)",
+		"INSERT INTO restore_test_skip (id, name) VALUES (1, 'old')",
+	} {
+		if err := db.Exec(sql).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output := filepath.Join(t.TempDir(), Name(time.Now())+Ext(Gzip))
+	if _, err := Build(context.Background(), Options{Path: output, DB: db, Include: []string{"database/restore_test_*"}}); err != nil {
+		t.Fatalf("Build err = %v", err)
+	}
+
+	db.Exec("DELETE FROM restore_test_child")
+	db.Exec("UPDATE restore_test_parent SET name = 'changed'")
+	db.Exec("UPDATE restore_test_skip SET name = 'new'")
+
+	opts := RestoreOptions{DB: db, Skip: []string{"restore_test_skip"}}
+	if _, err := Restore(context.Background(), output, opts); err == nil {
+		t.Fatal("已有数据时不使用强制恢复应失败")
+	}
+
+	opts.Force = true
+	result, err := Restore(context.Background(), output, opts)
+	if err != nil {
+		t.Fatalf("Restore err = %v", err)
+	}
+	if result.Tables["restore_test_parent"] != 2 || result.Tables["restore_test_child"] != 1 {
+		t.Fatalf("tables = %v", result.Tables)
+	}
+	if _, ok := result.Tables["restore_test_skip"]; ok {
+		t.Fatal("跳过的表不应恢复")
+	}
+
+	var name string
+	db.Raw("SELECT name FROM restore_test_parent WHERE id = 2").Scan(&name)
+	if name != "b" {
+		t.Errorf("parent name = %q, 期望 b", name)
+	}
+	db.Raw("SELECT name FROM restore_test_skip WHERE id = 1").Scan(&name)
+	if name != "new" {
+		t.Errorf("跳过的表被修改, name = %q", name)
+	}
+
+	// 序列重置后新插入的数据不会主键冲突
+	var id int64
+	if err := db.Raw("INSERT INTO restore_test_parent (name) VALUES ('c') RETURNING id").Scan(&id).Error; err != nil || id != 3 {
+		t.Fatalf("新插入的 id = %d, err = %v", id, err)
+	}
+}
@@ -0,0 +1,246 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// EncryptedExt 加密备份文件的扩展名，追加在压缩格式的扩展名之后
+const EncryptedExt = ".enc"
+
+// encryptMagic 加密文件的文件头标识
+var encryptMagic = []byte("BLOGENC1")
+
+const (
+	chunkSize  = 64 * 1024 // 每个加密块的明文长度
+	saltSize   = 16
+	prefixSize = 8 // nonce 前缀长度，nonce 由前缀和 4 字节块序号组成
+	headerSize = 8 + 3 + saltSize + prefixSize
+
+	// scrypt 参数，N = 2^scryptLogN
+	scryptLogN = 15
+	scryptR    = 8
+	scryptP    = 1
+)
+
+var (
+	// ErrEncrypted 备份文件已加密但没有提供口令
+	ErrEncrypted = errors.New("备份文件已加密，需要提供口令")
+	// ErrDecrypt 口令错误，或加密文件被截断、篡改
+	ErrDecrypt = errors.New("备份解密失败，口令错误或文件已损坏")
+)
+
+// 加密文件格式：
+//
+//	magic(8) logN(1) r(1) p(1) salt(16) noncePrefix(8)
+//	chunk...
+//
+// 每个块使用 AES-256-GCM 单独加密，附加数据为文件头和是否为最后一块的标记，
+// 因此调换、删除或截断块都会导致解密失败；除最后一块外每块明文长度都是 chunkSize，
+// 最后一块可能为空。
+
+// encryptWriter 分块加密写入
+type encryptWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	header []byte
+	buf    []byte
+	out    []byte
+	seq    uint32
+	closed bool
+}
+
+// NewEncryptWriter 使用口令派生的密钥加密写入 w，Close 时写入最后一块，不会关闭 w
+func NewEncryptWriter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	header := make([]byte, headerSize)
+	copy(header, encryptMagic)
+	header[8], header[9], header[10] = scryptLogN, scryptR, scryptP
+	if _, err := rand.Read(header[11:]); err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(passphrase, header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{
+		w:      w,
+		aead:   aead,
+		header: header,
+		buf:    make([]byte, 0, chunkSize),
+	}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, errors.New("加密写入已关闭")
+	}
+	var written int
+	for len(p) > 0 {
+		n := copy(e.buf[len(e.buf):chunkSize], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+		if len(e.buf) == chunkSize {
+			if err := e.flush(false); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (e *encryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.flush(true)
+}
+
+func (e *encryptWriter) flush(final bool) error {
+	e.out = e.aead.Seal(e.out[:0], chunkNonce(e.header, e.seq), e.buf, chunkAAD(e.header, final))
+	e.seq++
+	e.buf = e.buf[:0]
+	_, err := e.w.Write(e.out)
+	return err
+}
+
+// decryptReader 分块解密读取
+type decryptReader struct {
+	r      io.Reader
+	aead   cipher.AEAD
+	header []byte
+	in     []byte
+	plain  []byte
+	seq    uint32
+	done   bool
+}
+
+// NewDecryptReader 读取文件头并使用口令派生的密钥解密 r，块校验失败时返回 ErrDecrypt
+func NewDecryptReader(r io.Reader, passphrase string) (io.Reader, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, ErrDecrypt
+	}
+	if !bytes.Equal(header[:8], encryptMagic) {
+		return nil, errors.New("不是加密的备份文件")
+	}
+	aead, err := newAEAD(passphrase, header)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		r:      r,
+		aead:   aead,
+		header: header,
+		in:     make([]byte, chunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+// next 读取并解密下一块，读满一块时为中间块，不足一块时为最后一块
+func (d *decryptReader) next() error {
+	n, err := io.ReadFull(d.r, d.in)
+	final := false
+	switch {
+	case err == nil:
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		final = true
+	case errors.Is(err, io.EOF):
+		// 缺少最后一块，文件被截断
+		return ErrDecrypt
+	default:
+		return err
+	}
+
+	plain, err := d.aead.Open(d.in[:0], chunkNonce(d.header, d.seq), d.in[:n], chunkAAD(d.header, final))
+	if err != nil {
+		return ErrDecrypt
+	}
+	d.seq++
+	d.plain = plain
+	d.done = final
+	return nil
+}
+
+// newAEAD 按文件头中的参数和盐通过 scrypt 派生密钥
+func newAEAD(passphrase string, header []byte) (cipher.AEAD, error) {
+	if passphrase == "" {
+		return nil, ErrEncrypted
+	}
+	logN, r, p := header[8], header[9], header[10]
+	if logN < 10 || logN > 22 || r == 0 || p == 0 {
+		return nil, errors.New("加密参数不合法")
+	}
+	key, err := scrypt.Key([]byte(passphrase), header[11:11+saltSize], 1<<logN, int(r), int(p), 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(header []byte, seq uint32) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, header[headerSize-prefixSize:])
+	binary.BigEndian.PutUint32(nonce[prefixSize:], seq)
+	return nonce
+}
+
+func chunkAAD(header []byte, final bool) []byte {
+	aad := append(make([]byte, 0, len(header)+1), header...)
+	if final {
+		return append(aad, 1)
+	}
+	return append(aad, 0)
+}
+
+// Open 识别备份文件是否加密，加密时使用口令解密，返回压缩归档的读取流
+func Open(r io.Reader, passphrase string) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(encryptMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if !bytes.Equal(magic, encryptMagic) {
+		return br, nil
+	}
+	if passphrase == "" {
+		return nil, ErrEncrypted
+	}
+	return NewDecryptReader(br, passphrase)
+}
+
+// Encrypted 判断备份文件名是否为加密备份
+func Encrypted(name string) bool {
+	return strings.HasSuffix(name, EncryptedExt)
+}
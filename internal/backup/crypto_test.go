@@ -0,0 +1,84 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncryptRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 10, chunkSize, chunkSize*2 + 7} {
+		plain := bytes.Repeat([]byte{'x'}, size)
+
+		var buf bytes.Buffer
+		w, err := NewEncryptWriter(&buf, "secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(plain)
+		w.Close()
+		data := buf.Bytes()
+
+		r, err := Open(bytes.NewReader(data), "secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil || !bytes.Equal(got, plain) {
+			t.Fatalf("size %d: err = %v, len = %d", size, err, len(got))
+		}
+
+		if _, err := Open(bytes.NewReader(data), ""); !errors.Is(err, ErrEncrypted) {
+			t.Fatalf("size %d: missing passphrase err = %v", size, err)
+		}
+
+		r, _ = Open(bytes.NewReader(data), "wrong")
+		if _, err := io.ReadAll(r); !errors.Is(err, ErrDecrypt) {
+			t.Fatalf("size %d: wrong passphrase err = %v", size, err)
+		}
+
+		// 截断到块边界或块中间都应校验失败
+		for _, cut := range []int{headerSize, len(data) - 1, len(data) - 16 - 1} {
+			r, _ = Open(bytes.NewReader(data[:max(cut, headerSize)]), "secret")
+			if _, err := io.ReadAll(r); !errors.Is(err, ErrDecrypt) {
+				t.Fatalf("size %d cut %d: err = %v", size, cut, err)
+			}
+		}
+
+		tampered := append([]byte(nil), data...)
+		tampered[len(tampered)-1] ^= 1
+		r, _ = Open(bytes.NewReader(tampered), "secret")
+		if _, err := io.ReadAll(r); !errors.Is(err, ErrDecrypt) {
+			t.Fatalf("size %d: tampered err = %v", size, err)
+		}
+	}
+}
+
+func TestEncryptedBackup(t *testing.T) {
+	dir := t.TempDir()
+	uploads := filepath.Join(dir, "uploads")
+	os.MkdirAll(uploads, 0755)
+	os.WriteFile(filepath.Join(uploads, "a.png"), []byte("image"), 0644)
+
+	output := filepath.Join(dir, "backup.tar.zst.enc")
+	if _, err := Build(context.Background(), Options{
+		Path:        output,
+		Compression: Zstd,
+		Sources:     []Source{{Name: "uploads", Dir: uploads}},
+		Passphrase:  "secret",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := VerifyFile(output, ""); !errors.Is(err, ErrEncrypted) {
+		t.Fatalf("verify without passphrase err = %v", err)
+	}
+	manifest, err := VerifyFile(output, "secret")
+	if err != nil || len(manifest.Files) != 1 {
+		t.Fatalf("verify err = %v, manifest = %+v", err, manifest)
+	}
+}
@@ -0,0 +1,426 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"gorm.io/gorm"
+)
+
+// restoreBatchSize 恢复数据时每批插入的行数
+const restoreBatchSize = 500
+
+// ErrManifest 归档内容与清单不一致
+var ErrManifest = errors.New("备份清单校验失败")
+
+// RestoreOptions 恢复选项
+type RestoreOptions struct {
+	DB     *gorm.DB          // 恢复数据库的目标，为 nil 时不恢复数据库
+	Dirs   map[string]string // 归档中的目录与本地目录的对应关系，不在其中的文件不恢复
+	Force  bool              // 目标数据库已有数据时仍然恢复
+	Seeded []string          // 初始化时已写入默认数据的表，检查目标数据库是否已有数据时跳过
+	Skip   []string          // 不恢复的表，如迁移记录，目标数据库保留自己的内容
+
+	Passphrase string // 加密备份的口令
+}
+
+// RestoreResult 恢复结果
+type RestoreResult struct {
+	Files  int              `json:"files"`
+	Tables map[string]int64 `json:"tables"`
+}
+
+// NewDecompressor 根据文件头识别 gzip 或 zstd 并解压
+func NewDecompressor(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return gzip.NewReader(br)
+	case bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return nil, errors.New("无法识别的备份文件格式")
+	}
+}
+
+// Walk 按顺序读取归档中的每个条目，加密的归档使用口令解密，fn 返回错误时停止
+func Walk(r io.Reader, passphrase string, fn func(header *tar.Header, r io.Reader) error) error {
+	r, err := Open(r, passphrase)
+	if err != nil {
+		return err
+	}
+	zr, err := NewDecompressor(r)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	tr := tar.NewReader(zr)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(header, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// Verify 读取整个归档，校验每个条目的大小和 SHA-256 与清单一致，并返回清单
+func Verify(r io.Reader, passphrase string) (*Manifest, error) {
+	var (
+		manifest *Manifest
+		hashes   = make(map[string]string)
+	)
+	err := Walk(r, passphrase, func(header *tar.Header, r io.Reader) error {
+		if header.Name == ManifestName {
+			manifest = &Manifest{}
+			return json.NewDecoder(r).Decode(manifest)
+		}
+		hash := sha256.New()
+		if _, err := io.Copy(hash, r); err != nil {
+			return err
+		}
+		hashes[header.Name] = hex.EncodeToString(hash.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("%w: 缺少 %s", ErrManifest, ManifestName)
+	}
+	if manifest.Version > ManifestVersion {
+		return nil, fmt.Errorf("%w: 不支持的清单版本 %d", ErrManifest, manifest.Version)
+	}
+	if len(hashes) != len(manifest.Files) {
+		return nil, fmt.Errorf("%w: 清单记录 %d 个文件，归档中有 %d 个", ErrManifest, len(manifest.Files), len(hashes))
+	}
+	for _, f := range manifest.Files {
+		if hashes[f.Path] != f.SHA256 {
+			return nil, fmt.Errorf("%w: %s 的 SHA-256 不一致", ErrManifest, f.Path)
+		}
+	}
+	return manifest, nil
+}
+
+// VerifyFile 校验备份文件
+func VerifyFile(name, passphrase string) (*Manifest, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Verify(f, passphrase)
+}
+
+// Restore 校验备份文件后恢复文件和数据库；
+// 数据库在一个事务内清空归档中包含的表，再按外键依赖的顺序写入
+func Restore(ctx context.Context, name string, opts RestoreOptions) (*RestoreResult, error) {
+	manifest, err := VerifyFile(name, opts.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := make(map[string]string, len(manifest.Files))
+	for _, f := range manifest.Files {
+		expected[f.Path] = f.SHA256
+	}
+
+	result := &RestoreResult{Tables: make(map[string]int64)}
+
+	if opts.DB != nil && len(manifest.Tables) > 0 {
+		if err := restoreDatabase(ctx, name, opts, manifest, expected, result); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(opts.Dirs) > 0 {
+		if err := restoreFiles(name, opts, expected, result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// restoreFiles 将文件写入对应的本地目录，先写入临时文件，校验通过后再替换
+func restoreFiles(name string, opts RestoreOptions, expected map[string]string, result *RestoreResult) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return Walk(f, opts.Passphrase, func(header *tar.Header, r io.Reader) error {
+		target, ok := localPath(header.Name, opts.Dirs)
+		if !ok || header.Typeflag != tar.TypeReg {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return err
+		}
+
+		tmp := target + ".restore"
+		out, err := os.Create(tmp)
+		if err != nil {
+			return err
+		}
+		hash := sha256.New()
+		_, err = io.Copy(io.MultiWriter(out, hash), r)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil && hex.EncodeToString(hash.Sum(nil)) != expected[header.Name] {
+			err = fmt.Errorf("%w: %s 的 SHA-256 不一致", ErrManifest, header.Name)
+		}
+		if err != nil {
+			os.Remove(tmp)
+			return err
+		}
+		if err := os.Rename(tmp, target); err != nil {
+			return err
+		}
+		os.Chtimes(target, header.ModTime, header.ModTime)
+		result.Files++
+		return nil
+	})
+}
+
+// localPath 将归档路径映射为本地路径，拒绝跳出目标目录的路径
+func localPath(name string, dirs map[string]string) (string, bool) {
+	clean := path.Clean(name)
+	if clean != name || path.IsAbs(clean) || strings.HasPrefix(clean, "../") {
+		return "", false
+	}
+	prefix, rest, ok := strings.Cut(clean, "/")
+	if !ok {
+		return "", false
+	}
+	dir, ok := dirs[prefix]
+	if !ok || dir == "" {
+		return "", false
+	}
+	return filepath.Join(dir, filepath.FromSlash(rest)), true
+}
+
+// restoreDatabase 在一个事务内按外键依赖的顺序逐表读取归档并写入，每张表边读边写，不会一次读入所有数据
+func restoreDatabase(ctx context.Context, name string, opts RestoreOptions, manifest *Manifest, expected map[string]string, result *RestoreResult) error {
+	tables := make([]string, 0, len(manifest.Tables))
+	for table := range manifest.Tables {
+		if slices.Contains(opts.Skip, table) {
+			continue
+		}
+		if _, ok := expected[tableEntry(table)]; !ok {
+			return fmt.Errorf("%w: 缺少表 %s 的导出", ErrManifest, table)
+		}
+		tables = append(tables, table)
+	}
+	if len(tables) == 0 {
+		return nil
+	}
+
+	return opts.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, table := range tables {
+			if !tx.Migrator().HasTable(table) {
+				return fmt.Errorf("目标数据库缺少表 %s，请先完成数据库迁移", table)
+			}
+		}
+
+		if !opts.Force {
+			for _, table := range tables {
+				if slices.Contains(opts.Seeded, table) {
+					continue
+				}
+				var count int64
+				if err := tx.Table(table).Count(&count).Error; err != nil {
+					return err
+				}
+				if count > 0 {
+					return fmt.Errorf("表 %s 已有数据，确认覆盖时请使用强制恢复", table)
+				}
+			}
+		}
+
+		order, err := tableOrder(tx, tables)
+		if err != nil {
+			return err
+		}
+
+		// 只清空归档中的表，被归档之外的表引用时清空失败，不会连带清空其他表
+		names := make([]string, len(order))
+		for i, table := range order {
+			names[len(order)-1-i] = tx.Statement.Quote(table)
+		}
+		if err := tx.Exec("TRUNCATE TABLE " + strings.Join(names, ", ")).Error; err != nil {
+			return fmt.Errorf("清空表失败，请确认引用这些表的表也包含在备份中: %w", err)
+		}
+
+		for _, table := range order {
+			count, err := restoreTable(tx, name, opts.Passphrase, table, expected[tableEntry(table)])
+			if err != nil {
+				return fmt.Errorf("恢复表 %s 失败: %w", table, err)
+			}
+			result.Tables[table] = count
+		}
+
+		for _, table := range order {
+			if err := ResetSequence(tx, table); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// tableEntry 表的导出在归档中的路径
+func tableEntry(table string) string {
+	return path.Join(DatabaseDir, table+".ndjson")
+}
+
+// tableOrder 按外键依赖排序，被引用的表在前，表引用自身时不影响顺序
+func tableOrder(tx *gorm.DB, tables []string) ([]string, error) {
+	var refs []struct {
+		Child  string
+		Parent string
+	}
+	err := tx.Raw(`SELECT c.relname AS child, p.relname AS parent FROM pg_constraint k
+		JOIN pg_class c ON c.oid = k.conrelid
+		JOIN pg_class p ON p.oid = k.confrelid
+		WHERE k.contype = 'f' AND c.relnamespace = current_schema()::regnamespace`).Scan(&refs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	parents := make(map[string][]string, len(tables))
+	for _, ref := range refs {
+		if ref.Child != ref.Parent && slices.Contains(tables, ref.Child) && slices.Contains(tables, ref.Parent) {
+			parents[ref.Child] = append(parents[ref.Child], ref.Parent)
+		}
+	}
+
+	pending := slices.Clone(tables)
+	slices.Sort(pending)
+	order := make([]string, 0, len(tables))
+	for len(pending) > 0 {
+		var rest []string
+		for _, table := range pending {
+			ready := true
+			for _, parent := range parents[table] {
+				if !slices.Contains(order, parent) {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				order = append(order, table)
+			} else {
+				rest = append(rest, table)
+			}
+		}
+		if len(rest) == len(pending) {
+			return nil, fmt.Errorf("表之间存在循环外键: %s", strings.Join(rest, ", "))
+		}
+		pending = rest
+	}
+	return order, nil
+}
+
+// errFound 找到需要的条目后停止遍历归档
+var errFound = errors.New("found")
+
+// restoreTable 从归档中读取一张表的导出，分批写入并校验 SHA-256
+func restoreTable(tx *gorm.DB, name, passphrase, table, sum string) (int64, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var count int64
+	entry := tableEntry(table)
+	err = Walk(f, passphrase, func(header *tar.Header, r io.Reader) error {
+		if header.Name != entry {
+			return nil
+		}
+
+		hash := sha256.New()
+		dec := json.NewDecoder(io.TeeReader(r, hash))
+		dec.UseNumber() // 数字保留原始文本以免大整数丢失精度
+
+		batch := make([]map[string]any, 0, restoreBatchSize)
+		for {
+			var row map[string]any
+			err := dec.Decode(&row)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return err
+			}
+			if row != nil {
+				batch = append(batch, row)
+			}
+			if len(batch) > 0 && (len(batch) == restoreBatchSize || err != nil) {
+				if err := tx.Table(table).Create(batch).Error; err != nil {
+					return err
+				}
+				count += int64(len(batch))
+				batch = make([]map[string]any, 0, restoreBatchSize)
+			}
+			if err != nil {
+				break
+			}
+		}
+		if hex.EncodeToString(hash.Sum(nil)) != sum {
+			return fmt.Errorf("%w: %s 的 SHA-256 不一致", ErrManifest, entry)
+		}
+		return errFound
+	})
+	if errors.Is(err, errFound) {
+		return count, nil
+	}
+	if err == nil {
+		err = fmt.Errorf("%w: 缺少 %s", ErrManifest, entry)
+	}
+	return count, err
+}
+
+// ResetSequence 将自增主键的序列设置为当前最大值，避免恢复后新插入的数据主键冲突
+func ResetSequence(tx *gorm.DB, table string) error {
+	if !tx.Migrator().HasColumn(table, "id") {
+		return nil
+	}
+	var sequence *string
+	if err := tx.Raw("SELECT pg_get_serial_sequence(?, 'id')", table).Scan(&sequence).Error; err != nil {
+		return err
+	}
+	if sequence == nil {
+		return nil
+	}
+	return tx.Exec(fmt.Sprintf("SELECT setval(?, COALESCE((SELECT MAX(id) FROM %s), 0) + 1, false)", tx.Statement.Quote(table)), *sequence).Error
+}
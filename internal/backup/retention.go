@@ -0,0 +1,88 @@
+package backup
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// NamePrefix 备份文件名前缀，文件名中包含创建时间
+const NamePrefix = "backup-"
+
+// nameLayout 备份文件名中的时间格式
+const nameLayout = "20060102-150405"
+
+// Name 按创建时间生成不带扩展名的备份名称
+func Name(t time.Time) string {
+	return NamePrefix + t.Format(nameLayout)
+}
+
+// ParseName 从备份文件名中解析创建时间，不是备份文件时返回 false
+func ParseName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, NamePrefix) {
+		return time.Time{}, false
+	}
+	name = strings.TrimSuffix(name, EncryptedExt)
+	var ext string
+	switch {
+	case strings.HasSuffix(name, Ext(Gzip)):
+		ext = Ext(Gzip)
+	case strings.HasSuffix(name, Ext(Zstd)):
+		ext = Ext(Zstd)
+	default:
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(nameLayout, strings.TrimSuffix(strings.TrimPrefix(name, NamePrefix), ext), time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// Expired 按保留策略返回需要删除的备份文件：
+// 保留最近 daily 个有备份的日期中每天最新的一份，以及最近 weekly 个有备份的周中每周最新的一份；
+// daily 和 weekly 都不大于 0 时不删除任何备份
+func Expired(names []string, daily, weekly int) []string {
+	if daily <= 0 && weekly <= 0 {
+		return nil
+	}
+
+	type item struct {
+		name string
+		time time.Time
+	}
+	var items []item
+	for _, name := range names {
+		if t, ok := ParseName(name); ok {
+			items = append(items, item{name: name, time: t})
+		}
+	}
+	slices.SortFunc(items, func(a, b item) int { return b.time.Compare(a.time) })
+
+	keep := make(map[string]bool)
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+	for _, it := range items {
+		day := it.time.Format(time.DateOnly)
+		if !days[day] && len(days) < daily {
+			days[day] = true
+			keep[it.name] = true
+		}
+
+		year, week := it.time.ISOWeek()
+		key := fmt.Sprintf("%d-%02d", year, week)
+		if !weeks[key] && len(weeks) < weekly {
+			weeks[key] = true
+			keep[it.name] = true
+		}
+	}
+
+	var expired []string
+	for _, it := range items {
+		if !keep[it.name] {
+			expired = append(expired, it.name)
+		}
+	}
+	return expired
+}
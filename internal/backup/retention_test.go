@@ -0,0 +1,46 @@
+package backup
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestExpired(t *testing.T) {
+	// 2024-06-03 是周一
+	base := time.Date(2024, 6, 3, 3, 0, 0, 0, time.Local)
+	var names []string
+	for i := 0; i < 21; i++ {
+		names = append(names, Name(base.AddDate(0, 0, i))+Ext(Gzip))
+	}
+	// 同一天的第二份备份
+	names = append(names, Name(base.AddDate(0, 0, 20).Add(time.Hour))+Ext(Zstd))
+
+	expired := Expired(names, 3, 2)
+
+	keep := map[string]bool{}
+	for _, name := range names {
+		if !slices.Contains(expired, name) {
+			keep[name] = true
+		}
+	}
+
+	want := []string{
+		Name(base.AddDate(0, 0, 20).Add(time.Hour)) + Ext(Zstd), // 最近一天最新的一份，同时是本周最新
+		Name(base.AddDate(0, 0, 19)) + Ext(Gzip),
+		Name(base.AddDate(0, 0, 18)) + Ext(Gzip),
+		Name(base.AddDate(0, 0, 13)) + Ext(Gzip), // 上一周最新的一份
+	}
+	if len(keep) != len(want) {
+		t.Fatalf("保留了 %d 份, want %d: %v", len(keep), len(want), keep)
+	}
+	for _, name := range want {
+		if !keep[name] {
+			t.Errorf("%s 应保留", name)
+		}
+	}
+
+	if Expired(names, 0, 0) != nil {
+		t.Fatal("未配置保留策略时不应删除")
+	}
+}
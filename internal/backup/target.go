@@ -0,0 +1,121 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Target 备份文件的第二存储位置
+type Target interface {
+	// Put 上传本地备份文件，name 为备份文件名
+	Put(ctx context.Context, name, localPath string) error
+	// Delete 删除备份文件，文件不存在时不返回错误
+	Delete(ctx context.Context, name string) error
+}
+
+// DirTarget 复制到另一个目录，通常是挂载的网络存储或另一块磁盘
+type DirTarget struct {
+	Dir string
+}
+
+func (d DirTarget) Put(ctx context.Context, name, localPath string) error {
+	if err := os.MkdirAll(d.Dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	src, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	target := filepath.Join(d.Dir, filepath.Base(name))
+	out, err := os.Create(target + ".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, src)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(target + ".tmp")
+		return err
+	}
+	return os.Rename(target+".tmp", target)
+}
+
+func (d DirTarget) Delete(ctx context.Context, name string) error {
+	err := os.Remove(filepath.Join(d.Dir, filepath.Base(name)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// WebDAVTarget 通过 HTTP PUT、DELETE 上传到 WebDAV 服务
+type WebDAVTarget struct {
+	URL      string
+	Username string
+	Password string
+}
+
+func (w WebDAVTarget) Put(ctx context.Context, name, localPath string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	request, err := w.request(ctx, http.MethodPut, name, f)
+	if err != nil {
+		return err
+	}
+	request.ContentLength = info.Size()
+	return w.do(request, false)
+}
+
+func (w WebDAVTarget) Delete(ctx context.Context, name string) error {
+	request, err := w.request(ctx, http.MethodDelete, name, nil)
+	if err != nil {
+		return err
+	}
+	return w.do(request, true)
+}
+
+func (w WebDAVTarget) request(ctx context.Context, method, name string, body io.Reader) (*http.Request, error) {
+	url := strings.TrimSuffix(w.URL, "/") + "/" + filepath.Base(name)
+	request, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if w.Username != "" {
+		request.SetBasicAuth(w.Username, w.Password)
+	}
+	return request, nil
+}
+
+// do 发送请求，allowNotFound 为 true 时 404 视为成功
+func (w WebDAVTarget) do(request *http.Request, allowNotFound bool) error {
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 == 2 || (allowNotFound && response.StatusCode == http.StatusNotFound) {
+		return nil
+	}
+	return fmt.Errorf("%s %s 失败，状态码 %d", request.Method, request.URL.Path, response.StatusCode)
+}
@@ -27,3 +27,46 @@ type LogQueryParams struct {
 	End       *int64  `json:"-" form:"-"`
 	Sort      Sort    `json:"sort" form:"sort"`
 }
+
+// ExportRequest 导出站点数据
+type ExportRequest struct {
+	Tables []string `query:"tables"` //需要导出的表，为空时导出全部
+	Token  string   `query:"token"`  //重新验证密码后获得的一次性下载令牌
+}
+
+// ImportRequest 导入站点数据
+type ImportRequest struct {
+	Strategy string `query:"strategy" validate:"omitempty,oneof=skip overwrite merge" error:"冲突处理策略只能是skip、overwrite或merge"` //已存在数据的处理策略，默认skip
+	DryRun   bool   `query:"dryRun"`                                                                                         //只生成报告，不写入数据
+}
+
+// PostImportRequest 从其他博客系统导入文章
+type PostImportRequest struct {
+	Category   string `query:"category"`   // 文章没有分类时使用的分类
+	Drafts     bool   `query:"drafts"`     // 是否导入草稿，草稿导入为私有博客
+	KeepRemote bool   `query:"keepRemote"` // 是否保留远程图片地址，不下载到本站
+}
+
+// PostExportRequest 导出博客为 Markdown，分类和专题同时指定时都生效
+type PostExportRequest struct {
+	User     *int `query:"user"`     // 指定用户，只有超级管理员可以导出其他用户的博客
+	Category *int `query:"category"` // 指定分类
+	Topic    *int `query:"topic"`    // 指定专题
+}
+
+// SQLQueryRequest SQL 控制台只读查询
+type SQLQueryRequest struct {
+	SQL     string `json:"sql" validate:"required" error:"SQL 不能为空"`
+	Page    int    `json:"page" validate:"omitempty,min=1" error:"页码必须大于0"`
+	Size    int    `json:"size" validate:"omitempty,min=1,max=1000" error:"每页行数必须在1到1000之间"`
+	Explain bool   `json:"explain"`                                                          // 返回执行计划
+	Analyze bool   `json:"analyze"`                                                          // 执行计划中包含实际执行的耗时
+	Timeout int    `json:"timeout" validate:"omitempty,min=1,max=60" error:"超时时间必须在1到60秒之间"` // 超时时间（秒）
+}
+
+// SQLExecRequest SQL 控制台写入模式，commit 为 false 时只预览
+type SQLExecRequest struct {
+	SQL     string `json:"sql" validate:"required" error:"SQL 不能为空"`
+	Commit  bool   `json:"commit"`                                                           // 是否提交
+	Timeout int    `json:"timeout" validate:"omitempty,min=1,max=60" error:"超时时间必须在1到60秒之间"` // 超时时间（秒）
+}
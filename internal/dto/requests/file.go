@@ -14,11 +14,6 @@ type FileUpdateRequest struct {
 	IsPublic bool    `json:"is_pub"`
 }
 
-type TarRequest struct {
-	Path string `json:"path" validate:"required" error:"压缩路径为空"`
-	Min  int    `json:"min" validate:"required" error:"请填入定时删除分钟数"`
-}
-
 func (sort Sort) GetFilegOrderString(prefix string) string {
 	switch sort {
 	case CREATE:
@@ -41,3 +36,15 @@ type SystemFileRequest struct {
 	Path    string `json:"path"`    //文件路径
 	Keyword string `json:"keyword"` //文件关键字
 }
+
+// BackupRequest 创建备份，未传入的选项使用配置文件中的值
+type BackupRequest struct {
+	Compression string   `json:"compression" validate:"omitempty,oneof=gzip zstd" error:"压缩方式只能是gzip或zstd"` //压缩方式
+	Include     []string `json:"include"`                                                                   //只备份匹配的文件
+	Exclude     []string `json:"exclude"`                                                                   //排除匹配的文件
+}
+
+// BackupReauthRequest 下载备份前重新验证密码
+type BackupReauthRequest struct {
+	Password string `json:"password" validate:"required" error:"密码为必填项"` //当前账号的密码
+}
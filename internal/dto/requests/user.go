@@ -1,6 +1,7 @@
 package requests
 
 import (
+	"blog/internal/dto/response"
 	"blog/internal/models"
 	"blog/internal/utils"
 	"time"
@@ -13,7 +14,40 @@ type UserRequest struct {
 	Password string `json:"password" validate:"required,min=8,max=16" error:"密码要在8-16个字符之间"`      //用户密码
 	Email    string `json:"email" validate:"required,email" error:"这不是正确的邮箱格式"`                   //用户邮箱
 	NickName string `json:"nickName" validate:"required,max=50,min=1" error:"用户名称最低1个字符，不能超过50个"` //用户名称
-	Code     string `json:"code" validate:"required,min=6,max=6" error:"验证码为6位数字"`                //邮箱验证码
+	Code     string `json:"code" validate:"omitempty,len=6" error:"验证码为6位数字"`                     //邮箱验证码，开启邮箱验证时必填
+}
+
+// CreateUserRequest 直接创建账号，不需要邮箱验证码，用于命令行创建管理员
+type CreateUserRequest struct {
+	Username string `json:"username" validate:"required,min=8,max=16" error:"账号要在8-16个字符之间"`      //用户账号
+	Password string `json:"password" validate:"required,min=8,max=16" error:"密码要在8-16个字符之间"`      //用户密码
+	Email    string `json:"email" validate:"required,email" error:"这不是正确的邮箱格式"`                   //用户邮箱
+	NickName string `json:"nickName" validate:"required,max=50,min=1" error:"用户名称最低1个字符，不能超过50个"` //用户名称
+	RoleID   uint   `json:"roleId" validate:"required,oneof=1 2 3" error:"角色只能是1、2、3"`            //角色ID
+}
+
+// ToUserModel 转成model，邮箱视为已验证
+func (r CreateUserRequest) ToUserModel() models.User {
+	return models.User{
+		Username: r.Username,
+		Password: utils.EncryptPassword(r.Password),
+		Email:    r.Email,
+		RegTime:  time.Now().Unix(),
+		NickName: r.NickName,
+		RoleID:   r.RoleID,
+		Verified: true,
+		Status:   true,
+	}
+}
+
+// SetupRequest 首次安装请求，创建站长账号和网站配置
+type SetupRequest struct {
+	Token    string                  `json:"token" validate:"required" error:"安装令牌不能为空"`                           //安装令牌，启动日志中打印或通过环境变量指定
+	Username string                  `json:"username" validate:"required,min=8,max=16" error:"账号要在8-16个字符之间"`      //站长账号
+	Password string                  `json:"password" validate:"required,min=8,max=16" error:"密码要在8-16个字符之间"`      //站长密码
+	Email    string                  `json:"email" validate:"required,email" error:"这不是正确的邮箱格式"`                   //站长邮箱
+	NickName string                  `json:"nickName" validate:"required,max=50,min=1" error:"用户名称最低1个字符，不能超过50个"` //站长名称
+	Site     response.BlogConfigInfo `json:"site"`                                                                 //网站配置，名称为空时使用站长名称
 }
 
 // LoginRequest 账号登录请求体
@@ -58,6 +92,20 @@ type UserAdminFilter struct {
 	End     string `form:"date[1]"` //结束时间
 }
 
+// AccessTokenRequest 创建个人访问令牌请求
+// @Description 创建个人访问令牌请求
+type AccessTokenRequest struct {
+	Name       string   `json:"name" validate:"required,min=1,max=50" error:"令牌名称要在1-50个字符之间"`      //令牌名称
+	Scopes     []string `json:"scopes" validate:"required,min=1" error:"请至少选择一个权限范围"`               //权限范围
+	ExpireDays int      `json:"expireDays" validate:"min=0,max=3650" error:"有效天数要在0-3650之间，0为永不过期"` //有效天数
+}
+
+// VerifyEmailRequest 验证邮箱请求
+// @Description 验证邮箱请求
+type VerifyEmailRequest struct {
+	Code string `json:"code" validate:"required,len=6" error:"验证码为6位数字"` //邮箱验证码
+}
+
 // 修改密码
 type ResetPassword struct {
 	Password    string `json:"password"`
@@ -0,0 +1,22 @@
+package response
+
+import "blog/internal/backup"
+
+// BackupProgressResponse 备份进度
+type BackupProgressResponse struct {
+	ID      string `json:"id"`              // 备份ID
+	Name    string `json:"name"`            // 备份文件名
+	Status  string `json:"status"`          // 状态 running、success、failed
+	Error   string `json:"error,omitempty"` // 失败原因
+	Size    int64  `json:"size"`            // 备份文件大小
+	StartAt int64  `json:"startAt"`         // 开始时间
+	EndAt   int64  `json:"endAt"`           // 结束时间
+	backup.Progress
+}
+
+// BackupFileResponse 备份文件
+type BackupFileResponse struct {
+	Name      string `json:"name"`      // 文件名
+	Size      int64  `json:"size"`      // 文件大小
+	CreatedAt int64  `json:"createdAt"` // 创建时间
+}
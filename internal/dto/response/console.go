@@ -40,3 +40,25 @@ type SystemInfo struct {
 	GoVersion  string `json:"goVersion"`  // Go版本
 	GoRoutines int    `json:"goRoutines"` // 协程数量
 }
+
+// DailyViewResponse 每日浏览统计
+type DailyViewResponse struct {
+	Date string `json:"date"` // 日期
+	PV   int64  `json:"pv"`   // 浏览量
+	UV   int64  `json:"uv"`   // 独立访客数
+}
+
+// ViewTrendResponse 浏览趋势
+type ViewTrendResponse struct {
+	BlogID int64               `json:"blogId"` // 博客ID，0为全站
+	Start  string              `json:"start"`  // 开始日期
+	End    string              `json:"end"`    // 结束日期
+	PV     int64               `json:"pv"`     // 区间总浏览量
+	Days   []DailyViewResponse `json:"days"`   // 每日统计，没有数据的日期为0
+}
+
+// ViewSourceResponse 浏览来源统计
+type ViewSourceResponse struct {
+	Value string `json:"value"` // 维度取值，如来源域名、省份
+	Count int64  `json:"count"` // 浏览次数
+}
@@ -0,0 +1,25 @@
+package response
+
+// 依赖状态
+const (
+	HealthUp       = "up"       //正常
+	HealthDown     = "down"     //不可用
+	HealthDegraded = "degraded" //非关键依赖不可用，仍可提供服务
+	HealthDisabled = "disabled" //未启用
+)
+
+// DependencyHealth 单个依赖的检查结果
+type DependencyHealth struct {
+	Status   string  `json:"status"`          // 状态
+	Critical bool    `json:"critical"`        // 是否为关键依赖，关键依赖不可用时服务未就绪
+	Latency  float64 `json:"latency"`         // 探测耗时，单位毫秒
+	Error    string  `json:"error,omitempty"` // 失败原因
+}
+
+// HealthResponse 存活与就绪检查结果
+type HealthResponse struct {
+	Status       string                      `json:"status"`                 // 总体状态
+	Ready        bool                        `json:"ready"`                  // 是否就绪
+	Uptime       int64                       `json:"uptime"`                 // 运行时长，单位秒
+	Dependencies map[string]DependencyHealth `json:"dependencies,omitempty"` // 各依赖的检查结果
+}
@@ -0,0 +1,28 @@
+package response
+
+// 单篇文章的导入状态
+const (
+	PostImportCreated = "created"
+	PostImportSkipped = "skipped"
+	PostImportFailed  = "failed"
+)
+
+// PostImportResult 单篇文章的导入结果
+type PostImportResult struct {
+	Source   string   `json:"source"`             // 文章在导入文件中的位置
+	Title    string   `json:"title"`              // 标题
+	Status   string   `json:"status"`             // 状态 created、skipped、failed
+	BlogID   int64    `json:"blogId,omitempty"`   // 创建的博客ID
+	Images   int      `json:"images"`             // 转存的图片数量
+	Warnings []string `json:"warnings,omitempty"` // 导入成功但需要注意的问题
+	Error    string   `json:"error,omitempty"`    // 跳过或失败的原因
+}
+
+// PostImportReport 文章导入报告
+type PostImportReport struct {
+	Format  string             `json:"format"`  // 导入文件格式 markdown、wxr
+	Created int                `json:"created"` // 创建的文章数量
+	Skipped int                `json:"skipped"` // 跳过的文章数量
+	Failed  int                `json:"failed"`  // 失败的文章数量
+	Posts   []PostImportResult `json:"posts"`   // 每篇文章的结果
+}
@@ -0,0 +1,50 @@
+package response
+
+// TableResponse 数据表概要
+type TableResponse struct {
+	Name      string `json:"name"`      // 表名
+	Comment   string `json:"comment"`   // 表注释
+	Rows      int64  `json:"rows"`      // 估计行数，来自统计信息
+	TotalSize int64  `json:"totalSize"` // 占用的磁盘空间，包括索引和 TOAST，单位字节
+	TableSize int64  `json:"tableSize"` // 表数据占用的磁盘空间
+	IndexSize int64  `json:"indexSize"` // 索引占用的磁盘空间
+}
+
+// ColumnResponse 数据表的列
+type ColumnResponse struct {
+	Name       string  `json:"name"`       // 列名
+	Type       string  `json:"type"`       // 类型，如 bigint、character varying(255)
+	Nullable   bool    `json:"nullable"`   // 是否可以为空
+	Default    *string `json:"default"`    // 默认值表达式
+	Comment    string  `json:"comment"`    // 注释，来自模型的 comment 标签
+	PrimaryKey bool    `json:"primaryKey"` // 是否为主键
+}
+
+// IndexResponse 数据表的索引
+type IndexResponse struct {
+	Name       string   `json:"name"`       // 索引名
+	Columns    []string `json:"columns"`    // 索引的列，表达式索引为空
+	Unique     bool     `json:"unique"`     // 是否唯一
+	Primary    bool     `json:"primary"`    // 是否为主键
+	Definition string   `json:"definition"` // 创建索引的语句
+	Size       int64    `json:"size"`       // 占用的磁盘空间
+}
+
+// ForeignKeyResponse 数据表的外键
+type ForeignKeyResponse struct {
+	Name       string   `json:"name"`       // 约束名
+	Columns    []string `json:"columns"`    // 本表的列
+	RefTable   string   `json:"refTable"`   // 引用的表
+	RefColumns []string `json:"refColumns"` // 引用的列
+	OnUpdate   string   `json:"onUpdate"`   // 更新时的动作
+	OnDelete   string   `json:"onDelete"`   // 删除时的动作
+}
+
+// TableDetailResponse 数据表结构详情
+type TableDetailResponse struct {
+	TableResponse
+	ExactRows   int64                `json:"exactRows"`   // 实际行数
+	Columns     []ColumnResponse     `json:"columns"`     // 列
+	Indexes     []IndexResponse      `json:"indexes"`     // 索引
+	ForeignKeys []ForeignKeyResponse `json:"foreignKeys"` // 外键
+}
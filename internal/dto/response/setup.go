@@ -0,0 +1,6 @@
+package response
+
+// SetupStatusResponse 首次安装状态
+type SetupStatusResponse struct {
+	Required bool `json:"required"` // 是否需要首次安装，已有超级管理员时为 false
+}
@@ -10,6 +10,7 @@ type UserResponse struct {
 	Username string `json:"username"` //用户账号
 	Ip       string `json:"ip"`       //登录IP
 	City     string `json:"city"`     //登录地点
+	Verified bool   `json:"verified"` //邮箱是否已验证
 }
 
 // TokenResponse 登陆成功返回的token概要
@@ -25,3 +26,50 @@ type SimpleUserResponse struct {
 	ID       int    `json:"id"`
 	NickName string `json:"nickName"`
 }
+
+// ScanKeysResponse 按游标分页的Redis键
+// @Description 按游标分页的Redis键，cursor 为 0 表示已遍历结束
+type ScanKeysResponse struct {
+	Keys   []string `json:"keys"`   //本批的键
+	Cursor uint64   `json:"cursor"` //下一批的游标
+}
+
+// LoginLockResponse 登录锁定信息
+// @Description 因登录失败次数过多而被锁定的账号或IP
+type LoginLockResponse struct {
+	Type     string `json:"type"`     //锁定类型 user:账号 ip:IP
+	Target   string `json:"target"`   //被锁定的账号或IP
+	Failures int64  `json:"failures"` //连续失败次数
+	LockedAt int64  `json:"lockedAt"` //锁定时间
+	Until    int64  `json:"until"`    //解锁时间
+}
+
+// AccessTokenResponse 个人访问令牌信息
+// @Description 个人访问令牌信息，不包含令牌明文
+type AccessTokenResponse struct {
+	ID         int      `json:"id"`         //令牌ID
+	Name       string   `json:"name"`       //令牌名称
+	Prefix     string   `json:"prefix"`     //令牌前缀
+	Scopes     []string `json:"scopes"`     //权限范围
+	ExpiresAt  int64    `json:"expiresAt"`  //过期时间，0为永不过期
+	LastUsedAt int64    `json:"lastUsedAt"` //最后使用时间
+	LastUsedIp string   `json:"lastUsedIp"` //最后使用IP
+	CreatedAt  int64    `json:"createdAt"`  //创建时间
+}
+
+// CreateAccessTokenResponse 创建个人访问令牌的返回
+// @Description 令牌明文只在创建时返回一次
+type CreateAccessTokenResponse struct {
+	AccessTokenResponse
+	Token string `json:"token"` //令牌明文
+}
+
+// UserIdentityResponse 已绑定的第三方账号
+// @Description 已绑定的第三方账号
+type UserIdentityResponse struct {
+	Provider  string `json:"provider"`  //第三方提供方
+	Email     string `json:"email"`     //第三方邮箱
+	Name      string `json:"name"`      //第三方账号名
+	Avatar    string `json:"avatar"`    //第三方头像
+	CreatedAt int64  `json:"createdAt"` //绑定时间
+}
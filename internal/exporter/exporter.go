@@ -0,0 +1,150 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+)
+
+// AssetsDir 图片在导出文件中的目录
+const AssetsDir = "assets"
+
+// Ext 导出文件的扩展名
+const Ext = ".zip"
+
+// Post 导出的博客，字段与导入时识别的 front matter 对应，可以重新导入或交给静态站点生成器发布
+type Post struct {
+	ID          int64
+	Title       string
+	Description string
+	Content     string    // Markdown 正文，图片地址已改写为 assets 下的相对路径
+	Category    string    // 分类，没有时为空
+	Topic       string    // 专题，没有时为空
+	Tags        []string  // 标签
+	Cover       string    // 封面地址
+	SourceURL   string    // 原文链接
+	CreatedAt   time.Time // 创建时间
+	UpdatedAt   time.Time // 更新时间
+	Private     bool      // 私有博客，导出为草稿，避免被静态站点生成器发布
+}
+
+// FrontMatter 生成 YAML front matter，字段按固定顺序输出，空字段省略
+func FrontMatter(post Post) ([]byte, error) {
+	meta := yaml.MapSlice{{Key: "title", Value: post.Title}}
+	add := func(key string, value any, ok bool) {
+		if ok {
+			meta = append(meta, yaml.MapItem{Key: key, Value: value})
+		}
+	}
+	add("description", post.Description, post.Description != "")
+	add("date", post.CreatedAt.Format(time.RFC3339), !post.CreatedAt.IsZero())
+	add("updated", post.UpdatedAt.Format(time.RFC3339), !post.UpdatedAt.IsZero())
+	add("category", post.Category, post.Category != "")
+	add("topic", post.Topic, post.Topic != "")
+	add("tags", post.Tags, len(post.Tags) > 0)
+	add("cover", post.Cover, post.Cover != "")
+	add("source_url", post.SourceURL, post.SourceURL != "")
+	add("draft", true, post.Private)
+	return yaml.Marshal(meta)
+}
+
+// Markdown 生成带 front matter 的 Markdown 文件内容
+func Markdown(post Post) ([]byte, error) {
+	front, err := FrontMatter(post)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	buf.Write(front)
+	buf.WriteString("---\n\n")
+	buf.WriteString(strings.TrimSpace(post.Content))
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// FileName 文章的文件名，使用 Jekyll 的“日期-标题”格式，去掉文件名中不允许的字符
+func FileName(post Post) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune(`/\:*?"<>|#%`, r):
+			return -1
+		case unicode.IsSpace(r):
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(post.Title))
+	slug = strings.Trim(slug, "-.")
+	if slug == "" {
+		slug = fmt.Sprint(post.ID)
+	}
+	if runes := []rune(slug); len(runes) > 80 {
+		slug = string(runes[:80])
+	}
+	if post.CreatedAt.IsZero() {
+		return slug + ".md"
+	}
+	return post.CreatedAt.Format("2006-01-02") + "-" + slug + ".md"
+}
+
+// Writer 将文章和图片写入 zip，文章在根目录，图片在 assets 目录
+type Writer struct {
+	zw    *zip.Writer
+	names map[string]bool
+}
+
+// NewWriter 创建导出文件
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{zw: zip.NewWriter(w), names: make(map[string]bool)}
+}
+
+// WritePost 写入文章，文件名重复时追加博客ID，返回文件在导出文件中的路径
+func (w *Writer) WritePost(post Post) (string, error) {
+	data, err := Markdown(post)
+	if err != nil {
+		return "", err
+	}
+	name := FileName(post)
+	if w.names[name] {
+		name = fmt.Sprintf("%s-%d.md", strings.TrimSuffix(name, ".md"), post.ID)
+	}
+	return name, w.write(name, bytes.NewReader(data))
+}
+
+// WriteAsset 写入图片，返回文章中引用图片的相对路径
+func (w *Writer) WriteAsset(name string, r io.Reader) (string, error) {
+	name = path.Join(AssetsDir, path.Base(name))
+	if w.names[name] {
+		return name, nil
+	}
+	return name, w.write(name, r)
+}
+
+// HasAsset 判断图片是否已写入
+func (w *Writer) HasAsset(name string) bool {
+	return w.names[path.Join(AssetsDir, path.Base(name))]
+}
+
+func (w *Writer) write(name string, r io.Reader) error {
+	f, err := w.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		return err
+	}
+	w.names[name] = true
+	return nil
+}
+
+// Close 写入 zip 的目录
+func (w *Writer) Close() error {
+	return w.zw.Close()
+}
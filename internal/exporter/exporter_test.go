@@ -0,0 +1,81 @@
+package exporter
+
+import (
+	"archive/zip"
+	"blog/internal/importer"
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	created := time.Date(2023, 5, 6, 7, 8, 9, 0, time.Local)
+	post := Post{
+		ID:          7,
+		Title:       "Go: 并发 #1",
+		Description: "描述",
+		Content:     "正文 ![a](assets/x.png)",
+		Category:    "后端",
+		Topic:       "专题",
+		Tags:        []string{"go", "yaml: 冒号"},
+		Cover:       "assets/x.png",
+		SourceURL:   "https://example.com/a",
+		CreatedAt:   created,
+		UpdatedAt:   created.Add(time.Hour),
+		Private:     true,
+	}
+	data, err := Markdown(post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := FileName(post)
+	if name != "2023-05-06-Go-并发-1.md" {
+		t.Fatalf("file name = %q", name)
+	}
+	parsed, err := importer.ParseMarkdown(name, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Title != post.Title || parsed.Description != post.Description || parsed.Content != post.Content ||
+		parsed.Cover != post.Cover || parsed.Link != post.SourceURL || parsed.Topic != post.Topic || !parsed.Draft ||
+		!slices.Equal(parsed.Categories, []string{"后端"}) || !slices.Equal(parsed.Tags, post.Tags) ||
+		!parsed.CreatedAt.Equal(post.CreatedAt) || !parsed.UpdatedAt.Equal(post.UpdatedAt) {
+		t.Fatalf("parsed = %+v", parsed)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	post := Post{ID: 1, Title: "same"}
+	if _, err := w.WritePost(post); err != nil {
+		t.Fatal(err)
+	}
+	post.ID = 2
+	if name, _ := w.WritePost(post); name != "same-2.md" {
+		t.Fatalf("duplicate name = %q", name)
+	}
+	for i := 0; i < 2; i++ {
+		if name, err := w.WriteAsset("a.png", strings.NewReader("png")); err != nil || name != "assets/a.png" {
+			t.Fatalf("asset = %q, %v", name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	if !slices.Equal(names, []string{"same.md", "same-2.md", "assets/a.png"}) {
+		t.Fatalf("names = %v", names)
+	}
+}
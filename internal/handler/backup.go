@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"blog/internal/dto/requests"
+	"blog/internal/service"
+	"blog/internal/utils"
+	"blog/pkg/common"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// BackupController 备份控制器
+type BackupController struct {
+	service *service.BackupService
+}
+
+// CreateBackup 在后台创建备份，返回备份ID
+func (b *BackupController) CreateBackup(ctx fiber.Ctx) error {
+	var req requests.BackupRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.Bind().Body(&req); err != nil {
+			return ResultErrorToResponse(common.BAD_REQUEST, ctx, "参数绑定失败!")
+		}
+	}
+
+	if errs := Validate(req); len(errs) > 0 {
+		return ResultValidatorErrorToResponse(ctx, errs)
+	}
+
+	id, err := b.service.CreateBackup(req)
+	if err != nil {
+		return ResultErrorToResponse(common.ERROR, ctx, err.Error())
+	}
+	return ResultSuccessToResponse(id, ctx)
+}
+
+// GetBackupProgress 获取备份进度
+func (b *BackupController) GetBackupProgress(ctx fiber.Ctx) error {
+	progress, err := b.service.GetProgress(ctx.Params("id"))
+	if err != nil {
+		return ResultErrorToResponse(common.ERROR, ctx, err.Error())
+	}
+	if progress == nil {
+		return ResultErrorToResponse(common.NOT_FOUND, ctx, "备份不存在或进度已过期")
+	}
+	return ResultSuccessToResponse(progress, ctx)
+}
+
+// GetBackups 获取备份文件列表
+func (b *BackupController) GetBackups(ctx fiber.Ctx) error {
+	list, err := b.service.GetBackups()
+	if err != nil {
+		return ResultErrorToResponse(common.ERROR, ctx, err.Error())
+	}
+	return ResultSuccessToResponse(list, ctx)
+}
+
+// Reauthenticate 重新验证密码，返回一次性下载令牌
+func (b *BackupController) Reauthenticate(ctx fiber.Ctx) error {
+	var req requests.BackupReauthRequest
+	if err := ctx.Bind().Body(&req); err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "参数绑定失败!")
+	}
+
+	if errs := Validate(req); len(errs) > 0 {
+		return ResultValidatorErrorToResponse(ctx, errs)
+	}
+
+	token, err := b.service.Reauthenticate(ctx.Locals("uid").(int), req.Password, utils.GetIPAddress(ctx))
+	if err != nil {
+		var lockErr *service.LoginLockError
+		if errors.As(err, &lockErr) {
+			return ResultErrorToResponse(lockErr.Code, ctx, lockErr.Error())
+		}
+		return ResultErrorToResponse(common.Forbidden, ctx, err.Error())
+	}
+	return ResultSuccessToResponse(token, ctx)
+}
+
+// DownloadBackup 下载备份文件，需要携带重新验证密码后获得的一次性令牌
+func (b *BackupController) DownloadBackup(ctx fiber.Ctx) error {
+	if !b.service.ConsumeDownloadToken(ctx.Locals("uid").(int), ctx.Query("token")) {
+		return ResultErrorToResponse(common.Forbidden, ctx, "下载令牌无效或已过期，请重新验证密码")
+	}
+
+	path, err := b.service.GetBackupPath(ctx.Params("name"))
+	if err != nil {
+		return ResultErrorToResponse(common.NOT_FOUND, ctx, err.Error())
+	}
+	return ctx.Download(path, ctx.Params("name"))
+}
+
+// NewBackupController 创建备份控制器实例
+func NewBackupController() *BackupController {
+	return &BackupController{service: service.NewBackupService()}
+}
@@ -6,6 +6,7 @@ import (
 	"blog/internal/models"
 	"blog/internal/service"
 	"blog/internal/utils"
+	"blog/pkg/background"
 	"blog/pkg/common"
 	"blog/pkg/logger"
 	"strconv"
@@ -109,8 +110,13 @@ func (b *BlogController) GetBlogByID(ctx fiber.Ctx) error {
 
 	logger.Info("获取博客", zap.Int64("id", blog.ID), zap.String("title", blog.Title))
 
-	blog.EyeCount = b.service.GetBlogEyeCount(blog.EyeCount, blog.ID)
-	return ResultSuccessToResponse(blog.ToBlogContentResponse(), ctx)
+	// 前端单页应用请求接口时 Referer 是博客页面本身，可通过 ref 参数传入 document.referrer
+	referrer := service.ReferrerHost(ctx.Query("ref", ctx.Get(fiber.HeaderReferer)))
+	visitor := service.ViewVisitor{IP: utils.GetIPAddress(ctx), UserAgent: ctx.Get(fiber.HeaderUserAgent), Referrer: referrer}
+	// 缓存返回的博客由并发请求共享，修改前先复制
+	view := *blog
+	view.EyeCount = b.service.GetBlogEyeCount(blog.EyeCount, blog.ID, visitor)
+	return ResultSuccessToResponse(view.ToBlogContentResponse(), ctx)
 }
 
 // isAuthorized 检查用户是否有权访问私密博客
@@ -278,12 +284,12 @@ func (b *BlogController) DeleteByIds(ctx fiber.Ctx) error {
 }
 
 func (b *BlogController) InitSearch(ctx fiber.Ctx) error {
-	go b.service.InitSearch()
+	background.Go(func() { b.service.InitSearch() })
 	return ResultSuccessToResponse(nil, ctx)
 }
 
 func (b *BlogController) InitEyeCount(ctx fiber.Ctx) error {
-	go b.service.InitEyeCount()
+	background.Go(func() { b.service.InitEyeCount() })
 	return ResultSuccessToResponse(nil, ctx)
 }
 
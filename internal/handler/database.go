@@ -1,13 +1,15 @@
 package handler
 
 import (
+	"blog/internal/dto/requests"
 	"blog/internal/service"
+	"blog/internal/utils"
 	"blog/pkg/common"
 	"blog/pkg/configs"
 	"blog/pkg/logger"
 	"bufio"
+	"errors"
 	"strconv"
-	"sync"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/valyala/fasthttp"
@@ -32,17 +34,36 @@ const (
 // DataBaseController struct
 type DataBaseController struct {
 	service service.DataBaseService
-	mu      sync.Mutex
+	console *service.SQLConsoleService
 }
 
 // Create a new DataBaseController
 func NewDataBaseController() *DataBaseController {
 	return &DataBaseController{
 		service: service.NewDataBaseService(),
+		console: service.NewSQLConsoleService(),
 	}
 }
 
-// Execute SQL handler
+// QuerySQL 在只读事务中执行查询，返回带类型的列和分页的行，explain 为 true 时返回执行计划
+func (d *DataBaseController) QuerySQL(ctx fiber.Ctx) error {
+	var req requests.SQLQueryRequest
+	if err := ctx.Bind().Body(&req); err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "参数绑定失败!")
+	}
+
+	if errs := Validate(req); len(errs) > 0 {
+		return ResultValidatorErrorToResponse(ctx, errs)
+	}
+
+	result, err := d.console.Query(ctx.Context(), req, utils.NewSystemLog(ctx, "database", "query", ""))
+	if err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, err.Error())
+	}
+	return ResultSuccessToResponse(result, ctx)
+}
+
+// ExecSQL 以写入模式执行语句，commit 为 true 时才提交，否则执行后回滚并返回预览
 func (d *DataBaseController) ExecSQL(ctx fiber.Ctx) error {
 	// Validate API Key
 	apiKey := ctx.Query("apiKey")
@@ -50,21 +71,20 @@ func (d *DataBaseController) ExecSQL(ctx fiber.Ctx) error {
 		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "Invalid API key")
 	}
 
-	// Read request body
-	body := ctx.Body()
-	if len(body) == 0 {
-		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "Failed to bind parameters")
+	var req requests.SQLExecRequest
+	if err := ctx.Bind().Body(&req); err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "参数绑定失败!")
 	}
 
-	// Asynchronously execute SQL
-	go func(sql string) {
-		d.mu.Lock()
-		defer d.mu.Unlock()
-		d.service.ExecDataBaseSQL(sql)
-		logger.Info("Executing SQL", zap.String("sql", sql))
-	}(string(body))
+	if errs := Validate(req); len(errs) > 0 {
+		return ResultValidatorErrorToResponse(ctx, errs)
+	}
 
-	return ResultSuccessToResponse(nil, ctx)
+	result, err := d.console.Execute(ctx.Context(), req, utils.NewSystemLog(ctx, "database", "write", ""))
+	if err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, err.Error())
+	}
+	return ResultSuccessToResponse(result, ctx)
 }
 
 // Handle insert SQL with streaming
@@ -125,6 +145,18 @@ func (d *DataBaseController) GetTableInsertSQL(ctx fiber.Ctx) error {
 
 	// Find the corresponding SQL generation function
 	sqlFunc, exists := sqlFuncMap[tableType]
+
+	// 也可以直接指定表名，新增的表不需要修改这里
+	if table := ctx.Query("table"); table != "" {
+		if !d.service.HasTable(table) {
+			return ResultErrorToResponse(common.BAD_REQUEST, ctx, "表不存在")
+		}
+		sqlFunc = func(page int) []string {
+			return d.service.GetInsertSQL(table, page)
+		}
+		exists = true
+	}
+
 	if !exists {
 		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "Processing failed")
 	}
@@ -141,3 +173,26 @@ func (d *DataBaseController) GetTableInsertSQL(ctx fiber.Ctx) error {
 
 	return nil
 }
+
+// GetTables 获取所有表及其估计行数和占用空间
+func (d *DataBaseController) GetTables(ctx fiber.Ctx) error {
+	tables, err := d.service.GetTables()
+	if err != nil {
+		logger.Error("获取表列表失败", zap.Error(err))
+		return ResultErrorToResponse(common.ERROR, ctx, "获取表列表失败")
+	}
+	return ResultSuccessToResponse(tables, ctx)
+}
+
+// GetTable 获取表的列、索引、外键、行数和占用空间
+func (d *DataBaseController) GetTable(ctx fiber.Ctx) error {
+	table, err := d.service.GetTable(ctx.Params("name"))
+	if errors.Is(err, service.ErrTableNotFound) {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, err.Error())
+	}
+	if err != nil {
+		logger.Error("获取表结构失败", zap.Error(err))
+		return ResultErrorToResponse(common.ERROR, ctx, "获取表结构失败")
+	}
+	return ResultSuccessToResponse(table, ctx)
+}
@@ -9,9 +9,7 @@ import (
 	"blog/pkg/common"
 	"blog/pkg/configs"
 	"blog/pkg/logger"
-	"blog/pkg/smail"
 	"fmt"
-	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -44,47 +42,6 @@ func (f *FileController) UploadAvatar(ctx fiber.Ctx) error {
 	return f.uploadSingleFile(ctx, "files", "只能上传一个头像文件", "文件类型不支持，请上传图片格式", "头像文件不能超过5MB")
 }
 
-func (f *FileController) DownloadTar(ctx fiber.Ctx) error {
-	var uuid = ctx.Query("id")
-
-	str, err := configs.REDIS.Get(common.TarKey + uuid).Result()
-
-	if err != nil {
-		return ResultErrorToResponse(common.ERROR, ctx, "不允许访问")
-	}
-
-	// 获取文件名
-	fileName := path.Base(str)
-
-	// 发送文件，自动触发下载
-	return ctx.Download(str, fileName)
-}
-
-func (f *FileController) TarDockerComposeData(ctx fiber.Ctx) error {
-
-	logger.Info("重要！！！重要！！！！重要！！！执行打包下载功能")
-
-	var req requests.TarRequest
-
-	if err := ctx.Bind().Body(&req); err != nil {
-		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "参数绑定失败!")
-	}
-
-	if errs := Validate(req); len(errs) > 0 {
-		return ResultValidatorErrorToResponse(ctx, errs)
-	}
-
-	go func() {
-		if uuid, err := service.CreateTarGzAndStoreRedis(req.Path, req.Min); err == nil {
-			var downloadUrl = "https://blog.shuyuz.com/api/v1/file/admin/system_file/tar?id=" + uuid
-			smail.SendEmail(configs.CONFIG.MyEmail, "打包完毕", true, fmt.Sprintf(`链接<br/> <a style="color:blue" href="%s">%s</a>`, downloadUrl, downloadUrl))
-			logger.Info("打包成功,已发送至你的邮箱", zap.String("下载链接", downloadUrl))
-		}
-	}()
-
-	return ResultSuccessToResponse(nil, ctx)
-}
-
 // UploadImage 处理图片上传
 func (f *FileController) UploadImage(ctx fiber.Ctx) error {
 	return f.uploadSingleFile(ctx, "files", "只能上传一个图片文件", "文件类型不支持，请上传图片格式", "图片文件不能超过5MB")
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"blog/internal/service"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// HealthController 存活与就绪检查控制器，供负载均衡探测使用，直接以 HTTP 状态码表示结果
+type HealthController struct {
+	service *service.HealthService
+}
+
+// Liveness 存活检查
+func (h *HealthController) Liveness(ctx fiber.Ctx) error {
+	return ctx.JSON(h.service.Liveness())
+}
+
+// Readiness 就绪检查，关键依赖不可用时返回 503
+func (h *HealthController) Readiness(ctx fiber.Ctx) error {
+	result := h.service.Readiness(ctx.Context())
+	if !result.Ready {
+		ctx.Status(fiber.StatusServiceUnavailable)
+	}
+	return ctx.JSON(result)
+}
+
+// NewHealthController 创建存活与就绪检查控制器实例
+func NewHealthController() *HealthController {
+	return &HealthController{service: service.NewHealthService()}
+}
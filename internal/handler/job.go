@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"blog/internal/dto/requests"
+	"blog/internal/dto/response"
+	"blog/internal/job"
+	"blog/internal/service"
+	"blog/pkg/common"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// JobController 定时任务控制器
+type JobController struct {
+	service *service.JobService
+}
+
+// GetJobs 获取定时任务列表
+func (j *JobController) GetJobs(ctx fiber.Ctx) error {
+	return ResultSuccessToResponse(j.service.GetJobs(), ctx)
+}
+
+// GetJobRuns 获取任务执行记录，可通过 name 参数筛选任务
+func (j *JobController) GetJobRuns(ctx fiber.Ctx) error {
+	prequest := ctx.Locals(common.PageRequest).(requests.RequestQuery)
+
+	page := response.Page{Page: prequest.Page, Size: prequest.Size}
+
+	if err := j.service.GetJobRuns(ctx.Query("name"), &page); err != nil {
+		return ResultErrorToResponse(common.ERROR, ctx, "获取任务执行记录失败")
+	}
+
+	return ResultSuccessToResponse(page, ctx)
+}
+
+// PauseJob 暂停任务
+func (j *JobController) PauseJob(ctx fiber.Ctx) error {
+	return j.jobResult(ctx, j.service.PauseJob(ctx.Params("name")))
+}
+
+// ResumeJob 恢复任务
+func (j *JobController) ResumeJob(ctx fiber.Ctx) error {
+	return j.jobResult(ctx, j.service.ResumeJob(ctx.Params("name")))
+}
+
+// TriggerJob 立即执行任务
+func (j *JobController) TriggerJob(ctx fiber.Ctx) error {
+	return j.jobResult(ctx, j.service.TriggerJob(ctx.Params("name")))
+}
+
+// jobResult 将任务操作的错误转换为响应
+func (j *JobController) jobResult(ctx fiber.Ctx, err error) error {
+	switch {
+	case err == nil:
+		return ResultSuccessToResponse(nil, ctx)
+	case errors.Is(err, job.ErrNotFound):
+		return ResultErrorToResponse(common.NOT_FOUND, ctx, err.Error())
+	case errors.Is(err, job.ErrRunning):
+		return ResultErrorToResponse(common.FAIL, ctx, err.Error())
+	default:
+		return ResultErrorToResponse(common.ERROR, ctx, "操作失败，请稍后重试")
+	}
+}
+
+// NewJobController 创建定时任务控制器实例
+func NewJobController() *JobController {
+	return &JobController{service: service.NewJobService()}
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"blog/internal/service"
+	"blog/internal/utils"
+	"blog/pkg/common"
+	"blog/pkg/configs"
+	"net/url"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// OAuthController 第三方登录控制器
+type OAuthController struct {
+	service *service.OAuthService
+}
+
+// GetProviders 获取已启用的第三方登录
+func (o *OAuthController) GetProviders(ctx fiber.Ctx) error {
+	return ResultSuccessToResponse(o.service.GetProviders(), ctx)
+}
+
+// Authorize 获取第三方登录的授权地址
+func (o *OAuthController) Authorize(ctx fiber.Ctx) error {
+	authURL, nonce, err := o.service.AuthCodeURL(ctx.Params("provider"), 0)
+	if err != nil {
+		return ResultErrorToResponse(common.FAIL, ctx, "获取授权地址失败")
+	}
+
+	setNonceCookie(ctx, nonce)
+
+	return ResultSuccessToResponse(authURL, ctx)
+}
+
+// Link 获取绑定第三方账号的授权地址
+func (o *OAuthController) Link(ctx fiber.Ctx) error {
+	authURL, nonce, err := o.service.AuthCodeURL(ctx.Params("provider"), ctx.Locals("uid").(int))
+	if err != nil {
+		return ResultErrorToResponse(common.FAIL, ctx, "获取授权地址失败")
+	}
+
+	setNonceCookie(ctx, nonce)
+
+	return ResultSuccessToResponse(authURL, ctx)
+}
+
+// Callback 第三方授权回调，配置了 redirectUrl 时跳转回前端，结果放在 URL fragment 中
+func (o *OAuthController) Callback(ctx fiber.Ctx) error {
+	provider := ctx.Params("provider")
+
+	if errMsg := ctx.Query("error"); errMsg != "" {
+		return o.callbackResult(ctx, url.Values{"error": {errMsg}}, common.FAIL, "第三方授权被拒绝", nil)
+	}
+
+	nonce := ctx.Cookies(common.OAuthNonceCookie)
+	ctx.ClearCookie(common.OAuthNonceCookie)
+
+	result, err := o.service.Callback(provider, ctx.Query("code"), ctx.Query("state"), nonce, utils.GetIPAddress(ctx))
+	if err != nil {
+		return o.callbackResult(ctx, url.Values{"error": {err.Error()}}, common.FAIL, err.Error(), nil)
+	}
+
+	if result.Linked {
+		return o.callbackResult(ctx, url.Values{"linked": {provider}}, common.SUCCESS, "", nil)
+	}
+
+	return o.callbackResult(ctx, url.Values{"token": {result.Token.Token}}, common.SUCCESS, "", result.Token)
+}
+
+// setNonceCookie 将授权的随机数保存到只能由服务端读取的 Cookie，回调时校验是否为同一个浏览器
+func setNonceCookie(ctx fiber.Ctx, nonce string) {
+	ctx.Cookie(&fiber.Cookie{
+		Name:     common.OAuthNonceCookie,
+		Value:    nonce,
+		Path:     "/",
+		MaxAge:   int(common.OAuthStateExpire.Seconds()),
+		Secure:   ctx.Secure(),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
+// callbackResult 返回回调结果
+func (o *OAuthController) callbackResult(ctx fiber.Ctx, fragment url.Values, code common.Code, message string, data any) error {
+	if redirect := configs.CONFIG.OAuth.RedirectURL; redirect != "" {
+		return ctx.Redirect().To(redirect + "#" + fragment.Encode())
+	}
+
+	if code != common.SUCCESS {
+		return ResultErrorToResponse(code, ctx, message)
+	}
+
+	return ResultSuccessToResponse(data, ctx)
+}
+
+// GetIdentities 获取当前用户绑定的第三方账号
+func (o *OAuthController) GetIdentities(ctx fiber.Ctx) error {
+	identities, err := o.service.GetIdentities(ctx.Locals("uid").(int))
+	if err != nil {
+		return ResultErrorToResponse(common.FAIL, ctx, "获取绑定列表失败")
+	}
+
+	return ResultSuccessToResponse(identities, ctx)
+}
+
+// Unlink 解除第三方绑定
+func (o *OAuthController) Unlink(ctx fiber.Ctx) error {
+	user := GetUserInfo(ctx)
+
+	if user == nil {
+		return ResultErrorToResponse(common.NoLogin, ctx, "未能获取用户信息，请重新登录")
+	}
+
+	if err := o.service.Unlink(user, ctx.Params("provider")); err != nil {
+		return ResultErrorToResponse(common.FAIL, ctx, err.Error())
+	}
+
+	return ResultSuccessToResponse(nil, ctx)
+}
+
+// NewOAuthController 创建第三方登录控制器实例
+func NewOAuthController() *OAuthController {
+	return &OAuthController{service: service.NewOAuthService()}
+}
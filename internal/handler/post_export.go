@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"blog/internal/dto/requests"
+	"blog/internal/exporter"
+	"blog/internal/service"
+	"blog/pkg/common"
+	"io"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// PostExportController 博客导出控制器
+type PostExportController struct {
+	service *service.PostExportService
+}
+
+// ExportPosts 导出博客为 Markdown 和图片的 zip，可以按分类或专题导出
+func (p *PostExportController) ExportPosts(ctx fiber.Ctx) error {
+	var req requests.PostExportRequest
+	if err := ctx.Bind().Query(&req); err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "参数绑定失败!")
+	}
+
+	uid := ctx.Locals("uid").(int)
+	rid := ctx.Locals("rid").(uint)
+
+	blogs, err := p.service.FindBlogs(uid, rid == uint(common.SuperAdminRoleId), req)
+	if err != nil {
+		return ResultErrorToResponse(common.ERROR, ctx, "查询博客失败")
+	}
+	if len(blogs) == 0 {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "没有可导出的博客")
+	}
+
+	return sendAttachment(ctx, "posts-"+time.Now().Format("20060102-150405")+exporter.Ext, func(w io.Writer) error {
+		return p.service.Export(ctx.Context(), w, blogs)
+	})
+}
+
+// NewPostExportController 创建博客导出控制器实例
+func NewPostExportController() *PostExportController {
+	return &PostExportController{service: service.NewPostExportService()}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"blog/internal/dto/requests"
+	"blog/internal/service"
+	"blog/pkg/common"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// PostImportController 文章导入控制器
+type PostImportController struct {
+	service *service.PostImportService
+}
+
+// ImportPosts 导入 Markdown 的 zip 或 WordPress 导出文件，文章归当前用户所有
+func (p *PostImportController) ImportPosts(ctx fiber.Ctx) error {
+	var req requests.PostImportRequest
+	if err := ctx.Bind().Query(&req); err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "参数绑定失败!")
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "请上传导入文件")
+	}
+
+	tmp, err := os.CreateTemp("", "posts-*"+strings.ToLower(filepath.Ext(file.Filename)))
+	if err != nil {
+		return ResultErrorToResponse(common.ERROR, ctx, err.Error())
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := ctx.SaveFile(file, tmp.Name()); err != nil {
+		return ResultErrorToResponse(common.ERROR, ctx, "保存导入文件失败")
+	}
+
+	report, err := p.service.ImportPosts(ctx.Locals("uid").(int), tmp.Name(), req)
+	if err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, err.Error())
+	}
+	return ResultSuccessToResponse(report, ctx)
+}
+
+// NewPostImportController 创建文章导入控制器实例
+func NewPostImportController() *PostImportController {
+	return &PostImportController{service: service.NewPostImportService()}
+}
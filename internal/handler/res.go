@@ -3,7 +3,9 @@ package handler
 import (
 	"blog/internal/models"
 	"blog/pkg/common"
+	"io"
 	"net/http"
+	"os"
 	"reflect"
 	"strings"
 
@@ -34,6 +36,42 @@ func ResultValidatorErrorToResponse(c fiber.Ctx, errs interface{}) error {
 	}) // 返回状态200和验证错误信息
 }
 
+// sendAttachment 先将 write 的内容写入临时文件，全部写入成功后才作为附件发送，失败时返回错误响应而不是不完整的文件
+func sendAttachment(c fiber.Ctx, filename string, write func(w io.Writer) error) error {
+	f, err := os.CreateTemp("", "attachment-*")
+	if err != nil {
+		return ResultErrorToResponse(common.ERROR, c, "创建临时文件失败")
+	}
+	tmp := tempFile{f}
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return ResultErrorToResponse(common.ERROR, c, err.Error())
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		tmp.Close()
+		return ResultErrorToResponse(common.ERROR, c, err.Error())
+	}
+
+	c.Attachment(filename)
+	return c.SendStream(tmp, int(size)) // 发送完成后关闭并删除临时文件
+}
+
+// tempFile 关闭时删除的临时文件
+type tempFile struct {
+	*os.File
+}
+
+func (f tempFile) Close() error {
+	err := f.File.Close()
+	os.Remove(f.Name())
+	return err
+}
+
 // ErrorResponse 验证错误响应结构体
 type ErrorResponse struct {
 	Error       bool   `json:"error"`   // 是否错误
@@ -76,7 +114,7 @@ func Validate(data interface{}) []ErrorResponse {
 
 // GetUserIdIfSuper 获取用户ID，如果是超级管理员则返回-1
 func GetUserIdIfSuper(user models.User) int {
-	if user.Role.Name == string(common.SuperAdminRoleId) {
+	if user.RoleID == uint(common.SuperAdminRoleId) {
 		return -1 // 超级管理员返回-1
 	}
 	return user.ID // 返回用户ID
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"blog/internal/dto/requests"
+	"blog/internal/dto/response"
+	"blog/internal/service"
+	"blog/pkg/common"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// SetupController 首次安装控制器
+type SetupController struct {
+	service *service.SetupService
+}
+
+// GetStatus 获取是否需要首次安装
+func (s *SetupController) GetStatus(ctx fiber.Ctx) error {
+	required, err := s.service.Required()
+	if err != nil {
+		return ResultErrorToResponse(common.ERROR, ctx, "获取安装状态失败")
+	}
+	return ResultSuccessToResponse(response.SetupStatusResponse{Required: required}, ctx)
+}
+
+// Setup 首次安装，创建站长账号和网站配置，完成后不再可用
+func (s *SetupController) Setup(ctx fiber.Ctx) error {
+	var req requests.SetupRequest
+	if err := ctx.Bind().Body(&req); err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "参数绑定失败!")
+	}
+
+	if errs := Validate(&req); len(errs) > 0 {
+		return ResultValidatorErrorToResponse(ctx, errs)
+	}
+
+	user, err := s.service.Setup(req)
+	switch {
+	case errors.Is(err, service.ErrSetupDone):
+		return ResultErrorToResponse(common.NOT_FOUND, ctx, err.Error())
+	case errors.Is(err, service.ErrSetupToken):
+		return ResultErrorToResponse(common.Forbidden, ctx, err.Error())
+	case err != nil:
+		return ResultErrorToResponse(common.FAIL, ctx, err.Error())
+	}
+	return ResultSuccessToResponse(user.ToVo(), ctx)
+}
+
+// NewSetupController 创建首次安装控制器实例
+func NewSetupController() *SetupController {
+	return &SetupController{service: service.NewSetupService()}
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"blog/internal/models"
+	"blog/pkg/cache"
+	"blog/pkg/common"
+	"blog/pkg/configs"
+	"blog/pkg/logger"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// testDB 连接 BLOG_TEST_DSN 指定的 PostgreSQL 测试库，未设置时跳过；在独立的 schema 中建表，结束后删除
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("BLOG_TEST_DSN")
+	if dsn == "" {
+		t.Skip("未设置 BLOG_TEST_DSN")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // search_path 只对当前连接生效
+
+	for _, sql := range []string{"DROP SCHEMA IF EXISTS handler_test CASCADE", "CREATE SCHEMA handler_test", "SET search_path TO handler_test"} {
+		if err := db.Exec(sql).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.AutoMigrate(&models.Role{}, &models.User{}); err != nil {
+		t.Fatal(err)
+	}
+	roles := []models.Role{
+		{ID: uint(common.UserRoleId), Name: "user"},
+		{ID: uint(common.AdminRoleId), Name: "admin"},
+		{ID: uint(common.SuperAdminRoleId), Name: "super"},
+	}
+	if err := db.Create(&roles).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Exec("DROP SCHEMA IF EXISTS handler_test CASCADE")
+		sqlDB.Close()
+	})
+	return db
+}
+
+func TestSetup(t *testing.T) {
+	logger.Logger = zap.NewNop()
+	configs.CONFIG = &configs.GlobalConfig{}
+	configs.DB = testDB(t)
+	configs.CACHE = cache.NewMemoryCache(1000)
+	t.Setenv(common.SetupTokenEnv, "setup-token")
+
+	// 两个控制器模拟同时启动的两个实例
+	app := fiber.New()
+	for i, controller := range []*SetupController{NewSetupController(), NewSetupController()} {
+		group := app.Group("/" + string(rune('a'+i)))
+		group.Get("/setup", controller.GetStatus)
+		group.Post("/setup", controller.Setup)
+	}
+	call := func(method, path, body string) common.R {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Error(err)
+			return common.R{}
+		}
+		var r common.R
+		json.NewDecoder(resp.Body).Decode(&r)
+		return r
+	}
+	request := func(token, username string) string {
+		return `{"token":"` + token + `","username":"` + username + `","password":"password1","email":"` + username + `@example.com","nickName":"站长"}`
+	}
+
+	if r := call(fiber.MethodGet, "/a/setup", ""); r.Code != common.SUCCESS || !r.Data.(map[string]any)["required"].(bool) {
+		t.Fatalf("未安装时状态 = %+v", r)
+	}
+	if r := call(fiber.MethodPost, "/a/setup", request("wrong", "webmaster1")); r.Code != common.Forbidden {
+		t.Fatalf("令牌错误时 code = %d", r.Code)
+	}
+
+	// 两个实例同时安装，只有一个能创建站长账号
+	var wg sync.WaitGroup
+	codes := make([]common.Code, 2)
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = call(fiber.MethodPost, "/"+string(rune('a'+i))+"/setup", request("setup-token", "webmaster"+string(rune('1'+i)))).Code
+		}()
+	}
+	wg.Wait()
+	if (codes[0] == common.SUCCESS) == (codes[1] == common.SUCCESS) {
+		t.Fatalf("同时安装的结果 = %v，应只有一个成功", codes)
+	}
+	var count int64
+	configs.DB.Model(&models.User{}).Where("role_id = ?", common.SuperAdminRoleId).Count(&count)
+	if count != 1 {
+		t.Fatalf("超级管理员数量 = %d", count)
+	}
+
+	for _, prefix := range []string{"/a", "/b"} {
+		if r := call(fiber.MethodPost, prefix+"/setup", request("setup-token", "webmaster3")); r.Code != common.NOT_FOUND {
+			t.Errorf("%s 安装完成后 code = %d", prefix, r.Code)
+		}
+		if r := call(fiber.MethodGet, prefix+"/setup", ""); r.Code != common.SUCCESS || r.Data.(map[string]any)["required"].(bool) {
+			t.Errorf("%s 安装完成后状态 = %+v", prefix, r)
+		}
+	}
+}
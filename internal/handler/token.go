@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"blog/internal/dto/requests"
+	"blog/internal/service"
+	"blog/pkg/common"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// AccessTokenController 个人访问令牌控制器
+type AccessTokenController struct {
+	service *service.AccessTokenService
+}
+
+// CreateAccessToken 创建个人访问令牌
+func (a *AccessTokenController) CreateAccessToken(ctx fiber.Ctx) error {
+	if ctx.Locals("pat") != nil {
+		return ResultErrorToResponse(common.Forbidden, ctx, "不能使用访问令牌创建新的令牌")
+	}
+
+	var req requests.AccessTokenRequest
+
+	if err := ctx.Bind().Body(&req); err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "令牌信息无效，请检查输入")
+	}
+
+	if errs := Validate(&req); len(errs) > 0 {
+		return ResultValidatorErrorToResponse(ctx, errs)
+	}
+
+	token, err := a.service.CreateAccessToken(ctx.Locals("uid").(int), req)
+	if err != nil {
+		return ResultErrorToResponse(common.FAIL, ctx, err.Error())
+	}
+
+	return ResultSuccessToResponse(token, ctx)
+}
+
+// GetAccessTokens 获取当前用户的个人访问令牌列表
+func (a *AccessTokenController) GetAccessTokens(ctx fiber.Ctx) error {
+	tokens, err := a.service.GetAccessTokens(ctx.Locals("uid").(int))
+	if err != nil {
+		return ResultErrorToResponse(common.FAIL, ctx, "获取令牌列表失败")
+	}
+
+	return ResultSuccessToResponse(tokens, ctx)
+}
+
+// RevokeAccessToken 撤销个人访问令牌
+func (a *AccessTokenController) RevokeAccessToken(ctx fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id", ""))
+	if err != nil || id <= 0 {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "令牌ID无效")
+	}
+
+	if err := a.service.RevokeAccessToken(id, ctx.Locals("uid").(int)); err != nil {
+		return ResultErrorToResponse(common.FAIL, ctx, "撤销令牌失败")
+	}
+
+	return ResultSuccessToResponse(nil, ctx)
+}
+
+// GetAccessTokenScopes 获取可选的权限范围
+func (a *AccessTokenController) GetAccessTokenScopes(ctx fiber.Ctx) error {
+	return ResultSuccessToResponse(common.AccessTokenScopes, ctx)
+}
+
+// NewAccessTokenController 创建个人访问令牌控制器实例
+func NewAccessTokenController() *AccessTokenController {
+	return &AccessTokenController{service: service.NewAccessTokenService()}
+}
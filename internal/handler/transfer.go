@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"blog/internal/dto/requests"
+	"blog/internal/service"
+	"blog/internal/transfer"
+	"blog/pkg/common"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// TransferController 站点数据导出与导入控制器
+type TransferController struct {
+	service *service.TransferService
+	backup  *service.BackupService
+}
+
+// Export 导出站点数据为 zip，每张表一个 NDJSON 文件；与下载备份一样需要携带重新验证密码后获得的一次性令牌
+func (t *TransferController) Export(ctx fiber.Ctx) error {
+	var req requests.ExportRequest
+	if err := ctx.Bind().Query(&req); err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "参数绑定失败!")
+	}
+
+	if !t.backup.ConsumeDownloadToken(ctx.Locals("uid").(int), req.Token) {
+		return ResultErrorToResponse(common.Forbidden, ctx, "下载令牌无效或已过期，请重新验证密码")
+	}
+
+	if err := t.service.CheckTables(req.Tables); err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, err.Error())
+	}
+
+	return sendAttachment(ctx, "export-"+time.Now().Format("20060102-150405")+transfer.Ext, func(w io.Writer) error {
+		_, err := t.service.Export(ctx.Context(), w, req.Tables)
+		return err
+	})
+}
+
+// Import 导入站点数据，dryRun 为 true 时只返回报告
+func (t *TransferController) Import(ctx fiber.Ctx) error {
+	var req requests.ImportRequest
+	if err := ctx.Bind().Query(&req); err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "参数绑定失败!")
+	}
+
+	if errs := Validate(req); len(errs) > 0 {
+		return ResultValidatorErrorToResponse(ctx, errs)
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "请上传导出文件")
+	}
+
+	tmp, err := os.CreateTemp("", "import-*"+transfer.Ext)
+	if err != nil {
+		return ResultErrorToResponse(common.ERROR, ctx, err.Error())
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := ctx.SaveFile(file, tmp.Name()); err != nil {
+		return ResultErrorToResponse(common.ERROR, ctx, "保存导出文件失败")
+	}
+
+	report, err := t.service.Import(ctx.Context(), tmp.Name(), transfer.ImportOptions{Strategy: req.Strategy, DryRun: req.DryRun})
+	if err != nil {
+		return ResultErrorToResponse(common.ERROR, ctx, err.Error())
+	}
+	return ResultSuccessToResponse(report, ctx)
+}
+
+// NewTransferController 创建站点数据导出与导入控制器实例
+func NewTransferController() *TransferController {
+	return &TransferController{service: service.NewTransferService(), backup: service.NewBackupService()}
+}
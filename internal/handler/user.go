@@ -6,7 +6,9 @@ import (
 	"blog/internal/dto/response"
 	"blog/internal/service"
 	"blog/internal/utils"
+	"blog/pkg/background"
 	"blog/pkg/common"
+	"errors"
 	"strconv"
 	"time"
 
@@ -27,12 +29,58 @@ func (u *UserController) SendCodeToEmail(ctx fiber.Ctx) error {
 	}
 
 	if err := u.service.SendCodeToEmail(email); err != nil {
+		if errors.Is(err, service.ErrEmailTooFrequent) {
+			return ResultErrorToResponse(common.FAIL, ctx, err.Error())
+		}
 		return ResultErrorToResponse(common.FAIL, ctx, "验证码发送失败，请稍后重试")
 	}
 
 	return ResultSuccessToResponse(nil, ctx)
 }
 
+// SendVerifyEmail 向当前用户的邮箱发送验证码
+func (u *UserController) SendVerifyEmail(ctx fiber.Ctx) error {
+	user := GetUserInfo(ctx)
+
+	if user == nil {
+		return ResultErrorToResponse(common.NoLogin, ctx, "未能获取用户信息，请重新登录")
+	}
+
+	if err := u.service.SendVerifyEmail(user); err != nil {
+		if errors.Is(err, service.ErrEmailTooFrequent) || user.Verified {
+			return ResultErrorToResponse(common.FAIL, ctx, err.Error())
+		}
+		return ResultErrorToResponse(common.FAIL, ctx, "验证码发送失败，请稍后重试")
+	}
+
+	return ResultSuccessToResponse(nil, ctx)
+}
+
+// VerifyEmail 验证当前用户的邮箱
+func (u *UserController) VerifyEmail(ctx fiber.Ctx) error {
+	user := GetUserInfo(ctx)
+
+	if user == nil {
+		return ResultErrorToResponse(common.NoLogin, ctx, "未能获取用户信息，请重新登录")
+	}
+
+	var req requests.VerifyEmailRequest
+
+	if err := ctx.Bind().Body(&req); err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "参数绑定失败")
+	}
+
+	if errs := Validate(&req); len(errs) > 0 {
+		return ResultValidatorErrorToResponse(ctx, errs)
+	}
+
+	if err := u.service.VerifyEmail(user, req.Code); err != nil {
+		return ResultErrorToResponse(common.FAIL, ctx, "邮箱验证失败，请检查验证码")
+	}
+
+	return ResultSuccessToResponse(nil, ctx)
+}
+
 // RegisteredUser 注册新用户
 func (u *UserController) RegisteredUser(ctx fiber.Ctx) error {
 	var userRequest requests.UserRequest
@@ -67,8 +115,14 @@ func (u *UserController) Login(ctx fiber.Ctx) error {
 		return ResultValidatorErrorToResponse(ctx, errs)
 	}
 
-	tokenResponse, err := u.service.Login(loginRequest)
+	ip := utils.GetIPAddress(ctx)
+
+	tokenResponse, err := u.service.Login(loginRequest, ip)
 	if err != nil {
+		var lockErr *service.LoginLockError
+		if errors.As(err, &lockErr) {
+			return ResultErrorToResponse(lockErr.Code, ctx, lockErr.Error())
+		}
 		return ResultErrorToResponse(common.LoginFail, ctx, "登录失败，请检查用户名和密码")
 	}
 
@@ -76,9 +130,7 @@ func (u *UserController) Login(ctx fiber.Ctx) error {
 		return err
 	}
 
-	ip := utils.GetIPAddress(ctx)
-
-	go u.updateUserStatus(tokenResponse.User.ID, ip)
+	background.Go(func() { u.updateUserStatus(tokenResponse.User.ID, ip) })
 
 	return nil
 }
@@ -227,9 +279,19 @@ func (u *UserController) UpdateWebSiteConfig(ctx fiber.Ctx) error {
 	return ResultSuccessToResponse(nil, ctx)
 }
 
-// GetRedisKeys 获取所有 Redis 键
+// GetRedisKeys 按游标分页获取 Redis 键
 func (u *UserController) GetRedisKeys(ctx fiber.Ctx) error {
-	keys, err := u.service.GetRedisKeys()
+	cursor, err := strconv.ParseUint(ctx.Query("cursor", "0"), 10, 64)
+	if err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "无效的游标")
+	}
+
+	size, err := strconv.ParseInt(ctx.Query("size", strconv.Itoa(common.ScanBatchCount)), 10, 64)
+	if err != nil || size <= 0 || size > common.ScanMaxCount {
+		size = common.ScanBatchCount
+	}
+
+	keys, err := u.service.GetRedisKeys(cursor, ctx.Query("match", "*"), size)
 	if err != nil {
 		return ResultErrorToResponse(common.ERROR, ctx, "无法获取 Redis 键，请稍后重试")
 	}
@@ -266,6 +328,26 @@ func (u *UserController) MatchDelKeys(ctx fiber.Ctx) error {
 	return ResultSuccessToResponse(nil, ctx)
 }
 
+// GetLoginLocks 获取登录锁定列表
+func (u *UserController) GetLoginLocks(ctx fiber.Ctx) error {
+	return ResultSuccessToResponse(u.service.GetLoginLocks(), ctx)
+}
+
+// ClearLoginLocks 解除登录锁定
+func (u *UserController) ClearLoginLocks(ctx fiber.Ctx) error {
+	var keys []string
+
+	if err := ctx.Bind().Body(&keys); err != nil || len(keys) == 0 {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "请提供要解除锁定的账号或IP")
+	}
+
+	if err := u.service.ClearLoginLocks(keys); err != nil {
+		return ResultErrorToResponse(common.ERROR, ctx, "解除登录锁定失败，请稍后重试")
+	}
+
+	return ResultSuccessToResponse(nil, ctx)
+}
+
 // Logout 用户登出
 func (u *UserController) Logout(ctx fiber.Ctx) error {
 	uid := ctx.Locals("uid")
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"blog/internal/service"
+	"blog/pkg/common"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// ViewController 浏览统计控制器
+type ViewController struct {
+	service *service.ViewService
+}
+
+// GetSiteViewTrend 获取全站的浏览趋势，可通过 start、end 参数指定日期范围
+func (v *ViewController) GetSiteViewTrend(ctx fiber.Ctx) error {
+	trend, err := v.service.GetViewTrend(0, ctx.Query("start"), ctx.Query("end"))
+	if err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, err.Error())
+	}
+	return ResultSuccessToResponse(trend, ctx)
+}
+
+// GetBlogViewTrend 获取单篇博客的浏览趋势
+func (v *ViewController) GetBlogViewTrend(ctx fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, "无效的博客ID")
+	}
+
+	trend, err := v.service.GetViewTrend(id, ctx.Query("start"), ctx.Query("end"))
+	if err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, err.Error())
+	}
+	return ResultSuccessToResponse(trend, ctx)
+}
+
+// GetViewSources 获取浏览来源统计，dimension 为空时返回所有维度，可通过 start、end、limit 参数筛选
+func (v *ViewController) GetViewSources(ctx fiber.Ctx) error {
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+
+	sources, err := v.service.GetViewSources(ctx.Query("dimension"), ctx.Query("start"), ctx.Query("end"), limit)
+	if err != nil {
+		return ResultErrorToResponse(common.BAD_REQUEST, ctx, err.Error())
+	}
+	return ResultSuccessToResponse(sources, ctx)
+}
+
+// NewViewController 创建浏览统计控制器实例
+func NewViewController() *ViewController {
+	return &ViewController{service: service.NewViewService()}
+}
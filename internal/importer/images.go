@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// markdownImage Markdown 图片 ![alt](url "title")，地址包含空格时用尖括号包围
+	markdownImage = regexp.MustCompile(`!\[[^\]]*\]\(\s*(?:<([^>]+)>|([^)\s]+))(?:\s+["'][^"']*["'])?\s*\)`)
+	// htmlImage HTML 图片 <img src="url">
+	htmlImage = regexp.MustCompile(`(?i)<img\b[^>]*?\bsrc\s*=\s*["']([^"']+)["']`)
+)
+
+// Images 返回正文中引用的图片地址，去掉重复项并保持出现顺序
+func Images(content string) []string {
+	var result []string
+	for _, re := range []*regexp.Regexp{markdownImage, htmlImage} {
+		for _, m := range re.FindAllStringSubmatchIndex(content, -1) {
+			start, end := group(m)
+			if ref := strings.TrimSpace(content[start:end]); ref != "" && !slices.Contains(result, ref) {
+				result = append(result, ref)
+			}
+		}
+	}
+	return result
+}
+
+// ReplaceImages 将正文中的图片地址替换为 mapping 中的新地址，不在 mapping 中的保持不变
+func ReplaceImages(content string, mapping map[string]string) string {
+	if len(mapping) == 0 {
+		return content
+	}
+	for _, re := range []*regexp.Regexp{markdownImage, htmlImage} {
+		content = replaceGroup(re, content, mapping)
+	}
+	return content
+}
+
+// replaceGroup 只替换匹配中的图片地址
+func replaceGroup(re *regexp.Regexp, content string, mapping map[string]string) string {
+	var (
+		b    strings.Builder
+		last int
+	)
+	for _, m := range re.FindAllStringSubmatchIndex(content, -1) {
+		start, end := group(m)
+		replacement, ok := mapping[strings.TrimSpace(content[start:end])]
+		if !ok {
+			continue
+		}
+		b.WriteString(content[last:start])
+		b.WriteString(replacement)
+		last = end
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// group 返回匹配中第一个参与匹配的分组的位置，即图片地址
+func group(m []int) (int, int) {
+	for i := 2; i+1 < len(m); i += 2 {
+		if m[i] >= 0 {
+			return m[i], m[i+1]
+		}
+	}
+	return m[0], m[0]
+}
+
+var (
+	// htmlTag HTML 标签和注释
+	htmlTag = regexp.MustCompile(`(?s)<!--.*?-->|<[^>]+>`)
+	// markdownLink Markdown 链接 [text](url)，只保留文字
+	markdownLink = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	// markdownMark 行首的标题、引用和列表标记以及强调、代码标记
+	markdownMark = regexp.MustCompile("(?m)^\\s*(?:#{1,6}|>|[-*+]|\\d+\\.)\\s+|[*_`~]+")
+)
+
+// Summary 去掉正文中的图片、标签和 Markdown 标记，返回不超过 limit 个字符的纯文本摘要
+func Summary(content string, limit int) string {
+	content = markdownImage.ReplaceAllString(content, "")
+	content = htmlTag.ReplaceAllString(content, "")
+	content = markdownLink.ReplaceAllString(content, "$1")
+	content = markdownMark.ReplaceAllString(content, "")
+	return Truncate(strings.Join(strings.Fields(content), " "), limit)
+}
+
+// Truncate 按字符截断字符串
+func Truncate(s string, limit int) string {
+	if runes := []rune(s); len(runes) > limit {
+		return string(runes[:limit])
+	}
+	return s
+}
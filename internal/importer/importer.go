@@ -0,0 +1,267 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// 导入文件的格式
+const (
+	FormatMarkdown = "markdown"
+	FormatWXR      = "wxr"
+)
+
+// MaxFileSize zip 中单个文件解压后的最大大小，避免压缩率极高的文件解压后占满内存
+const MaxFileSize = 64 << 20
+
+// MaxTotalSize 一个导入文件中所有读取的文件解压后的累计最大大小
+const MaxTotalSize = 1 << 30
+
+// MaxEntries zip 中最多的条目数
+const MaxEntries = 10000
+
+var (
+	// ErrTooLarge 文件解压后超过 MaxFileSize
+	ErrTooLarge = fmt.Errorf("文件解压后超过 %dMB", MaxFileSize>>20)
+	// ErrTotalTooLarge 累计解压的大小超过 MaxTotalSize
+	ErrTotalTooLarge = fmt.Errorf("导入文件解压后累计超过 %dMB", MaxTotalSize>>20)
+	// ErrTooManyEntries zip 中的条目超过 MaxEntries
+	ErrTooManyEntries = fmt.Errorf("导入文件中的条目超过 %d 个", MaxEntries)
+)
+
+// Post 从其他博客系统解析出的文章
+type Post struct {
+	Source      string    // 文章在导入文件中的位置，用于报告
+	Title       string    // 标题
+	Description string    // 描述，没有时为空
+	Content     string    // 正文，Markdown 或 HTML
+	Categories  []string  // 分类，按原顺序
+	Topic       string    // 专题，本站导出的博客才有
+	Tags        []string  // 标签
+	Cover       string    // 封面地址，可能是相对路径
+	CreatedAt   time.Time // 创建时间，没有时为零值
+	UpdatedAt   time.Time // 更新时间，没有时为零值
+	Draft       bool      // 是否为草稿
+	Link        string    // 原站点中的链接
+}
+
+// FileError 无法解析的文件
+type FileError struct {
+	Source string
+	Err    error
+}
+
+// Archive 导入文件中的文章和附件
+type Archive struct {
+	Format string
+	Posts  []Post
+	Errors []FileError
+
+	closer io.Closer
+	files  map[string]*zip.File
+	names  []string     // zip 中的文件，按路径排序
+	total  atomic.Int64 // 已读取的文件解压后的累计大小
+}
+
+// Open 打开 Markdown 的 zip 或 WordPress 导出的 WXR 文件，zip 中包含 WXR 文件时按 WXR 解析
+func Open(name string) (*Archive, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	magic := make([]byte, 4)
+	n, _ := io.ReadFull(f, magic)
+	f.Close()
+
+	if !bytes.Equal(magic[:n], []byte("PK\x03\x04")) {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		posts, err := ParseWXR(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return &Archive{Format: FormatWXR, Posts: posts}, nil
+	}
+
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(zr.File) > MaxEntries {
+		zr.Close()
+		return nil, ErrTooManyEntries
+	}
+	archive := &Archive{Format: FormatMarkdown, closer: zr, files: make(map[string]*zip.File)}
+	for _, f := range zr.File {
+		if !f.FileInfo().IsDir() {
+			archive.files[path.Clean(f.Name)] = f
+			archive.names = append(archive.names, path.Clean(f.Name))
+		}
+	}
+	sort.Strings(archive.names)
+
+	if err := archive.parse(); err != nil {
+		zr.Close()
+		return nil, err
+	}
+	return archive, nil
+}
+
+// parse 解析 zip 中的 WXR 文件或所有 Markdown 文件
+func (a *Archive) parse() error {
+	for _, name := range a.names {
+		if strings.EqualFold(path.Ext(name), ".xml") && !skipped(name) {
+			data, err := a.Read(name)
+			if err != nil {
+				return err
+			}
+			if !bytes.Contains(data, []byte("wordpress.org/export")) {
+				continue
+			}
+			posts, err := ParseWXR(bytes.NewReader(data))
+			if err != nil {
+				return fmt.Errorf("解析 %s 失败: %w", name, err)
+			}
+			a.Format = FormatWXR
+			a.Posts = append(a.Posts, posts...)
+		}
+	}
+	if a.Format == FormatWXR {
+		return nil
+	}
+
+	for _, name := range a.names {
+		if !IsMarkdown(name) || skipped(name) {
+			continue
+		}
+		data, err := a.Read(name)
+		if err == nil {
+			var post Post
+			if post, err = ParseMarkdown(name, data); err == nil {
+				a.Posts = append(a.Posts, post)
+				continue
+			}
+		}
+		a.Errors = append(a.Errors, FileError{Source: name, Err: err})
+	}
+	if len(a.Posts) == 0 && len(a.Errors) == 0 {
+		return errors.New("导入文件中没有 Markdown 文章或 WordPress 导出文件")
+	}
+	return nil
+}
+
+// Read 读取 zip 中的文件，解压后超过 MaxFileSize 时返回 ErrTooLarge，累计读取超过 MaxTotalSize 时返回 ErrTotalTooLarge
+func (a *Archive) Read(name string) ([]byte, error) {
+	f, ok := a.files[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	if f.UncompressedSize64 > MaxFileSize {
+		return nil, ErrTooLarge
+	}
+	remaining := MaxTotalSize - a.total.Load()
+	if remaining <= 0 || f.UncompressedSize64 > uint64(remaining) {
+		return nil, ErrTotalTooLarge
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	// 不依赖头部记录的大小，按实际解压的字节数再限制一次
+	data, err := io.ReadAll(io.LimitReader(rc, min(MaxFileSize, remaining)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxFileSize {
+		return nil, ErrTooLarge
+	}
+	if a.total.Add(int64(len(data))) > MaxTotalSize {
+		return nil, ErrTotalTooLarge
+	}
+	return data, nil
+}
+
+// Asset 查找文章中相对路径引用的附件，依次尝试相对文章所在目录、与文章同名的资源目录（Hexo），
+// 最后按路径后缀匹配（Hugo 的 static、Hexo 的 source 等站点根目录）；找到时返回附件在 zip 中的路径
+func (a *Archive) Asset(post Post, ref string) (string, bool) {
+	if a.files == nil || IsRemote(ref) || strings.HasPrefix(ref, "data:") {
+		return "", false
+	}
+	ref, _, _ = strings.Cut(ref, "#")
+	ref, _, _ = strings.Cut(ref, "?")
+	if unescaped, err := url.PathUnescape(ref); err == nil {
+		ref = unescaped
+	}
+	if ref == "" {
+		return "", false
+	}
+
+	dir := path.Dir(post.Source)
+	if !strings.HasPrefix(ref, "/") {
+		candidates := []string{
+			path.Join(dir, ref),
+			path.Join(dir, strings.TrimSuffix(path.Base(post.Source), path.Ext(post.Source)), ref),
+		}
+		for _, candidate := range candidates {
+			if _, ok := a.files[candidate]; ok {
+				return candidate, true
+			}
+		}
+	}
+
+	suffix := "/" + strings.TrimPrefix(path.Clean("/"+ref), "/")
+	for _, name := range a.names {
+		if strings.HasSuffix("/"+name, suffix) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Close 关闭导入文件
+func (a *Archive) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
+
+// IsMarkdown 判断是否为 Markdown 文件
+func IsMarkdown(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".md", ".markdown", ".mdown":
+		return true
+	}
+	return false
+}
+
+// IsRemote 判断是否为远程地址
+func IsRemote(ref string) bool {
+	return strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") || strings.HasPrefix(ref, "//")
+}
+
+// skipped 主题、依赖目录和说明文件中的 Markdown 不是文章，Hugo 的 _index.md 是栏目页
+func skipped(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		switch part {
+		case "node_modules", "themes", "public", "__MACOSX":
+			return true
+		}
+	}
+	base := strings.ToLower(path.Base(name))
+	return base == "_index.md" || strings.HasPrefix(base, "readme") || strings.HasPrefix(base, "license") || strings.HasPrefix(base, "changelog")
+}
@@ -0,0 +1,196 @@
+package importer
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	hexo := "title: Hello\ndate: 2020-01-02 10:00:00\ncategories:\n- [Go, Web]\ntags: go\ncover: img/a.png\n---\nintro\n<!-- more -->\nbody"
+	post, err := ParseMarkdown("source/_posts/hello.md", []byte(hexo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Title != "Hello" || post.Description != "intro" || post.Cover != "img/a.png" {
+		t.Fatalf("post = %+v", post)
+	}
+	if !reflect.DeepEqual(post.Categories, []string{"Go", "Web"}) || !reflect.DeepEqual(post.Tags, []string{"go"}) {
+		t.Fatalf("categories = %v, tags = %v", post.Categories, post.Tags)
+	}
+	if post.CreatedAt.Format("2006-01-02 15:04") != "2020-01-02 10:00" {
+		t.Fatalf("date = %v", post.CreatedAt)
+	}
+
+	hugo := "---\ntitle: \"Hugo\"\nlastmod: 2021-03-04T05:06:07+08:00\ndraft: true\nimages: [\"/cover.jpg\"]\ntags: [a, b]\n---\n\ncontent"
+	post, err = ParseMarkdown("content/posts/hugo.md", []byte(hugo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !post.Draft || post.Cover != "/cover.jpg" || post.UpdatedAt.IsZero() || post.Content != "content" || len(post.Tags) != 2 {
+		t.Fatalf("post = %+v", post)
+	}
+
+	jekyll := "---\nlayout: post\npublished: false\n---\n# Jekyll Title\ntext"
+	post, err = ParseMarkdown("_posts/2019-05-06-jekyll-post.md", []byte(jekyll))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Title != "Jekyll Title" || !post.Draft || post.CreatedAt.Format("2006-01-02") != "2019-05-06" {
+		t.Fatalf("post = %+v", post)
+	}
+
+	toml := "+++\ntitle = \"TOML\"\ndate = 2022-01-02T03:04:05+08:00\ntags = [\"a\", \"b\"]\ndraft = true\n[cover]\nimage = \"/c.png\"\n+++\nbody"
+	post, err = ParseMarkdown("content/posts/toml.md", []byte(toml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Title != "TOML" || !post.Draft || post.Cover != "/c.png" || post.Content != "body" || len(post.Tags) != 2 || post.CreatedAt.IsZero() {
+		t.Fatalf("post = %+v", post)
+	}
+	if _, err := ParseMarkdown("bad.md", []byte("+++\ntitle = \"x\"\n")); err == nil {
+		t.Fatal("没有结束标记时应返回错误")
+	}
+}
+
+func TestParseWXR(t *testing.T) {
+	wxr := `<?xml version="1.0"?>
+<rss xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/" xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+<item><title>cover.png</title><wp:post_id>9</wp:post_id><wp:post_type>attachment</wp:post_type><wp:attachment_url>https://old.example.com/cover.png</wp:attachment_url></item>
+<item>
+<title>WordPress Post</title>
+<link>https://old.example.com/?p=1</link>
+<content:encoded><![CDATA[<p>Hi <img src="https://old.example.com/a.png"></p>]]></content:encoded>
+<excerpt:encoded><![CDATA[Summary]]></excerpt:encoded>
+<wp:post_id>1</wp:post_id>
+<wp:post_date>2018-07-08 09:10:11</wp:post_date>
+<wp:status>publish</wp:status>
+<wp:post_type>post</wp:post_type>
+<category domain="category" nicename="news"><![CDATA[News]]></category>
+<category domain="post_tag" nicename="go"><![CDATA[Go]]></category>
+<wp:postmeta><wp:meta_key>_thumbnail_id</wp:meta_key><wp:meta_value>9</wp:meta_value></wp:postmeta>
+</item>
+<item><title>Page</title><wp:post_type>page</wp:post_type></item>
+</channel>
+</rss>`
+	posts, err := ParseWXR(strings.NewReader(wxr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 {
+		t.Fatalf("posts = %+v", posts)
+	}
+	post := posts[0]
+	if post.Title != "WordPress Post" || post.Description != "Summary" || post.Draft || post.Cover != "https://old.example.com/cover.png" {
+		t.Fatalf("post = %+v", post)
+	}
+	if !reflect.DeepEqual(post.Categories, []string{"News"}) || !reflect.DeepEqual(post.Tags, []string{"Go"}) {
+		t.Fatalf("categories = %v, tags = %v", post.Categories, post.Tags)
+	}
+	if !reflect.DeepEqual(Images(post.Content), []string{"https://old.example.com/a.png"}) {
+		t.Fatalf("images = %v", Images(post.Content))
+	}
+}
+
+func TestReplaceImages(t *testing.T) {
+	content := `![a](img/a.png "t") ![b](<b c.png>) <img class="x" src='/c.png'> ![a again](img/a.png)`
+	if got := Images(content); !reflect.DeepEqual(got, []string{"img/a.png", "b c.png", "/c.png"}) {
+		t.Fatalf("images = %v", got)
+	}
+	got := ReplaceImages(content, map[string]string{"img/a.png": "https://cdn/a.png", "/c.png": "https://cdn/c.png"})
+	want := `![a](https://cdn/a.png "t") ![b](<b c.png>) <img class="x" src='https://cdn/c.png'> ![a again](https://cdn/a.png)`
+	if got != want {
+		t.Fatalf("replaced = %s", got)
+	}
+}
+
+func TestArchiveAsset(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "posts.zip")
+	f, _ := os.Create(name)
+	zw := zip.NewWriter(f)
+	for _, file := range []string{
+		"blog/source/_posts/hello.md",
+		"blog/source/_posts/hello/local.png",
+		"blog/source/images/root.png",
+		"blog/themes/x/README.md",
+	} {
+		w, _ := zw.Create(file)
+		w.Write([]byte("---\ntitle: x\n---\n"))
+	}
+	zw.Close()
+	f.Close()
+
+	archive, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	if archive.Format != FormatMarkdown || len(archive.Posts) != 1 {
+		t.Fatalf("archive = %+v", archive)
+	}
+	post := archive.Posts[0]
+	for ref, want := range map[string]string{
+		"local.png":           "blog/source/_posts/hello/local.png",
+		"/images/root.png":    "blog/source/images/root.png",
+		"../images/root.png":  "blog/source/images/root.png",
+		"https://x/local.png": "",
+	} {
+		if got, _ := archive.Asset(post, ref); got != want {
+			t.Fatalf("asset %s = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestArchiveReadLimit(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "bomb.zip")
+	f, _ := os.Create(name)
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("post.md")
+	w.Write([]byte("---\ntitle: x\n---\n"))
+
+	// 压缩率极高的文件，解压后超过限制
+	w, _ = zw.Create("images/bomb.png")
+	w.Write(make([]byte, MaxFileSize+1))
+	zw.Close()
+	f.Close()
+
+	archive, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	if _, err := archive.Read("images/bomb.png"); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Read err = %v, want ErrTooLarge", err)
+	}
+
+	// 条目过多时拒绝打开
+	many := filepath.Join(t.TempDir(), "many.zip")
+	f, _ = os.Create(many)
+	zw = zip.NewWriter(f)
+	for i := 0; i <= MaxEntries; i++ {
+		zw.Create(fmt.Sprintf("posts/%d.md", i))
+	}
+	zw.Close()
+	f.Close()
+	if _, err := Open(many); !errors.Is(err, ErrTooManyEntries) {
+		t.Fatalf("Open err = %v, want ErrTooManyEntries", err)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	content := "## 标题\n\n![a](a.png) 这是**一段** [链接](https://x) <span>文字</span>\n- 列表"
+	if got := Summary(content, 255); got != "标题 这是一段 链接 文字 列表" {
+		t.Fatalf("summary = %q", got)
+	}
+	if got := Summary(content, 4); got != "标题 这" {
+		t.Fatalf("truncated summary = %q", got)
+	}
+}
@@ -0,0 +1,250 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// dateLayouts front matter 中常见的日期格式
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 -07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2006/01/02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// 各博客系统中表示同一含义的 front matter 字段，按优先级排列
+var (
+	descriptionKeys = []string{"description", "excerpt", "summary", "subtitle"}
+	updatedKeys     = []string{"updated", "lastmod", "last_modified_at", "modified"}
+	categoryKeys    = []string{"categories", "category"}
+	tagKeys         = []string{"tags", "tag"}
+	linkKeys        = []string{"source_url", "permalink"}
+	coverKeys       = []string{"cover", "cover_image", "coverImage", "image", "images", "thumbnail", "banner", "featured_image", "featureImage", "top_img", "index_img", "photos"}
+)
+
+// jekyllName Jekyll 文章文件名中的日期，如 2020-01-02-hello.md
+var jekyllName = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(.+)$`)
+
+// moreTag Hexo 的摘要分隔标记
+const moreTag = "<!--more-->"
+
+// ParseMarkdown 解析带 YAML 或 TOML front matter 的 Markdown 文章，兼容 Hexo、Hugo 和 Jekyll 的字段和约定
+func ParseMarkdown(source string, data []byte) (Post, error) {
+	text := strings.ReplaceAll(strings.TrimPrefix(string(data), "\ufeff"), "\r\n", "\n")
+	front, body, isToml, err := splitFrontMatter(text)
+	if err != nil {
+		return Post{}, err
+	}
+
+	meta := make(map[string]any)
+	if front != "" {
+		if isToml {
+			err = toml.Unmarshal([]byte(front), &meta)
+		} else {
+			err = yaml.Unmarshal([]byte(front), &meta)
+		}
+		if err != nil {
+			return Post{}, fmt.Errorf("front matter 格式错误: %w", err)
+		}
+	}
+
+	post := Post{
+		Source:      source,
+		Title:       str(meta["title"]),
+		Description: first(meta, descriptionKeys),
+		Content:     strings.TrimSpace(body),
+		Categories:  list(meta, categoryKeys),
+		Topic:       str(meta["topic"]),
+		Tags:        list(meta, tagKeys),
+		Cover:       cover(meta),
+		Link:        first(meta, linkKeys),
+		Draft:       isTrue(meta["draft"]) || meta["published"] == false || strings.Contains("/"+source, "/_drafts/"),
+	}
+
+	post.CreatedAt = parseDate(str(meta["date"]))
+	post.UpdatedAt = parseDate(first(meta, updatedKeys))
+
+	// Jekyll 的文件名中包含日期，标题可以省略
+	base := strings.TrimSuffix(path.Base(source), path.Ext(source))
+	if m := jekyllName.FindStringSubmatch(base); m != nil {
+		if post.CreatedAt.IsZero() {
+			post.CreatedAt = parseDate(m[1])
+		}
+		base = m[2]
+	}
+	if post.Title == "" {
+		post.Title = heading(post.Content)
+	}
+	if post.Title == "" {
+		post.Title = base
+	}
+
+	if post.Description == "" {
+		if excerpt, _, ok := strings.Cut(strings.ReplaceAll(post.Content, "<!-- more -->", moreTag), moreTag); ok {
+			post.Description = strings.TrimSpace(excerpt)
+		}
+	}
+	return post, nil
+}
+
+// splitFrontMatter 拆分 front matter 和正文，isToml 表示 front matter 是 Hugo 的 TOML：
+// 支持 --- 包围的 YAML、+++ 包围的 TOML，以及 Hexo 省略开头 --- 的写法
+func splitFrontMatter(text string) (front, body string, isToml bool, err error) {
+	for _, delim := range []string{"---", "+++"} {
+		if !strings.HasPrefix(text, delim+"\n") {
+			continue
+		}
+		isToml = delim == "+++"
+		rest := text[4:]
+		if strings.HasPrefix(rest, delim+"\n") {
+			return "", rest[4:], isToml, nil
+		}
+		end := strings.Index(rest, "\n"+delim+"\n")
+		if end < 0 {
+			if strings.HasSuffix(rest, "\n"+delim) {
+				return rest[:len(rest)-4], "", isToml, nil
+			}
+			return "", "", isToml, errors.New("front matter 没有结束标记 " + delim)
+		}
+		return rest[:end], rest[end+5:], isToml, nil
+	}
+
+	// Hexo 允许省略开头的 ---，只有结束标记
+	if front, body, ok := strings.Cut(text, "\n---\n"); ok && strings.HasPrefix(front, "title:") {
+		return front, body, false, nil
+	}
+	return "", text, false, nil
+}
+
+// heading 取正文中第一个一级标题
+func heading(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(line[2:])
+		}
+	}
+	return ""
+}
+
+// parseDate 按常见格式解析日期，无法解析时返回零值
+func parseDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// str 将 front matter 中的标量转为字符串
+func str(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+}
+
+// first 返回第一个不为空的字段
+func first(meta map[string]any, keys []string) string {
+	for _, key := range keys {
+		if value := str(meta[key]); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// list 合并多个字段中的字符串列表，去掉重复项；
+// 字段可以是字符串、以逗号分隔的字符串、列表或 Hexo 的多级分类（嵌套列表）
+func list(meta map[string]any, keys []string) []string {
+	var result []string
+	var add func(value any)
+	add = func(value any) {
+		switch v := value.(type) {
+		case []any:
+			for _, item := range v {
+				add(item)
+			}
+		case string:
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" && !contains(result, item) {
+					result = append(result, item)
+				}
+			}
+		case nil:
+		default:
+			add(str(v))
+		}
+	}
+	for _, key := range keys {
+		add(meta[key])
+	}
+	return result
+}
+
+// cover 取封面字段，字段可以是字符串、列表（Hugo 的 images）或包含 image 的对象（Hugo 的 cover，YAML 和 TOML 解析出的类型不同）
+func cover(meta map[string]any) string {
+	for _, key := range coverKeys {
+		switch v := meta[key].(type) {
+		case string:
+			if v = strings.TrimSpace(v); v != "" {
+				return v
+			}
+		case []any:
+			if len(v) > 0 {
+				if s := str(v[0]); s != "" {
+					return s
+				}
+			}
+		case map[any]any:
+			if s := str(v["image"]); s != "" {
+				return s
+			}
+		case map[string]any:
+			if s := str(v["image"]); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func isTrue(value any) bool {
+	b, ok := value.(bool)
+	return ok && b
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// wxrDateLayout WordPress 导出文件中 post_date 的格式
+const wxrDateLayout = "2006-01-02 15:04:05"
+
+// wxrRSS WordPress 导出文件（WXR），只按元素名匹配以兼容不同版本的命名空间
+type wxrRSS struct {
+	Channel struct {
+		Items []wxrItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type wxrItem struct {
+	Title         string       `xml:"title"`
+	Link          string       `xml:"link"`
+	PubDate       string       `xml:"pubDate"`
+	Encoded       []wxrEncoded `xml:"encoded"` // content:encoded 为正文，excerpt:encoded 为摘要
+	PostID        string       `xml:"post_id"`
+	PostDate      string       `xml:"post_date"`
+	PostModified  string       `xml:"post_modified"`
+	Status        string       `xml:"status"`
+	PostType      string       `xml:"post_type"`
+	AttachmentURL string       `xml:"attachment_url"`
+	Categories    []struct {
+		Domain string `xml:"domain,attr"`
+		Name   string `xml:",chardata"`
+	} `xml:"category"`
+	Meta []struct {
+		Key   string `xml:"meta_key"`
+		Value string `xml:"meta_value"`
+	} `xml:"postmeta"`
+}
+
+type wxrEncoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// ParseWXR 解析 WordPress 导出文件中的文章，正文保持 HTML；
+// 特色图片通过 _thumbnail_id 关联到附件的地址，非 publish 状态的文章视为草稿
+func ParseWXR(r io.Reader) ([]Post, error) {
+	var rss wxrRSS
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	if err := decoder.Decode(&rss); err != nil {
+		return nil, fmt.Errorf("WordPress 导出文件格式错误: %w", err)
+	}
+
+	attachments := make(map[string]string)
+	for _, item := range rss.Channel.Items {
+		if item.PostType == "attachment" && item.AttachmentURL != "" {
+			attachments[item.PostID] = item.AttachmentURL
+		}
+	}
+
+	var posts []Post
+	for _, item := range rss.Channel.Items {
+		if item.PostType != "post" {
+			continue
+		}
+		post := Post{
+			Source:    "wxr:" + item.PostID,
+			Title:     strings.TrimSpace(item.Title),
+			Link:      strings.TrimSpace(item.Link),
+			Draft:     item.Status != "publish",
+			CreatedAt: parseWXRDate(item.PostDate, item.PubDate),
+			UpdatedAt: parseWXRDate(item.PostModified, ""),
+		}
+		for _, encoded := range item.Encoded {
+			if strings.Contains(encoded.XMLName.Space, "excerpt") {
+				post.Description = strings.TrimSpace(encoded.Value)
+			} else {
+				post.Content = strings.TrimSpace(encoded.Value)
+			}
+		}
+		for _, category := range item.Categories {
+			name := strings.TrimSpace(category.Name)
+			if name == "" {
+				continue
+			}
+			switch category.Domain {
+			case "category":
+				if !contains(post.Categories, name) {
+					post.Categories = append(post.Categories, name)
+				}
+			case "post_tag":
+				if !contains(post.Tags, name) {
+					post.Tags = append(post.Tags, name)
+				}
+			}
+		}
+		for _, meta := range item.Meta {
+			if meta.Key == "_thumbnail_id" {
+				post.Cover = attachments[strings.TrimSpace(meta.Value)]
+			}
+		}
+		posts = append(posts, post)
+	}
+	return posts, nil
+}
+
+// parseWXRDate 优先使用 post_date，草稿的 post_date 为全零时使用 RSS 的发布时间
+func parseWXRDate(postDate, pubDate string) time.Time {
+	if t, err := time.ParseInLocation(wxrDateLayout, strings.TrimSpace(postDate), time.Local); err == nil {
+		return t
+	}
+	return parseDate(pubDate)
+}
@@ -1,69 +1,552 @@
 package job
 
 import (
+	"blog/internal/models"
+	"blog/pkg/background"
+	"blog/pkg/cache"
+	"blog/pkg/common"
+	"blog/pkg/configs"
 	"blog/pkg/logger"
+	"blog/pkg/metrics"
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"runtime/debug"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// 任务执行状态
+const (
+	StatusRunning = "running" // 执行中
+	StatusSuccess = "success" // 执行成功
+	StatusFailed  = "failed"  // 返回错误
+	StatusPanic   = "panic"   // 发生panic
+	StatusSkipped = "skipped" // 上一次执行未结束，本次跳过
+)
+
+// 任务触发方式
+const (
+	TriggerCron   = "cron"   // 定时触发
+	TriggerManual = "manual" // 手动触发
+)
+
+// 多实例部署时的调度方式
+const (
+	ModeLock   = "lock"   // 每个实例都调度，执行前抢占本次调度时间点和任务锁，同一时间点只在一个实例执行一次
+	ModeLeader = "leader" // 选举出一个实例负责调度，其余实例只在手动执行时抢占任务锁
+)
+
+var (
+	ErrNotFound = errors.New("任务不存在")
+	ErrRunning  = errors.New("任务正在执行中")
+)
+
+// Job 定时任务
 type Job struct {
-	Hour        int // 24表示每天凌晨执行
-	Description string
-	Eq          bool
-	Job         func()
+	Name        string                          // 任务唯一名称，同时作为任务锁的名称
+	Spec        string                          // 标准cron表达式，如 "0 1 * * *"，也支持 "@every 6h"、"@daily"
+	Description string                          // 任务描述
+	Job         func(ctx context.Context) error // 任务内容，失去任务锁时 ctx 会被取消
 }
 
+// Store 任务执行记录与暂停状态的存储
+type Store interface {
+	CreateRun(run *models.JobRun) error
+	FinishRun(run *models.JobRun) error
+	PausedJobs() ([]string, error)
+	SetPaused(name string, paused bool) error
+}
+
+// Overview 调度器状态
+type Overview struct {
+	Mode     string   `json:"mode"`     // 调度方式
+	Leader   bool     `json:"leader"`   // 本实例是否负责调度
+	Instance string   `json:"instance"` // 本实例标识
+	Jobs     []Status `json:"jobs"`
+}
+
+// Status 任务当前状态
+type Status struct {
+	Name        string `json:"name"`
+	Spec        string `json:"spec"`
+	Description string `json:"description"`
+	Paused      bool   `json:"paused"`
+	Running     bool   `json:"running"`
+	Next        int64  `json:"next"` // 下次执行时间，暂停时为0
+	Prev        int64  `json:"prev"` // 上次开始执行时间
+}
+
+// entry 已注册的任务
+type entry struct {
+	job      Job
+	schedule cron.Schedule
+	paused   atomic.Bool
+	running  atomic.Bool
+	prev     atomic.Int64
+	next     atomic.Int64
+}
+
+// leaseKey 任务锁在 context 中的key
+type leaseKey struct{}
+
 var (
-	jobs  = []Job{}
-	mutex sync.Mutex
+	entries  = map[string]*entry{}
+	store    Store
+	started  bool
+	leader   atomic.Bool
+	instance = newInstance()
+	mutex    sync.Mutex
+	quit     = make(chan struct{})
+	stopped  bool
 )
 
+// AddJob 注册定时任务，同名任务只注册一次
 func AddJob(job Job) {
+	schedule, err := cron.ParseStandard(job.Spec)
+	if err != nil {
+		log.Fatalf("任务 %s 的cron表达式无效: %v", job.Name, err)
+	}
+
 	mutex.Lock()
 	defer mutex.Unlock()
-	jobs = append(jobs, job)
+
+	if _, ok := entries[job.Name]; ok {
+		return
+	}
+
+	e := &entry{job: job, schedule: schedule}
+	entries[job.Name] = e
+
+	if started && !stopped {
+		go e.loop()
+	}
 }
 
+// SetStore 设置执行记录的存储
+func SetStore(s Store) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	store = s
+}
+
+// StartJob 启动调度，每个任务按各自的cron表达式执行
 func StartJob() {
-	go func() {
-		// 打印任务列表
-		log.Println("==================================任务列表==================================")
-		mutex.Lock()
-		for _, job := range jobs {
-			if job.Eq {
-				log.Printf("任务：%s - 每天到%d点执行", job.Description, job.Hour)
-			} else {
-				log.Printf("任务：%s - 每%d小时执行", job.Description, job.Hour)
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if started {
+		return
+	}
+	started = true
+
+	loadPaused()
+
+	if mode() == ModeLeader {
+		go campaign()
+	} else {
+		leader.Store(true)
+	}
+
+	log.Println("==================================任务列表==================================")
+	log.Printf("调度方式：%s，实例：%s", mode(), instance)
+	for _, name := range sortedNames() {
+		e := entries[name]
+		log.Printf("任务：%s(%s) - %s", e.job.Description, e.job.Name, e.job.Spec)
+		go e.loop()
+	}
+	log.Println("==================================任务列表==================================")
+}
+
+// Stop 停止调度并释放调度锁，正在执行的任务由 background.Shutdown 等待完成
+func Stop() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if stopped {
+		return
+	}
+	stopped = true
+	close(quit)
+}
+
+// Info 获取调度器与所有任务的状态
+func Info() Overview {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	loadPaused()
+
+	var list = make([]Status, 0, len(entries))
+	for _, name := range sortedNames() {
+		list = append(list, entries[name].status())
+	}
+	return Overview{Mode: mode(), Leader: leader.Load(), Instance: instance, Jobs: list}
+}
+
+// Pause 暂停任务，正在执行的不受影响
+func Pause(name string) error {
+	return setPaused(name, true)
+}
+
+// Resume 恢复任务
+func Resume(name string) error {
+	return setPaused(name, false)
+}
+
+// Trigger 立即执行一次任务，任务正在本实例或其他实例执行时返回 ErrRunning
+func Trigger(name string) error {
+	e, err := find(name)
+	if err != nil {
+		return err
+	}
+	return e.start(TriggerManual)
+}
+
+// WithLock 持有任务锁执行 fn，用于在定时任务之外手动执行同一操作，任务锁被占用时返回 ErrRunning
+func WithLock(name string, fn func(ctx context.Context) error) error {
+	lease, err := cache.Acquire(configs.CACHE, name, common.JobLockExpire)
+	if errors.Is(err, cache.ErrLocked) {
+		return ErrRunning
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := hold(lease)
+	defer stop()
+	return fn(ctx)
+}
+
+// Check 确认当前任务仍持有任务锁，任务在执行缓存、文件等非数据库的副作用前调用；不在任务锁内执行时直接返回 nil
+// 检查与写入之间租约仍可能过期，数据库写入应使用 Transaction
+func Check(ctx context.Context) error {
+	if lease, ok := ctx.Value(leaseKey{}).(*cache.Lease); ok {
+		return lease.Check()
+	}
+	return nil
+}
+
+// Transaction 在事务中执行任务的数据库写入
+// 在任务锁内执行时先将任务锁令牌写入 job_states，已有更大的令牌写入过时返回 cache.ErrLeaseLost，
+// 该行锁持有到事务结束，过期的持有者无法与新的持有者同时提交
+func Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return configs.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if lease, ok := ctx.Value(leaseKey{}).(*cache.Lease); ok {
+			if err := fence(tx, lease); err != nil {
+				return err
 			}
 		}
-		mutex.Unlock()
-		log.Println("==================================任务列表==================================")
+		return fn(tx)
+	})
+}
 
-		// 等待到下一个整点
-		now := time.Now()
-		next := now.Truncate(time.Hour).Add(time.Hour)
-		time.Sleep(time.Until(next))
+// fence 写入任务锁令牌，只有令牌不小于已写入的令牌时才会成功
+func fence(tx *gorm.DB, lease *cache.Lease) error {
+	result := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"fence", "updated_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: models.JobStateTable + ".fence <= excluded.fence"},
+		}},
+	}).Create(&models.JobState{Name: lease.Name(), Fence: lease.Token()})
+	if result.Error != nil {
+		return fmt.Errorf("写入任务锁令牌失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return cache.ErrLeaseLost
+	}
+	return nil
+}
 
-		// 每小时检查任务
-		ticker := time.NewTicker(time.Hour)
+// hold 定时续期任务锁，续期失败时取消 context，stop 停止续期并释放锁
+func hold(lease *cache.Lease) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), leaseKey{}, lease))
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(common.JobLockExpire / 3)
 		defer ticker.Stop()
 
 		for {
-			hour := time.Now().Hour()
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := lease.Refresh(); err != nil {
+					logger.Error("任务锁续期失败", zap.Int64("token", lease.Token()), zap.Error(err))
+					cancel()
+					return
+				}
+			}
+		}
+	}()
 
-			mutex.Lock()
-			for _, job := range jobs {
-				if (job.Eq && job.Hour == hour) || (!job.Eq && hour%job.Hour == 0) {
-					logger.Info("执行任务", zap.String("desc", job.Description), zap.Bool("eq", job.Eq), zap.Int("hour", job.Hour))
-					go job.Job()
+	return ctx, func() {
+		close(done)
+		cancel()
+		if err := lease.Release(); err != nil {
+			logger.Error("释放任务锁失败", zap.Int64("token", lease.Token()), zap.Error(err))
+		}
+	}
+}
+
+// campaign 选举调度实例，持有调度锁的实例负责调度所有任务
+func campaign() {
+	ticker := time.NewTicker(common.JobLeaderExpire / 3)
+	defer ticker.Stop()
+
+	var lease *cache.Lease
+	for {
+		if lease != nil {
+			if err := lease.Refresh(); err != nil {
+				lease = nil
+				leader.Store(false)
+				logger.Error("失去调度权", zap.String("instance", instance), zap.Error(err))
+			}
+		}
+
+		if lease == nil {
+			if l, err := cache.Acquire(configs.CACHE, common.JobLeaderLock, common.JobLeaderExpire); err == nil {
+				lease = l
+				leader.Store(true)
+				logger.Info("取得调度权", zap.String("instance", instance), zap.Int64("token", l.Token()))
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-quit:
+			if lease != nil {
+				leader.Store(false)
+				if err := lease.Release(); err != nil {
+					logger.Error("释放调度锁失败", zap.Error(err))
 				}
 			}
-			mutex.Unlock()
+			return
+		}
+	}
+}
+
+// mode 当前的调度方式
+func mode() string {
+	if configs.CONFIG.Server.CronMode == ModeLeader {
+		return ModeLeader
+	}
+	return ModeLock
+}
+
+// newInstance 本实例的标识
+func newInstance() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// find 查找任务
+func find(name string) (*entry, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	e, ok := entries[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return e, nil
+}
+
+// setPaused 修改任务的暂停状态并持久化
+func setPaused(name string, paused bool) error {
+	e, err := find(name)
+	if err != nil {
+		return err
+	}
+
+	if store != nil {
+		if err := store.SetPaused(name, paused); err != nil {
+			return err
+		}
+	}
+
+	e.paused.Store(paused)
+	logger.Info("修改任务状态", zap.String("name", name), zap.Bool("paused", paused))
+	return nil
+}
+
+// loadPaused 从存储中同步暂停状态，多实例部署时在任一实例暂停都会生效，调用方需持有锁
+func loadPaused() {
+	if store == nil {
+		return
+	}
+	names, err := store.PausedJobs()
+	if err != nil {
+		logger.Error("获取任务暂停状态失败", zap.Error(err))
+		return
+	}
+	for name, e := range entries {
+		e.paused.Store(slices.Contains(names, name))
+	}
+}
+
+// sortedNames 按名称排序的任务列表，调用方需持有锁
+func sortedNames() []string {
+	var names = make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// status 任务当前状态
+func (e *entry) status() Status {
+	s := Status{
+		Name:        e.job.Name,
+		Spec:        e.job.Spec,
+		Description: e.job.Description,
+		Paused:      e.paused.Load(),
+		Running:     e.running.Load(),
+		Prev:        e.prev.Load(),
+	}
+	if !s.Paused {
+		s.Next = e.next.Load()
+	}
+	return s
+}
+
+// loop 按cron表达式循环调度任务
+func (e *entry) loop() {
+	for {
+		next := e.schedule.Next(time.Now())
+		e.next.Store(next.UnixMilli())
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+		case <-quit:
+			timer.Stop()
+			return
+		}
+
+		if !leader.Load() {
+			continue
+		}
+
+		mutex.Lock()
+		loadPaused()
+		mutex.Unlock()
+
+		if e.paused.Load() {
+			continue
+		}
+
+		if ok, err := e.claim(next); !ok {
+			if err != nil {
+				logger.Error("抢占任务调度时间点失败", zap.String("name", e.job.Name), zap.Error(err))
+			}
+			continue
+		}
+
+		if err := e.start(TriggerCron); err != nil && !errors.Is(err, cache.ErrLocked) {
+			logger.Info("任务未执行", zap.String("name", e.job.Name), zap.Error(err))
+		}
+	}
+}
+
+// claim 抢占本次调度的时间点，保留到下一个时间点；
+// 任务锁在执行结束后释放，只靠任务锁时执行较快的任务会被时钟稍慢的实例在同一时间点再执行一次
+func (e *entry) claim(slot time.Time) (bool, error) {
+	key := fmt.Sprintf("%s%s:%d", common.JobSlotKey, e.job.Name, slot.Unix())
+	return configs.CACHE.SetNX(key, instance, e.schedule.Next(slot).Sub(slot))
+}
+
+// start 抢占任务锁并在后台执行任务；
+// 本实例的上一次执行未结束时记录为跳过并返回 ErrRunning，其他实例正在执行时手动触发返回 ErrRunning，定时触发返回 cache.ErrLocked
+func (e *entry) start(trigger string) error {
+	if !e.running.CompareAndSwap(false, true) {
+		now := time.Now().UnixMilli()
+		e.record(&models.JobRun{Name: e.job.Name, Trigger: trigger, Instance: instance, Status: StatusSkipped, StartAt: now, EndAt: now})
+		return ErrRunning
+	}
+
+	lease, err := cache.Acquire(configs.CACHE, e.job.Name, common.JobLockExpire)
+	if err != nil {
+		e.running.Store(false)
+		if errors.Is(err, cache.ErrLocked) && trigger == TriggerManual {
+			return ErrRunning
+		}
+		return err
+	}
 
-			<-ticker.C
+	background.Go(func() { e.run(trigger, lease) })
+	return nil
+}
+
+// run 持有任务锁执行任务并记录结果
+func (e *entry) run(trigger string, lease *cache.Lease) {
+	defer e.running.Store(false)
+
+	ctx, stop := hold(lease)
+	defer stop()
+
+	start := time.Now()
+	e.prev.Store(start.UnixMilli())
+
+	run := &models.JobRun{Name: e.job.Name, Trigger: trigger, Instance: instance, Fence: lease.Token(), Status: StatusRunning, StartAt: start.UnixMilli()}
+	if store != nil {
+		if err := store.CreateRun(run); err != nil {
+			logger.Error("保存任务执行记录失败", zap.String("name", e.job.Name), zap.Error(err))
+		}
+	}
+
+	logger.Info("执行任务", zap.String("name", e.job.Name), zap.String("trigger", trigger), zap.Int64("token", lease.Token()))
+
+	defer func() {
+		if r := recover(); r != nil {
+			run.Status = StatusPanic
+			run.Error = fmt.Sprintf("%v\n%s", r, debug.Stack())
+			logger.Error("任务发生panic", zap.String("name", e.job.Name), zap.Any("panic", r))
+		}
+
+		end := time.Now()
+		run.EndAt = end.UnixMilli()
+		run.Duration = end.Sub(start).Milliseconds()
+
+		metrics.JobRuns.WithLabelValues(e.job.Name, run.Status).Inc()
+		metrics.JobDuration.WithLabelValues(e.job.Name).Observe(end.Sub(start).Seconds())
+
+		if store != nil && run.ID != 0 {
+			if err := store.FinishRun(run); err != nil {
+				logger.Error("更新任务执行记录失败", zap.String("name", e.job.Name), zap.Error(err))
+			}
 		}
 	}()
+
+	if err := e.job.Job(ctx); err != nil {
+		run.Status = StatusFailed
+		run.Error = err.Error()
+		logger.Error("任务执行失败", zap.String("name", e.job.Name), zap.Error(err))
+		return
+	}
+
+	run.Status = StatusSuccess
+}
+
+// record 保存一条已结束的执行记录
+func (e *entry) record(run *models.JobRun) {
+	metrics.JobRuns.WithLabelValues(run.Name, run.Status).Inc()
+	if store == nil {
+		return
+	}
+	if err := store.CreateRun(run); err != nil {
+		logger.Error("保存任务执行记录失败", zap.String("name", e.job.Name), zap.Error(err))
+	}
 }
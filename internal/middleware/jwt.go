@@ -5,6 +5,8 @@ import (
 	"blog/internal/models"
 	"blog/internal/utils"
 	"blog/pkg/common"
+	"blog/pkg/configs"
+	"slices"
 	"strings"
 
 	"github.com/gofiber/fiber/v3"
@@ -28,6 +30,11 @@ func ParseToken(header string, c fiber.Ctx) *models.User {
 	// 去除tokenType前缀，获取实际token
 	token := strings.TrimPrefix(header, tokenType)
 
+	// 个人访问令牌
+	if strings.HasPrefix(token, common.AccessTokenPrefix) {
+		return parseAccessToken(token, c)
+	}
+
 	// 解析token获取用户ID
 	uid := utils.ParseTokenUserId(token)
 
@@ -62,6 +69,47 @@ func ParseToken(header string, c fiber.Ctx) *models.User {
 	return user
 }
 
+// parseAccessToken 校验个人访问令牌及其权限范围
+func parseAccessToken(token string, c fiber.Ctx) *models.User {
+	user, scopes := common.GetAccessToken(token, utils.GetIPAddress(c))
+
+	if user == nil {
+		handler.ResultErrorToResponse(common.TokenExpireError, c, "访问令牌无效或已过期")
+		return nil
+	}
+
+	path := routePath(c)
+	group, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if slices.Contains(common.AccessTokenDeniedGroups, group) || slices.Contains(common.AccessTokenDeniedRoutes, path) {
+		handler.ResultErrorToResponse(common.Forbidden, c, "该接口不支持使用访问令牌")
+		return nil
+	}
+
+	scope := requiredScope(group, c)
+
+	if !slices.Contains(scopes, common.AccessTokenAllScope) && !slices.Contains(scopes, scope) {
+		handler.ResultErrorToResponse(common.Forbidden, c, "访问令牌缺少权限: "+scope)
+		return nil
+	}
+
+	c.Locals("pat", true)
+
+	return user
+}
+
+// routePath 当前路由去掉全局前缀后的路径，如 /blog/admin/list，分组为第一段 blog
+func routePath(c fiber.Ctx) string {
+	return strings.TrimPrefix(c.Route().Path, configs.CONFIG.Server.ApiPrefix)
+}
+
+// requiredScope 根据路由分组和请求方法计算所需的权限范围，如 blog:write
+func requiredScope(group string, c fiber.Ctx) string {
+	if c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead {
+		return group + ":read"
+	}
+	return group + ":write"
+}
+
 // JwtMiddle 验证身份中间件
 func JwtMiddle(roleId common.RoleId) fiber.Handler {
 
@@ -89,6 +137,17 @@ func JwtMiddle(roleId common.RoleId) fiber.Handler {
 	}
 }
 
+// VerifiedMiddle 开启邮箱验证时，要求用户已验证邮箱，需放在 JwtMiddle 之后
+func VerifiedMiddle(c fiber.Ctx) error {
+	user, ok := c.Locals("user").(*models.User)
+
+	if configs.CONFIG.Mail.Verify && (!ok || !user.Verified) {
+		return handler.ResultErrorToResponse(common.EmailUnverified, c, "请先验证邮箱后再进行此操作")
+	}
+
+	return c.Next()
+}
+
 // 权限检查函数
 func isAuthorized(roleId common.RoleId, role uint) bool {
 	if role == uint(common.SuperAdminRoleId) {
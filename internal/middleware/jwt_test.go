@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"blog/internal/models"
+	"blog/pkg/common"
+	"blog/pkg/configs"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestAccessTokenScope(t *testing.T) {
+	configs.CONFIG = &configs.GlobalConfig{Server: configs.ServerConfig{ApiPrefix: "/api"}}
+
+	scopes := map[string][]string{
+		common.AccessTokenPrefix + "read":  {"blog:read"},
+		common.AccessTokenPrefix + "write": {"blog:write"},
+		common.AccessTokenPrefix + "all":   {common.AccessTokenAllScope},
+	}
+	getAccessToken := common.GetAccessToken
+	defer func() { common.GetAccessToken = getAccessToken }()
+	common.GetAccessToken = func(token string, ip string) (*models.User, []string) {
+		scope, ok := scopes[token]
+		if !ok {
+			return nil, nil
+		}
+		user := &models.User{ID: 1}
+		user.Role.ID = uint(common.SuperAdminRoleId)
+		return user, scope
+	}
+
+	app := fiber.New()
+	ok := func(c fiber.Ctx) error { return c.SendString("ok") }
+	api := app.Group("/api")
+	api.Get("/blog/admin/list", ok, JwtMiddle(common.SuperAdminRoleId))
+	api.Put("/blog/admin/update", ok, JwtMiddle(common.SuperAdminRoleId))
+	api.Put("/user/auth/reset", ok, JwtMiddle(common.UserRoleId))
+	api.Put("/user/admin/update", ok, JwtMiddle(common.SuperAdminRoleId))
+	api.Get("/token/list", ok, JwtMiddle(common.UserRoleId))
+
+	for _, tc := range []struct {
+		method, path, token string
+		allowed             bool
+	}{
+		{fiber.MethodGet, "/api/blog/admin/list", "read", true},
+		{fiber.MethodPut, "/api/blog/admin/update", "read", false},
+		{fiber.MethodPut, "/api/blog/admin/update", "write", true},
+		{fiber.MethodGet, "/api/blog/admin/list", "write", false},
+		{fiber.MethodGet, "/api/blog/admin/list", "all", true},
+		{fiber.MethodGet, "/api/blog/admin/list", "unknown", false},
+		// 令牌管理、修改密码和修改用户信息即使拥有全部权限也不能使用访问令牌
+		{fiber.MethodGet, "/api/token/list", "all", false},
+		{fiber.MethodPut, "/api/user/auth/reset", "all", false},
+		{fiber.MethodPut, "/api/user/admin/update", "all", false},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		req.Header.Set(tokenHeader, tokenType+common.AccessTokenPrefix+tc.token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+
+		if allowed := string(body) == "ok"; allowed != tc.allowed {
+			var r common.R
+			json.Unmarshal(body, &r)
+			t.Errorf("%s %s 使用 %s 令牌: allowed = %v, 期望 %v (%s)", tc.method, tc.path, tc.token, allowed, tc.allowed, r.Message)
+		}
+	}
+}
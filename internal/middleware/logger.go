@@ -40,35 +40,19 @@ func LoggerMiddleware(c fiber.Ctx) error {
 }
 func SystemLogMiddleware(module string, action string, message string, body bool) fiber.Handler {
 	return func(ctx fiber.Ctx) error {
-		ip, city := utils.GetIpAndCitp(ctx)
-		user := ctx.Locals("user")
-
-		logInfo := &models.SystemLogInfo{
-			Module:     module,
-			Action:     action,
-			IP:         ip,
-			Location:   city,
-			RequestURL: ctx.OriginalURL(),
-			Method:     ctx.Method(),
-			Message:    message,
-			UserAgent:  utils.GetClientPlatformInfo(ctx.Get("User-Agent")),
+		if user := ctx.Locals("user"); user != nil {
+			if _, ok := user.(*models.User); !ok {
+				// Log or handle the case where user is not of type *models.User
+				logger.Warn("User is not of type *models.User")
+			}
 		}
 
+		logInfo := utils.NewSystemLog(ctx, module, action, message)
+
 		if body {
 			logInfo.Params = string(ctx.Body())
 		}
 
-		if user != nil {
-			if userInfo, ok := user.(*models.User); ok {
-				logInfo.OperatorID = userInfo.ID
-				logInfo.OperatorName = userInfo.NickName
-				logInfo.Email = userInfo.Email
-			} else {
-				// Log or handle the case where user is not of type *models.User
-				logger.Warn("User is not of type *models.User")
-			}
-		}
-
 		if err := configs.DB.Model(&models.SystemLogInfo{}).Create(logInfo).Error; err != nil {
 			// Log the error
 			logger.Error("Failed to create system log", zap.Error(err))
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"blog/internal/handler"
+	"blog/pkg/common"
+	"blog/pkg/configs"
+	"blog/pkg/metrics"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// MetricsMiddleware 记录请求数量和耗时，路由使用注册时的模板避免标签过多
+func MetricsMiddleware(c fiber.Ctx) error {
+	start := time.Now()
+
+	err := c.Next()
+
+	status := c.Response().StatusCode()
+	if err != nil {
+		status = fiber.StatusInternalServerError
+		var e *fiber.Error
+		if errors.As(err, &e) {
+			status = e.Code
+		}
+	}
+
+	route := c.Route().Path
+	if status == fiber.StatusNotFound {
+		route = "unmatched"
+	}
+
+	labels := []string{c.Method(), route, strconv.Itoa(status)}
+	metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+	metrics.HTTPDuration.WithLabelValues(labels...).Observe(metrics.Since(start))
+
+	return err
+}
+
+// MetricsGuard 指标接口访问控制，令牌或IP任一匹配即可访问
+func MetricsGuard(config configs.MetricsConfig) fiber.Handler {
+	allowIps := config.AllowIps
+	if config.Token == "" && len(allowIps) == 0 {
+		allowIps = []string{"127.0.0.1", "::1"}
+	}
+
+	var networks []*net.IPNet
+	for _, item := range allowIps {
+		if !strings.Contains(item, "/") {
+			if strings.Contains(item, ":") {
+				item += "/128"
+			} else {
+				item += "/32"
+			}
+		}
+		if _, network, err := net.ParseCIDR(item); err == nil {
+			networks = append(networks, network)
+		}
+	}
+
+	return func(c fiber.Ctx) error {
+		if config.Token != "" {
+			token := strings.TrimPrefix(c.Get(tokenHeader), tokenType)
+			if token == "" {
+				token = c.Query("token")
+			}
+			if subtle.ConstantTimeCompare([]byte(token), []byte(config.Token)) == 1 {
+				return c.Next()
+			}
+		}
+
+		if ip := net.ParseIP(c.IP()); ip != nil {
+			for _, network := range networks {
+				if network.Contains(ip) {
+					return c.Next()
+				}
+			}
+		}
+
+		handler.ResultErrorToResponse(common.Forbidden, c, "无权访问指标接口")
+		return nil
+	}
+}
@@ -0,0 +1,303 @@
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Table 记录已执行迁移的表
+const Table = "schema_migrations"
+
+// lockKey 执行迁移时使用的 PostgreSQL 事务级咨询锁，避免多个实例同时迁移
+const lockKey = 7_326_189_041
+
+var (
+	ErrOutdated     = errors.New("数据库结构不是最新版本")
+	ErrUnknown      = errors.New("数据库中有程序不认识的迁移版本")
+	ErrIrreversible = errors.New("迁移不支持回滚")
+)
+
+// Migration 一个版本的数据库迁移，Up 和 Down 在同一个事务中与版本记录一起提交
+type Migration struct {
+	Version int64  // 版本号，使用创建时间如 20240101000000，按从小到大执行
+	Name    string // 名称，用于显示
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error // 为空时不支持回滚
+}
+
+// Record 已执行的迁移
+type Record struct {
+	Version   int64  `gorm:"primaryKey;autoIncrement:false;comment:迁移版本"`
+	Name      string `gorm:"size:255;comment:迁移名称"`
+	AppliedAt int64  `gorm:"comment:执行时间"`
+}
+
+func (*Record) TableName() string {
+	return Table
+}
+
+// Status 迁移的执行状态
+type Status struct {
+	Version   int64  `json:"version"`
+	Name      string `json:"name"`
+	Applied   bool   `json:"applied"`   // 是否已执行
+	AppliedAt int64  `json:"appliedAt"` // 执行时间，未执行时为0
+	Unknown   bool   `json:"unknown"`   // 数据库中有记录但程序中没有，通常是用更新版本的程序执行过迁移
+}
+
+// SQL 用 SQL 语句定义 Up 和 Down，down 为空时不支持回滚
+func SQL(up, down string) (func(tx *gorm.DB) error, func(tx *gorm.DB) error) {
+	upFn := func(tx *gorm.DB) error { return tx.Exec(up).Error }
+	if strings.TrimSpace(down) == "" {
+		return upFn, nil
+	}
+	return upFn, func(tx *gorm.DB) error { return tx.Exec(down).Error }
+}
+
+// Load 读取目录中的 SQL 迁移，文件名为 <版本>_<名称>.up.sql 和 <版本>_<名称>.down.sql
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	type files struct{ name, up, down string }
+	byVersion := make(map[int64]*files)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || path.Ext(name) != ".sql" {
+			continue
+		}
+		base, direction := strings.TrimSuffix(name, ".sql"), ""
+		switch {
+		case strings.HasSuffix(base, ".up"):
+			base, direction = strings.TrimSuffix(base, ".up"), "up"
+		case strings.HasSuffix(base, ".down"):
+			base, direction = strings.TrimSuffix(base, ".down"), "down"
+		default:
+			return nil, fmt.Errorf("迁移文件名应以 .up.sql 或 .down.sql 结尾: %s", name)
+		}
+		versionText, title, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(versionText, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("迁移文件名应以版本号开头: %s", name)
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := byVersion[version]
+		if !ok {
+			f = &files{name: title}
+			byVersion[version] = f
+		} else if f.name != title {
+			return nil, fmt.Errorf("版本 %d 的迁移文件名称不一致: %s、%s", version, f.name, title)
+		}
+		if direction == "up" {
+			f.up = string(data)
+		} else {
+			f.down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for version, f := range byVersion {
+		if strings.TrimSpace(f.up) == "" {
+			return nil, fmt.Errorf("版本 %d 缺少 up 迁移", version)
+		}
+		up, down := SQL(f.up, f.down)
+		migrations = append(migrations, Migration{Version: version, Name: f.name, Up: up, Down: down})
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+	return migrations, nil
+}
+
+// Migrator 按版本执行迁移并记录到迁移表
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New 创建迁移器，迁移按版本排序，版本不能重复
+func New(db *gorm.DB, migrations []Migration) (*Migrator, error) {
+	sorted := slices.Clone(migrations)
+	slices.SortFunc(sorted, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+	for i, m := range sorted {
+		if m.Up == nil {
+			return nil, fmt.Errorf("迁移 %d 缺少 Up", m.Version)
+		}
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, fmt.Errorf("迁移版本重复: %d", m.Version)
+		}
+	}
+	return &Migrator{db: db, migrations: sorted}, nil
+}
+
+// Status 返回所有迁移的执行状态，包括数据库中有记录但程序中没有的版本
+func (m *Migrator) Status() ([]Status, error) {
+	records, err := m.records()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if record, ok := records[migration.Version]; ok {
+			status.Applied, status.AppliedAt = true, record.AppliedAt
+			delete(records, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range records {
+		statuses = append(statuses, Status{Version: record.Version, Name: record.Name, Applied: true, AppliedAt: record.AppliedAt, Unknown: true})
+	}
+	slices.SortFunc(statuses, func(a, b Status) int { return cmp.Compare(a.Version, b.Version) })
+	return statuses, nil
+}
+
+// Check 检查数据库是否已执行所有迁移，有未执行的迁移时返回 ErrOutdated，有未知版本时返回 ErrUnknown
+func (m *Migrator) Check() error {
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+	var pending, unknown int
+	for _, status := range statuses {
+		switch {
+		case status.Unknown:
+			unknown++
+		case !status.Applied:
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w，有 %d 个迁移未执行", ErrOutdated, pending)
+	}
+	if unknown > 0 {
+		return fmt.Errorf("%w，共 %d 个", ErrUnknown, unknown)
+	}
+	return nil
+}
+
+// Up 按顺序执行所有未执行的迁移，每个迁移一个事务，失败时停止；applied 在每个迁移完成后调用
+func (m *Migrator) Up(ctx context.Context, applied func(Migration, time.Duration)) error {
+	if err := m.ensureTable(); err != nil {
+		return err
+	}
+	for _, migration := range m.migrations {
+		start := time.Now()
+		done, err := m.run(ctx, migration, true)
+		if err != nil {
+			return fmt.Errorf("执行迁移 %d_%s 失败: %w", migration.Version, migration.Name, err)
+		}
+		if done && applied != nil {
+			applied(migration, time.Since(start))
+		}
+	}
+	return nil
+}
+
+// Down 按版本从大到小回滚最近执行的 steps 个迁移
+func (m *Migrator) Down(ctx context.Context, steps int, reverted func(Migration, time.Duration)) error {
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+	for i := len(statuses) - 1; i >= 0 && steps > 0; i-- {
+		status := statuses[i]
+		if !status.Applied {
+			continue
+		}
+		if status.Unknown {
+			return fmt.Errorf("%w: %d，请使用对应版本的程序回滚", ErrUnknown, status.Version)
+		}
+
+		migration := m.find(status.Version)
+		if migration.Down == nil {
+			return fmt.Errorf("%d_%s: %w", migration.Version, migration.Name, ErrIrreversible)
+		}
+		start := time.Now()
+		if _, err := m.run(ctx, migration, false); err != nil {
+			return fmt.Errorf("回滚迁移 %d_%s 失败: %w", migration.Version, migration.Name, err)
+		}
+		if reverted != nil {
+			reverted(migration, time.Since(start))
+		}
+		steps--
+	}
+	return nil
+}
+
+// run 在事务中加锁后执行一个迁移并更新记录，其他实例已执行时跳过，返回是否执行
+func (m *Migrator) run(ctx context.Context, migration Migration, up bool) (bool, error) {
+	done := false
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockKey).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&Record{}).Where("version = ?", migration.Version).Count(&count).Error; err != nil {
+			return err
+		}
+		if up == (count > 0) {
+			return nil
+		}
+
+		if up {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			done = true
+			return tx.Create(&Record{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now().Unix()}).Error
+		}
+		if err := migration.Down(tx); err != nil {
+			return err
+		}
+		done = true
+		return tx.Where("version = ?", migration.Version).Delete(&Record{}).Error
+	})
+	return done, err
+}
+
+// records 读取已执行的迁移，迁移表不存在时视为都未执行
+func (m *Migrator) records() (map[int64]Record, error) {
+	result := make(map[int64]Record)
+	if !m.db.Migrator().HasTable(Table) {
+		return result, nil
+	}
+	var records []Record
+	if err := m.db.Order("version").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		result[record.Version] = record
+	}
+	return result, nil
+}
+
+// ensureTable 创建迁移表
+func (m *Migrator) ensureTable() error {
+	return m.db.AutoMigrate(&Record{})
+}
+
+func (m *Migrator) find(version int64) Migration {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration
+		}
+	}
+	return Migration{}
+}
@@ -0,0 +1,168 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"20240102000000_add_index.up.sql":   {Data: []byte("CREATE INDEX idx ON blogs (title)")},
+		"20240101000000_init.up.sql":        {Data: []byte("CREATE TABLE t (id int)")},
+		"20240101000000_init.down.sql":      {Data: []byte("DROP TABLE t")},
+		"20240102000000_add_index.down.sql": {Data: []byte("  ")},
+		"README.md":                         {Data: []byte("说明")},
+		"nested/20240103000000_skip.up.sql": {Data: []byte("SELECT 1")},
+	}
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("迁移数量 = %d, 期望 2", len(migrations))
+	}
+	if migrations[0].Version != 20240101000000 || migrations[0].Name != "init" || migrations[0].Down == nil {
+		t.Errorf("第一个迁移 = %+v", migrations[0])
+	}
+	if migrations[1].Version != 20240102000000 || migrations[1].Name != "add_index" || migrations[1].Down != nil {
+		t.Errorf("第二个迁移 = %+v，空的 down 应视为不支持回滚", migrations[1])
+	}
+
+	invalid := map[string]fstest.MapFS{
+		"名称不一致": {
+			"20240101000000_init.up.sql":    {Data: []byte("SELECT 1")},
+			"20240101000000_other.down.sql": {Data: []byte("SELECT 1")},
+		},
+		"缺少 up": {
+			"20240101000000_init.down.sql": {Data: []byte("SELECT 1")},
+		},
+		"没有方向": {
+			"20240101000000_init.sql": {Data: []byte("SELECT 1")},
+		},
+		"没有版本号": {
+			"init.up.sql": {Data: []byte("SELECT 1")},
+		},
+	}
+	for name, fsys := range invalid {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: 期望返回错误", name)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	up := func(tx *gorm.DB) error { return nil }
+
+	m, err := New(nil, []Migration{{Version: 2, Up: up}, {Version: 1, Up: up}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.migrations[0].Version != 1 || m.migrations[1].Version != 2 {
+		t.Errorf("迁移未按版本排序: %+v", m.migrations)
+	}
+
+	if _, err := New(nil, []Migration{{Version: 1, Up: up}, {Version: 1, Up: up}}); err == nil {
+		t.Error("版本重复时期望返回错误")
+	}
+	if _, err := New(nil, []Migration{{Version: 1}}); err == nil {
+		t.Error("缺少 Up 时期望返回错误")
+	}
+	if _, err := New(nil, Migrations); err != nil {
+		t.Errorf("程序中的迁移无效: %v", err)
+	}
+}
+
+// testDB 连接 BLOG_TEST_DSN 指定的 PostgreSQL 测试库，未设置时跳过；在独立的 schema 中执行，结束后删除
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("BLOG_TEST_DSN")
+	if dsn == "" {
+		t.Skip("未设置 BLOG_TEST_DSN")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // search_path 只对当前连接生效
+
+	for _, sql := range []string{"DROP SCHEMA IF EXISTS migrate_test CASCADE", "CREATE SCHEMA migrate_test", "SET search_path TO migrate_test"} {
+		if err := db.Exec(sql).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		db.Exec("DROP SCHEMA IF EXISTS migrate_test CASCADE")
+		sqlDB.Close()
+	})
+	return db
+}
+
+func TestMigrator(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	createA, dropA := SQL("CREATE TABLE a (id int)", "DROP TABLE a")
+	addColumn, _ := SQL("ALTER TABLE a ADD COLUMN name text", "")
+	createB, dropB := SQL("CREATE TABLE b (id int)", "DROP TABLE b")
+	migrations := []Migration{
+		{Version: 1, Name: "create_a", Up: createA, Down: dropA},
+		{Version: 2, Name: "add_name", Up: addColumn},
+		{Version: 3, Name: "create_b", Up: createB, Down: dropB},
+	}
+	m, err := New(db, migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Check(); !errors.Is(err, ErrOutdated) {
+		t.Fatalf("未迁移时 Check = %v", err)
+	}
+
+	var applied []int64
+	record := func(migration Migration, _ time.Duration) { applied = append(applied, migration.Version) }
+	if err := m.Up(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 3 {
+		t.Fatalf("执行的迁移 = %v，重复执行 Up 不应再次迁移", applied)
+	}
+	if err := m.Check(); err != nil {
+		t.Fatalf("迁移后 Check = %v", err)
+	}
+	if !db.Migrator().HasColumn("a", "name") || !db.Migrator().HasTable("b") {
+		t.Fatal("迁移未生效")
+	}
+
+	if err := m.Down(ctx, 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if db.Migrator().HasTable("b") {
+		t.Fatal("回滚后表 b 应被删除")
+	}
+	if err := m.Check(); !errors.Is(err, ErrOutdated) {
+		t.Fatalf("回滚后 Check = %v", err)
+	}
+	if err := m.Down(ctx, 1, nil); !errors.Is(err, ErrIrreversible) {
+		t.Fatalf("回滚不支持回滚的迁移 err = %v", err)
+	}
+
+	// 旧版本的程序不认识数据库中更新的迁移
+	old, _ := New(db, migrations[:1])
+	if err := old.Check(); !errors.Is(err, ErrUnknown) {
+		t.Fatalf("有未知版本时 Check = %v", err)
+	}
+	if err := old.Down(ctx, 1, nil); !errors.Is(err, ErrUnknown) {
+		t.Fatalf("回滚未知版本 err = %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package migrate
+
+import (
+	"blog/pkg/common"
+
+	"gorm.io/gorm"
+)
+
+// Migrations 程序的所有迁移，新增迁移追加到末尾，已发布的迁移不要修改
+var Migrations = []Migration{
+	{
+		Version: 20261019000000,
+		Name:    "create_tables",
+		Up:      createTables, // 已有数据库升级时会和之前的表合并，回滚会删除全部数据，因此不支持回滚
+	},
+	{
+		Version: 20261019000100,
+		Name:    "seed_roles",
+		Up:      seedRoles,
+		Down:    deleteRoles,
+	},
+	{
+		Version: 20261019000200,
+		Name:    "create_job_states",
+		Up:      func(tx *gorm.DB) error { return tx.Migrator().CreateTable(&jobState{}) },
+		Down:    func(tx *gorm.DB) error { return tx.Migrator().DropTable(&jobState{}) },
+	},
+	{
+		Version: 20261019000300,
+		Name:    "add_job_fence",
+		Up:      func(tx *gorm.DB) error { return tx.Migrator().AddColumn(&jobFence{}, "Fence") },
+		Down:    func(tx *gorm.DB) error { return tx.Migrator().DropColumn(&jobFence{}, "Fence") },
+	},
+}
+
+// v1Tables 首个版本的数据表，之前由启动时的 AutoMigrate 创建，已有数据库执行时只补齐缺少的表和列
+func v1Tables() []any {
+	return []any{
+		&role{},
+		&user{},
+		&category{},
+		&tag{},
+		&topic{},
+		&fileMd5Info{},
+		&fileInfo{},
+		&blog{},
+		&eyeView{},
+		&systemLogInfo{},
+		&editBlog{},
+		&accessToken{},
+		&userIdentity{},
+		&jobRun{},
+		&blogDailyView{},
+		&viewRollup{},
+	}
+}
+
+func createTables(tx *gorm.DB) error {
+	// 新增邮箱验证字段前注册的用户视为已验证
+	backfillVerified := tx.Migrator().HasTable(&user{}) && !tx.Migrator().HasColumn(&user{}, "verified")
+
+	if err := tx.AutoMigrate(v1Tables()...); err != nil {
+		return err
+	}
+	if backfillVerified {
+		return tx.Model(&user{}).Where("1 = 1").Update("verified", true).Error
+	}
+	return nil
+}
+
+// defaultRoles 内置角色，ID 与 common 中的角色ID对应
+var defaultRoles = []role{
+	{ID: uint(common.UserRoleId), Name: "USER", Description: "普通用户"},
+	{ID: uint(common.AdminRoleId), Name: "ADMIN", Description: "管理员"},
+	{ID: uint(common.SuperAdminRoleId), Name: "SUPER_ADMIN", Description: "超级管理员"},
+}
+
+func seedRoles(tx *gorm.DB) error {
+	roles := append([]role(nil), defaultRoles...)
+	return tx.Save(&roles).Error
+}
+
+func deleteRoles(tx *gorm.DB) error {
+	ids := make([]uint, len(defaultRoles))
+	for i, role := range defaultRoles {
+		ids[i] = role.ID
+	}
+	return tx.Unscoped().Where("id IN ?", ids).Delete(&role{}).Error
+}
+
+// jobState 定时任务的暂停状态，之前只保存在缓存中
+type jobState struct {
+	Name      string `gorm:"primaryKey;size:50;comment:任务名称"`
+	Paused    bool   `gorm:"not null;default:false;comment:是否暂停"`
+	UpdatedAt int64  `gorm:"autoUpdateTime;comment:更新时间"`
+}
+
+func (*jobState) TableName() string { return "job_states" }
+
+// jobFence 任务写入数据库时比较的任务锁令牌
+type jobFence struct {
+	Name  string `gorm:"primaryKey;size:50"`
+	Fence int64  `gorm:"not null;default:0;comment:最近一次写入的任务锁令牌"`
+}
+
+func (*jobFence) TableName() string { return "job_states" }
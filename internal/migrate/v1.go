@@ -0,0 +1,217 @@
+package migrate
+
+import "gorm.io/gorm"
+
+// 首个版本数据表的快照，复制自当时的 models，只保留建表需要的字段和 gorm 标签；
+// 之后修改 models 不会影响已发布的迁移，表结构的变更通过新的迁移完成。
+// 类型名与 models 中的同名（首字母小写），gorm 按类型名生成的中间表字段和外键约束名称才会与之前一致
+
+// model 公共的时间字段，gorm 不解析未导出的嵌入字段，各表以 Model 字段加 embedded 标签嵌入
+type model struct {
+	CreatedAt int64          `gorm:"autoCreateTime;comment:创建时间"`
+	UpdatedAt int64          `gorm:"autoUpdateTime;comment:更新时间"`
+	DeletedAt gorm.DeletedAt `gorm:"index;comment:删除时间"`
+}
+
+type role struct {
+	Model       model  `gorm:"embedded"`
+	ID          uint   `gorm:"primary_key;type:int;comment:角色ID"`
+	Name        string `gorm:"size:255;unique;not null;comment:角色名"`
+	Description string `gorm:"size:255;not null;comment:角色描述"`
+}
+
+func (*role) TableName() string { return "roles" }
+
+type user struct {
+	Model     model  `gorm:"embedded"`
+	ID        int    `gorm:"primary_key;type:int;comment:用户ID"`
+	Username  string `gorm:"size:16;unique;not null;comment:用户账号"`
+	Password  string `gorm:"size:255;not null;comment:用户密码"`
+	Email     string `gorm:"size:255;unique;not null;comment:用户邮箱"`
+	Avatar    string `gorm:"default:'test.png';comment:用户头像"`
+	Status    bool   `gorm:"size:1;default:1;comment:账号状态"`
+	NickName  string `gorm:"size:50;not null;comment:用户名称"`
+	RoleID    uint   `gorm:"column:role_id;type:integer;comment:角色ID"`
+	LoginIP   string `gorm:"size:45;comment:登录IP"`
+	LoginCity string `gorm:"size:50;comment:登录地点"`
+	RegIp     string `gorm:"size:45;comment:注册IP"`
+	RegCity   string `gorm:"size:50;comment:注册地点"`
+	RegTime   int64  `gorm:"comment:注册时间"`
+	LastLogin int64  `gorm:"comment:最后登录时间"`
+	Verified  bool   `gorm:"default:false;comment:邮箱是否已验证"`
+	Role      role   `gorm:"foreignKey:RoleID"`
+}
+
+func (*user) TableName() string { return "users" }
+
+type category struct {
+	Model model  `gorm:"embedded"`
+	ID    int    `gorm:"primary_key;type:int;comment:分类ID"`
+	Name  string `gorm:"size:255;unique;not null;comment:分类名称"`
+}
+
+func (*category) TableName() string { return "categories" }
+
+type tag struct {
+	Model model  `gorm:"embedded"`
+	ID    int    `gorm:"primary_key;type:int;comment:标签ID;"`
+	Name  string `gorm:"size:255;unique;not null;comment:标签名称"`
+}
+
+func (*tag) TableName() string { return "tags" }
+
+type topic struct {
+	Model       model  `gorm:"embedded"`
+	ID          int    `gorm:"primary_key;type:int;comment:专题ID"`
+	Name        string `gorm:"size:255;unique;not null;comment:专题名"`
+	Description string `gorm:"size:255;not null;comment:专题描述"`
+	CoverImage  string `gorm:"size:255;not null;comment:专题封面"`
+	UserID      int    `gorm:"column:user_id;type:integer;comment:创建专题的用户"`
+	User        user   `gorm:"foreignKey:UserID"`
+}
+
+func (*topic) TableName() string { return "topics" }
+
+type fileMd5Info struct {
+	Md5          string `gorm:"size:255;primary_key;not null;comment:文件 MD5"`
+	Url          string `gorm:"unique;comment:文件 URL"`
+	AbsolutePath string `gorm:"comment:文件绝对路径"`
+}
+
+func (*fileMd5Info) TableName() string { return "file_md5_infos" }
+
+type fileInfo struct {
+	Model       model       `gorm:"embedded"`
+	ID          int         `gorm:"primary_key;type:int;comment:文件ID"`
+	OldName     string      `gorm:"size:255;not null;comment:原文件名"`
+	NewName     string      `gorm:"size:255;not null;comment:新文件名"`
+	UserID      *int        `gorm:"column:user_id;type:int;comment:上传文件用户ID"`
+	Suffix      string      `gorm:"size:10;comment:后缀"`
+	Size        int64       `gorm:"comment:文件大小"`
+	FileMd5     string      `gorm:"size:255;column:md5;not null;comment:文件 MD5 值"`
+	IsPub       bool        `gorm:"default:false;comment:是否公开"`
+	FileMd5Info fileMd5Info `gorm:"foreignKey:FileMd5;references:Md5"`
+	User        user        `gorm:"foreignKey:UserID"`
+}
+
+func (*fileInfo) TableName() string { return "file_infos" }
+
+type blog struct {
+	Model       model     `gorm:"embedded"`
+	ID          int64     `gorm:"primary_key;comment:博客ID"`
+	Description string    `gorm:"size:255;not null;comment:博客描述"`
+	Title       string    `gorm:"size:255;not null;comment:博客标题"`
+	CoverImage  string    `gorm:"not null;comment:博客封面"`
+	SourceURL   *string   `gorm:"default:null;comment:博客原文链接"`
+	Content     string    `gorm:"type:text;comment:博客正文"`
+	EyeCount    int64     `gorm:"default:0;comment:浏览量"`
+	CategoryID  *int      `gorm:"column:category_id;type:integer;comment:博客分类ID"`
+	UserID      int       `gorm:"column:user_id;type:integer;comment:创建的用户ID"`
+	TopicID     *int      `gorm:"column:topic_id;type:integer;comment:博客专题ID"`
+	Tags        []tag     `gorm:"many2many:blogs_tags"`
+	Category    *category `gorm:"foreignKey:CategoryID"`
+	User        user      `gorm:"foreignKey:UserID"`
+	Topic       *topic    `gorm:"foreignKey:TopicID"`
+	IsPrivate   bool      `gorm:"comment:是否为私有博客"`
+	Pinned      bool      `gorm:"comment:是否为置顶博客"`
+	Order       *int64    `gorm:"default:null;comment:置顶博客排序，只有开启置顶的时候才有用"`
+	Password    *string   `gorm:"size:255;comment:访问私有博客的密码"`
+}
+
+func (*blog) TableName() string { return "blogs" }
+
+type eyeView struct {
+	Model model  `gorm:"embedded"`
+	ID    string `gorm:"primary_key;type:varchar(10);"`
+	Count int64  `gorm:"type:int"`
+}
+
+func (*eyeView) TableName() string { return "eye_count" }
+
+type systemLogInfo struct {
+	Model        model  `gorm:"embedded"`
+	ID           int64  `gorm:"primaryKey"`
+	Module       string `gorm:"comment:模块名称"`
+	Action       string `gorm:"comment:操作类型"`
+	Message      string `gorm:"comment:消息"`
+	IP           string `gorm:"comment:操作IP"`
+	Location     string `gorm:"comment:操作地点"`
+	UserAgent    string `gorm:"comment:用户代理"`
+	RequestURL   string `gorm:"comment:请求URL"`
+	Method       string `gorm:"comment:请求方法"`
+	Params       string `gorm:"comment:请求参数"`
+	OperatorID   int    `gorm:"comment:操作人ID"`
+	OperatorName string `gorm:"comment:操作人名称"`
+	Email        string `gorm:"comment:操作人邮箱"`
+}
+
+func (*systemLogInfo) TableName() string { return "system_log_info" }
+
+type editBlog struct {
+	Model   model  `gorm:"embedded"`
+	UID     int    `gorm:"primaryKey;type:int;column:uid;comment:保存博客的用户ID"`
+	Content string `gorm:"type:text;comment:保存博客正文"`
+}
+
+func (*editBlog) TableName() string { return "edit_blog" }
+
+type accessToken struct {
+	Model      model  `gorm:"embedded"`
+	ID         int    `gorm:"primary_key;type:int;comment:令牌ID"`
+	UserID     int    `gorm:"index;not null;comment:所属用户ID"`
+	Name       string `gorm:"size:50;not null;comment:令牌名称"`
+	TokenHash  string `gorm:"size:64;unique;not null;comment:令牌SHA256摘要"`
+	Prefix     string `gorm:"size:20;comment:令牌前缀，用于辨认"`
+	Scopes     string `gorm:"size:255;comment:权限范围，逗号分隔"`
+	ExpiresAt  int64  `gorm:"comment:过期时间，0为永不过期"`
+	LastUsedAt int64  `gorm:"comment:最后使用时间"`
+	LastUsedIp string `gorm:"size:45;comment:最后使用IP"`
+}
+
+func (*accessToken) TableName() string { return "access_tokens" }
+
+type userIdentity struct {
+	Model    model  `gorm:"embedded"`
+	ID       int    `gorm:"primary_key;type:int;comment:绑定ID"`
+	UserID   int    `gorm:"index;not null;comment:用户ID"`
+	Provider string `gorm:"size:50;not null;uniqueIndex:idx_provider_subject;comment:第三方提供方"`
+	Subject  string `gorm:"size:255;not null;uniqueIndex:idx_provider_subject;comment:第三方账号ID"`
+	Email    string `gorm:"size:255;comment:第三方邮箱"`
+	Name     string `gorm:"size:255;comment:第三方账号名"`
+	Avatar   string `gorm:"size:500;comment:第三方头像"`
+}
+
+func (*userIdentity) TableName() string { return "user_identities" }
+
+type jobRun struct {
+	ID       int64  `gorm:"primaryKey;comment:记录ID"`
+	Name     string `gorm:"size:50;index;not null;comment:任务名称"`
+	Trigger  string `gorm:"size:10;comment:触发方式 cron/manual"`
+	Instance string `gorm:"size:100;comment:执行的实例"`
+	Fence    int64  `gorm:"comment:任务锁的令牌"`
+	Status   string `gorm:"size:10;index;comment:执行状态"`
+	Error    string `gorm:"type:text;comment:错误信息或panic堆栈"`
+	StartAt  int64  `gorm:"index;comment:开始时间(毫秒)"`
+	EndAt    int64  `gorm:"comment:结束时间(毫秒)"`
+	Duration int64  `gorm:"comment:耗时(毫秒)"`
+}
+
+func (*jobRun) TableName() string { return "job_runs" }
+
+type blogDailyView struct {
+	Date   string `gorm:"primaryKey;type:varchar(10);comment:日期"`
+	BlogID int64  `gorm:"primaryKey;autoIncrement:false;comment:博客ID，0为全站"`
+	PV     int64  `gorm:"comment:浏览量"`
+	UV     int64  `gorm:"comment:独立访客数"`
+}
+
+func (*blogDailyView) TableName() string { return "blog_daily_views" }
+
+type viewRollup struct {
+	Hour      int64  `gorm:"primaryKey;autoIncrement:false;comment:小时开始时间"`
+	Dimension string `gorm:"primaryKey;size:20;comment:统计维度"`
+	Value     string `gorm:"primaryKey;size:100;comment:维度取值"`
+	Count     int64  `gorm:"comment:浏览次数"`
+}
+
+func (*viewRollup) TableName() string { return "view_rollups" }
@@ -0,0 +1,27 @@
+package models
+
+import "blog/internal/dto/response"
+
+// UserIdentity 第三方账号绑定模型
+type UserIdentity struct {
+	Model
+	ID       int    `gorm:"primary_key;type:int;comment:绑定ID" json:"id"`
+	UserID   int    `gorm:"index;not null;comment:用户ID" json:"user_id"`
+	Provider string `gorm:"size:50;not null;uniqueIndex:idx_provider_subject;comment:第三方提供方" json:"provider"`
+	Subject  string `gorm:"size:255;not null;uniqueIndex:idx_provider_subject;comment:第三方账号ID" json:"subject"`
+	Email    string `gorm:"size:255;comment:第三方邮箱" json:"email"`
+	Name     string `gorm:"size:255;comment:第三方账号名" json:"name"`
+	Avatar   string `gorm:"size:500;comment:第三方头像" json:"avatar"`
+}
+
+func (*UserIdentity) TableName() string { return UserIdentityTable }
+
+func (i *UserIdentity) ToVo() response.UserIdentityResponse {
+	return response.UserIdentityResponse{
+		Provider:  i.Provider,
+		Email:     i.Email,
+		Name:      i.Name,
+		Avatar:    i.Avatar,
+		CreatedAt: i.CreatedAt,
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+// JobRun 定时任务执行记录
+type JobRun struct {
+	ID       int64  `gorm:"primaryKey;comment:记录ID" json:"id"`
+	Name     string `gorm:"size:50;index;not null;comment:任务名称" json:"name"`
+	Trigger  string `gorm:"size:10;comment:触发方式 cron/manual" json:"trigger"`
+	Instance string `gorm:"size:100;comment:执行的实例" json:"instance"`
+	Fence    int64  `gorm:"comment:任务锁的令牌" json:"fence"`
+	Status   string `gorm:"size:10;index;comment:执行状态" json:"status"`
+	Error    string `gorm:"type:text;comment:错误信息或panic堆栈" json:"error"`
+	StartAt  int64  `gorm:"index;comment:开始时间(毫秒)" json:"start_at"`
+	EndAt    int64  `gorm:"comment:结束时间(毫秒)" json:"end_at"`
+	Duration int64  `gorm:"comment:耗时(毫秒)" json:"duration"`
+}
+
+func (*JobRun) TableName() string { return JobRunTable }
+
+// JobState 定时任务的状态，多个实例共用
+type JobState struct {
+	Name      string `gorm:"primaryKey;size:50;comment:任务名称" json:"name"`
+	Paused    bool   `gorm:"not null;default:false;comment:是否暂停" json:"paused"`
+	Fence     int64  `gorm:"not null;default:0;comment:最近一次写入的任务锁令牌" json:"fence"`
+	UpdatedAt int64  `gorm:"autoUpdateTime;comment:更新时间" json:"updated_at"`
+}
+
+func (*JobState) TableName() string { return JobStateTable }
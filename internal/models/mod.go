@@ -12,16 +12,22 @@ type Model struct {
 
 // 表名称
 const (
-	UserTable        = "users"
-	RoleTable        = "roles"
-	TagTable         = "tags"
-	BlogTable        = "blogs"
-	CategoryTable    = "categories"
-	TopicTable       = "topics"
-	FileInfoTable    = "file_infos"
-	FileInfoMd5Table = "file_md5_infos"
-	BlogTagTable     = "blogs_tags"
-	EyeCountTable    = "eye_count"
-	SystemLogTable   = "system_log_info"
-	EditBlogTable    = "edit_blog"
+	UserTable          = "users"
+	RoleTable          = "roles"
+	TagTable           = "tags"
+	BlogTable          = "blogs"
+	CategoryTable      = "categories"
+	TopicTable         = "topics"
+	FileInfoTable      = "file_infos"
+	FileInfoMd5Table   = "file_md5_infos"
+	BlogTagTable       = "blogs_tags"
+	EyeCountTable      = "eye_count"
+	SystemLogTable     = "system_log_info"
+	EditBlogTable      = "edit_blog"
+	AccessTokenTable   = "access_tokens"
+	UserIdentityTable  = "user_identities"
+	JobRunTable        = "job_runs"
+	JobStateTable      = "job_states"
+	BlogDailyViewTable = "blog_daily_views"
+	ViewRollupTable    = "view_rollups"
 )
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"blog/internal/dto/response"
+	"strings"
+)
+
+// AccessToken 个人访问令牌模型
+type AccessToken struct {
+	Model
+	ID         int    `gorm:"primary_key;type:int;comment:令牌ID" json:"id"`
+	UserID     int    `gorm:"index;not null;comment:所属用户ID" json:"user_id"`
+	Name       string `gorm:"size:50;not null;comment:令牌名称" json:"name"`
+	TokenHash  string `gorm:"size:64;unique;not null;comment:令牌SHA256摘要" json:"-"`
+	Prefix     string `gorm:"size:20;comment:令牌前缀，用于辨认" json:"prefix"`
+	Scopes     string `gorm:"size:255;comment:权限范围，逗号分隔" json:"scopes"`
+	ExpiresAt  int64  `gorm:"comment:过期时间，0为永不过期" json:"expires_at"`
+	LastUsedAt int64  `gorm:"comment:最后使用时间" json:"last_used_at"`
+	LastUsedIp string `gorm:"size:45;comment:最后使用IP" json:"last_used_ip"`
+}
+
+func (*AccessToken) TableName() string { return AccessTokenTable }
+
+// ScopeList 返回权限范围列表
+func (t *AccessToken) ScopeList() []string {
+	if t.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(t.Scopes, ",")
+}
+
+func (t *AccessToken) ToVo() response.AccessTokenResponse {
+	return response.AccessTokenResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		Prefix:     t.Prefix,
+		Scopes:     t.ScopeList(),
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		LastUsedIp: t.LastUsedIp,
+		CreatedAt:  t.CreatedAt,
+	}
+}
@@ -21,6 +21,7 @@ type User struct {
 	RegCity   string `gorm:"size:50;comment:注册地点" json:"reg_city"`
 	RegTime   int64  `gorm:"comment:注册时间" json:"reg_time"`
 	LastLogin int64  `gorm:"comment:最后登录时间" json:"last_login"`
+	Verified  bool   `gorm:"default:false;comment:邮箱是否已验证" json:"verified"`
 	Role      Role   `json:"role"` // Role 字段不需要验证
 }
 
@@ -41,6 +42,7 @@ func (u *User) ToVo() response.UserResponse {
 		Username: u.Username,
 		Ip:       u.LoginIP,
 		City:     u.LoginCity,
+		Verified: u.Verified,
 	}
 }
 
@@ -0,0 +1,21 @@
+package models
+
+// BlogDailyView 博客每日浏览统计，BlogID 为0时表示全站
+type BlogDailyView struct {
+	Date   string `gorm:"primaryKey;type:varchar(10);comment:日期" json:"date"`
+	BlogID int64  `gorm:"primaryKey;autoIncrement:false;comment:博客ID，0为全站" json:"blogId"`
+	PV     int64  `gorm:"comment:浏览量" json:"pv"`
+	UV     int64  `gorm:"comment:独立访客数" json:"uv"`
+}
+
+func (*BlogDailyView) TableName() string { return BlogDailyViewTable }
+
+// ViewRollup 博客浏览来源按小时汇总
+type ViewRollup struct {
+	Hour      int64  `gorm:"primaryKey;autoIncrement:false;comment:小时开始时间" json:"hour"`
+	Dimension string `gorm:"primaryKey;size:20;comment:统计维度" json:"dimension"`
+	Value     string `gorm:"primaryKey;size:100;comment:维度取值" json:"value"`
+	Count     int64  `gorm:"comment:浏览次数" json:"count"`
+}
+
+func (*ViewRollup) TableName() string { return ViewRollupTable }
@@ -0,0 +1,84 @@
+package oauth
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	githubAuthURL   = "https://github.com/login/oauth/authorize"
+	githubTokenURL  = "https://github.com/login/oauth/access_token"
+	githubApiURL    = "https://api.github.com"
+	githubName      = "github"
+	githubUserScope = "read:user"
+	githubMailScope = "user:email"
+)
+
+// GithubProvider GitHub OAuth2 登录，GitHub 不支持 OIDC，需要单独调用用户接口
+type GithubProvider struct {
+	clientID     string
+	clientSecret string
+	apiURL       string
+}
+
+// githubUser GitHub 用户信息
+type githubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// githubEmail GitHub 邮箱信息
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// NewGithubProvider 创建 GitHub 提供方
+func NewGithubProvider(clientID, clientSecret string) *GithubProvider {
+	return &GithubProvider{clientID: clientID, clientSecret: clientSecret, apiURL: githubApiURL}
+}
+
+func (p *GithubProvider) Name() string { return githubName }
+
+func (p *GithubProvider) AuthCodeURL(state, verifier, redirectURI string) (string, error) {
+	return buildAuthURL(githubAuthURL, p.clientID, redirectURI, state, verifier, []string{githubUserScope, githubMailScope})
+}
+
+func (p *GithubProvider) Exchange(code, verifier, redirectURI string) (*Identity, error) {
+	token, err := exchangeCode(githubTokenURL, p.clientID, p.clientSecret, code, verifier, redirectURI)
+	if err != nil {
+		return nil, err
+	}
+
+	var user githubUser
+	if err := getJSON(p.apiURL+"/user", token.AccessToken, &user); err != nil {
+		return nil, fmt.Errorf("获取 GitHub 用户信息失败: %w", err)
+	}
+
+	identity := &Identity{
+		Provider: githubName,
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Email:    user.Email,
+		Name:     user.Name,
+		Username: user.Login,
+		Avatar:   user.AvatarURL,
+	}
+
+	// 公开邮箱不一定经过验证，以邮箱接口返回的主邮箱为准
+	var emails []githubEmail
+	if err := getJSON(p.apiURL+"/user/emails", token.AccessToken, &emails); err == nil {
+		for _, email := range emails {
+			if email.Primary {
+				identity.Email = email.Email
+				identity.EmailVerified = email.Verified
+				break
+			}
+		}
+	}
+
+	return identity, nil
+}
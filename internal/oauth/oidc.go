@@ -0,0 +1,122 @@
+package oauth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// OIDCProvider 通用 OpenID Connect 提供方，通过 issuer 的 discovery 文档获取端点
+type OIDCProvider struct {
+	name         string
+	issuer       string
+	clientID     string
+	clientSecret string
+	scopes       []string
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+}
+
+// discoveryDocument OIDC discovery 文档
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// userInfo OIDC userinfo 端点返回的标准声明
+type userInfo struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     any    `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Picture           string `json:"picture"`
+}
+
+// NewOIDCProvider 创建通用 OIDC 提供方
+func NewOIDCProvider(name, issuer, clientID, clientSecret string, scopes []string) *OIDCProvider {
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "profile", "email"}
+	}
+	return &OIDCProvider{
+		name:         name,
+		issuer:       strings.TrimSuffix(issuer, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scopes:       scopes,
+	}
+}
+
+func (p *OIDCProvider) Name() string { return p.name }
+
+// discover 获取并缓存 discovery 文档，失败时下次重试
+func (p *OIDCProvider) discover() (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var doc discoveryDocument
+	if err := getJSON(p.issuer+"/.well-known/openid-configuration", "", &doc); err != nil {
+		return nil, fmt.Errorf("获取 OIDC 配置失败: %w", err)
+	}
+
+	if strings.TrimSuffix(doc.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("OIDC issuer 不匹配: %s", doc.Issuer)
+	}
+
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.UserinfoEndpoint == "" {
+		return nil, errors.New("OIDC 配置缺少必要的端点")
+	}
+
+	p.discovery = &doc
+	return p.discovery, nil
+}
+
+func (p *OIDCProvider) AuthCodeURL(state, verifier, redirectURI string) (string, error) {
+	doc, err := p.discover()
+	if err != nil {
+		return "", err
+	}
+	return buildAuthURL(doc.AuthorizationEndpoint, p.clientID, redirectURI, state, verifier, p.scopes)
+}
+
+func (p *OIDCProvider) Exchange(code, verifier, redirectURI string) (*Identity, error) {
+	doc, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := exchangeCode(doc.TokenEndpoint, p.clientID, p.clientSecret, code, verifier, redirectURI)
+	if err != nil {
+		return nil, err
+	}
+
+	var info userInfo
+	if err := getJSON(doc.UserinfoEndpoint, token.AccessToken, &info); err != nil {
+		return nil, fmt.Errorf("获取用户信息失败: %w", err)
+	}
+
+	if info.Subject == "" {
+		return nil, errors.New("用户信息缺少 sub")
+	}
+
+	// 部分提供方会把 email_verified 返回为字符串
+	verified := info.EmailVerified == true || info.EmailVerified == "true"
+
+	return &Identity{
+		Provider:      p.name,
+		Subject:       info.Subject,
+		Email:         info.Email,
+		EmailVerified: verified,
+		Name:          info.Name,
+		Username:      info.PreferredUsername,
+		Avatar:        info.Picture,
+	}, nil
+}
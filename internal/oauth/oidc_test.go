@@ -0,0 +1,98 @@
+package oauth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// newMockOIDCServer 启动一个本地的模拟 OIDC 提供方
+func newMockOIDCServer(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	var challenge string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 server.URL,
+			"authorization_endpoint": server.URL + "/authorize",
+			"token_endpoint":         server.URL + "/token",
+			"userinfo_endpoint":      server.URL + "/userinfo",
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		challenge = r.URL.Query().Get("code_challenge")
+		redirect, _ := url.Parse(r.URL.Query().Get("redirect_uri"))
+		query := redirect.Query()
+		query.Set("code", "mock-code")
+		query.Set("state", r.URL.Query().Get("state"))
+		redirect.RawQuery = query.Encode()
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("code") != "mock-code" || r.Form.Get("client_secret") != "secret" ||
+			codeChallenge(r.Form.Get("code_verifier")) != challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "mock-access-token", "token_type": "Bearer"})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer mock-access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"sub":                "10086",
+			"email":              "reader@example.com",
+			"email_verified":     true,
+			"name":               "Reader",
+			"preferred_username": "reader",
+		})
+	})
+
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOIDCProviderLogin(t *testing.T) {
+	server := newMockOIDCServer(t)
+	provider := NewOIDCProvider("mock", server.URL, "client", "secret", nil)
+
+	const redirectURI = "http://localhost/api/v1/oauth/mock/callback"
+
+	authURL, err := provider.AuthCodeURL("state-1", "verifier-1", redirectURI)
+	if err != nil {
+		t.Fatalf("生成授权地址失败: %v", err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("请求授权地址失败: %v", err)
+	}
+	resp.Body.Close()
+
+	callback, _ := url.Parse(resp.Header.Get("Location"))
+	if callback.Query().Get("state") != "state-1" {
+		t.Fatalf("state 不一致: %s", callback.Query().Get("state"))
+	}
+
+	identity, err := provider.Exchange(callback.Query().Get("code"), "verifier-1", redirectURI)
+	if err != nil {
+		t.Fatalf("换取用户信息失败: %v", err)
+	}
+
+	if identity.Provider != "mock" || identity.Subject != "10086" || identity.Email != "reader@example.com" || !identity.EmailVerified {
+		t.Fatalf("用户信息不正确: %+v", identity)
+	}
+
+	if _, err := provider.Exchange(callback.Query().Get("code"), "wrong-verifier", redirectURI); err == nil {
+		t.Fatal("错误的 PKCE verifier 应该换取失败")
+	}
+}
@@ -0,0 +1,138 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Identity 第三方账号信息
+type Identity struct {
+	Provider      string // 提供方名称
+	Subject       string // 第三方账号的唯一ID
+	Email         string // 邮箱
+	EmailVerified bool   // 邮箱是否已被第三方验证
+	Name          string // 显示名称
+	Username      string // 第三方账号名
+	Avatar        string // 头像
+}
+
+// Provider 第三方登录提供方
+type Provider interface {
+	// Name 提供方名称，用于路由和绑定记录
+	Name() string
+	// AuthCodeURL 生成授权跳转地址，verifier 用于 PKCE
+	AuthCodeURL(state, verifier, redirectURI string) (string, error)
+	// Exchange 使用授权码换取第三方账号信息
+	Exchange(code, verifier, redirectURI string) (*Identity, error)
+}
+
+var httpClient = &http.Client{Timeout: time.Second * 10}
+
+// tokenResponse 令牌端点的返回
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IdToken     string `json:"id_token"`
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// codeChallenge 计算 PKCE S256 challenge
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// buildAuthURL 拼接授权地址
+func buildAuthURL(endpoint, clientID, redirectURI, state, verifier string, scopes []string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("授权地址无效: %w", err)
+	}
+
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", clientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+	query.Set("code_challenge", codeChallenge(verifier))
+	query.Set("code_challenge_method", "S256")
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// exchangeCode 使用授权码换取访问令牌
+func exchangeCode(endpoint, clientID, clientSecret, code, verifier, redirectURI string) (*tokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {clientID},
+		"client_secret": {clientSecret},
+		"code_verifier": {verifier},
+	}
+
+	request, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	var token tokenResponse
+	if err := doJSON(request, &token); err != nil {
+		return nil, fmt.Errorf("换取访问令牌失败: %w", err)
+	}
+
+	if token.Error != "" {
+		return nil, fmt.Errorf("换取访问令牌失败: %s %s", token.Error, token.Description)
+	}
+
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("换取访问令牌失败: 返回的令牌为空")
+	}
+
+	return &token, nil
+}
+
+// getJSON 携带访问令牌请求 JSON 接口
+func getJSON(endpoint, accessToken string, v any) error {
+	request, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return doJSON(request, v)
+}
+
+// doJSON 发送请求并解析 JSON 返回
+func doJSON(request *http.Request, v any) error {
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("请求 %s 失败，状态码: %d", request.URL.Path, response.StatusCode)
+	}
+
+	return json.Unmarshal(body, v)
+}
@@ -21,6 +21,11 @@ func NewBlogRepository() *BlogRepository {
 	return &BlogRepository{db: configs.DB}
 }
 
+// WithTx 返回在事务 tx 中执行的 BlogRepository
+func (b *BlogRepository) WithTx(tx *gorm.DB) *BlogRepository {
+	return &BlogRepository{db: tx}
+}
+
 // CreateBlog 保存博客到数据库
 func (b *BlogRepository) CreateBlog(blog *models.Blog) error {
 	return b.db.Transaction(func(tx *gorm.DB) error {
@@ -31,6 +36,13 @@ func (b *BlogRepository) CreateBlog(blog *models.Blog) error {
 	})
 }
 
+// ExistsByTitle 判断用户是否已有同名的博客
+func (b *BlogRepository) ExistsByTitle(uid int, title string) (bool, error) {
+	var count int64
+	err := b.db.Model(&models.Blog{}).Where("user_id = ? AND title = ?", uid, title).Count(&count).Error
+	return count > 0, err
+}
+
 func (b *BlogRepository) UpdateBlog(uid int, super bool, blog *models.Blog) error {
 	return b.db.Transaction(func(tx *gorm.DB) error {
 		query := tx.Model(&models.Blog{}).Select(
@@ -80,6 +92,23 @@ func (b *BlogRepository) GetBlogDetail(id int64) (*models.Blog, error) {
 	return &blog, nil
 }
 
+// FindExportBlogs 查找要导出的博客及其分类、专题和标签，uid、category、topic 为空时不过滤
+func (b *BlogRepository) FindExportBlogs(uid, category, topic *int) ([]models.Blog, error) {
+	var blogs []models.Blog
+	build := b.db.Preload("Tags").Preload("Category").Preload("Topic")
+	if uid != nil {
+		build = build.Where("user_id = ?", *uid)
+	}
+	if category != nil {
+		build = build.Where("category_id = ?", *category)
+	}
+	if topic != nil {
+		build = build.Where("topic_id = ?", *topic)
+	}
+	err := build.Order("created_at").Find(&blogs).Error
+	return blogs, err
+}
+
 // FindAllSearchBlog 查找所有可搜索的博客
 func (b *BlogRepository) FindAllSearchBlog() ([]response.SearchBlogResponse, error) {
 	var blogs []response.SearchBlogResponse
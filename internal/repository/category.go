@@ -39,6 +39,30 @@ func (c *CategoryRepository) SaveCategory(category models.Category) error {
 	})
 }
 
+// FindOrCreateCategory 按名称查找分类，已删除时恢复，不存在时创建，返回是否新建或恢复了分类
+func (c *CategoryRepository) FindOrCreateCategory(name string) (models.Category, bool, error) {
+	var (
+		category models.Category
+		changed  bool
+	)
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("name = ?", name).Limit(1).Find(&category)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			category, changed = models.Category{Name: name}, true
+			return tx.Create(&category).Error
+		}
+		if category.DeletedAt.Valid {
+			changed = true
+			return tx.Unscoped().Model(&category).Update("deleted_at", nil).Error
+		}
+		return nil
+	})
+	return category, changed, err
+}
+
 // DeleteCategoryBlogs 批量软删除指定分类下的所有博客
 func (c *CategoryRepository) DeleteCategoryBlogs(categoryIds []int64) error {
 	if len(categoryIds) == 0 {
@@ -27,6 +27,16 @@ func (u *FileRepository) FindByMd5Info(md5 string) (models.FileMd5Info, error) {
 	return md5Info, err
 }
 
+// FindMd5InfosByUrls 根据文件地址查找文件信息
+func (u *FileRepository) FindMd5InfosByUrls(urls []string) ([]models.FileMd5Info, error) {
+	var infos []models.FileMd5Info
+	if len(urls) == 0 {
+		return infos, nil
+	}
+	err := u.db.Model(&models.FileMd5Info{}).Where("url IN ?", urls).Find(&infos).Error
+	return infos, err
+}
+
 func (u *FileRepository) DeleteMd5Infos(md5 string) error {
 	// 删除 FileInfo 表中的记录
 	var info = &models.FileInfo{}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"blog/internal/models"
+	"blog/pkg/configs"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentityRepository 第三方账号绑定数据访问层
+type UserIdentityRepository struct {
+	db *gorm.DB
+}
+
+// FindIdentity 根据提供方和第三方账号ID查找绑定
+func (r *UserIdentityRepository) FindIdentity(provider, subject string) (models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.Model(&models.UserIdentity{}).
+		First(&identity, "provider = ? AND subject = ?", provider, subject).Error
+	if err != nil {
+		return identity, fmt.Errorf("查找第三方绑定失败: %w", err)
+	}
+	return identity, nil
+}
+
+// FindByUserId 获取用户绑定的所有第三方账号
+func (r *UserIdentityRepository) FindByUserId(uid int) ([]models.UserIdentity, error) {
+	var identities = make([]models.UserIdentity, 0)
+	if err := r.db.Model(&models.UserIdentity{}).Where("user_id = ?", uid).Find(&identities).Error; err != nil {
+		return nil, fmt.Errorf("查找第三方绑定失败: %w", err)
+	}
+	return identities, nil
+}
+
+// Save 保存第三方绑定
+func (r *UserIdentityRepository) Save(identity *models.UserIdentity) error {
+	if err := r.db.Create(identity).Error; err != nil {
+		return fmt.Errorf("保存第三方绑定失败: %w", err)
+	}
+	return nil
+}
+
+// SaveUserWithIdentity 注册新用户并绑定第三方账号
+func (r *UserIdentityRepository) SaveUserWithIdentity(user *models.User, identity *models.UserIdentity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return fmt.Errorf("创建用户失败: %w", err)
+		}
+		identity.UserID = user.ID
+		if err := tx.Create(identity).Error; err != nil {
+			return fmt.Errorf("保存第三方绑定失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteByUserIdAndProvider 解除用户的第三方绑定
+func (r *UserIdentityRepository) DeleteByUserIdAndProvider(uid int, provider string) error {
+	result := r.db.Unscoped().Where("user_id = ? AND provider = ?", uid, provider).Delete(&models.UserIdentity{})
+	if result.Error != nil {
+		return fmt.Errorf("解除第三方绑定失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("未绑定该第三方账号")
+	}
+	return nil
+}
+
+// NewUserIdentityRepository 创建第三方账号绑定数据访问层
+func NewUserIdentityRepository() *UserIdentityRepository {
+	return &UserIdentityRepository{db: configs.DB}
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"blog/internal/models"
+	"blog/pkg/configs"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobRepository 定时任务执行记录数据访问层
+type JobRepository struct {
+	db *gorm.DB
+}
+
+// CreateRun 保存执行记录
+func (j *JobRepository) CreateRun(run *models.JobRun) error {
+	if err := j.db.Create(run).Error; err != nil {
+		return fmt.Errorf("保存任务执行记录失败: %w", err)
+	}
+	return nil
+}
+
+// FinishRun 更新执行结果
+func (j *JobRepository) FinishRun(run *models.JobRun) error {
+	err := j.db.Model(&models.JobRun{}).Where("id = ?", run.ID).
+		UpdateColumns(map[string]interface{}{
+			"status":   run.Status,
+			"error":    run.Error,
+			"end_at":   run.EndAt,
+			"duration": run.Duration,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("更新任务执行记录失败: %w", err)
+	}
+	return nil
+}
+
+// FindRuns 分页获取执行记录，name 为空时查询所有任务
+func (j *JobRepository) FindRuns(name string, page, size int, count *int64) ([]models.JobRun, error) {
+	var runs = make([]models.JobRun, 0)
+
+	db := j.db.Model(&models.JobRun{})
+	if name != "" {
+		db = db.Where("name = ?", name)
+	}
+
+	if err := db.Count(count).Error; err != nil {
+		return nil, fmt.Errorf("统计任务执行记录失败: %w", err)
+	}
+
+	if err := db.Order("start_at desc").Offset((page - 1) * size).Limit(size).Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("查询任务执行记录失败: %w", err)
+	}
+
+	return runs, nil
+}
+
+// FindPausedNames 获取已暂停的任务名称
+func (j *JobRepository) FindPausedNames() ([]string, error) {
+	var names = make([]string, 0)
+	if err := j.db.Model(&models.JobState{}).Where("paused = ?", true).Pluck("name", &names).Error; err != nil {
+		return nil, fmt.Errorf("查询任务暂停状态失败: %w", err)
+	}
+	return names, nil
+}
+
+// SavePaused 保存任务的暂停状态，记录不存在时创建
+func (j *JobRepository) SavePaused(name string, paused bool) error {
+	err := j.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"paused", "updated_at"}),
+	}).Create(&models.JobState{Name: name, Paused: paused}).Error
+	if err != nil {
+		return fmt.Errorf("保存任务暂停状态失败: %w", err)
+	}
+	return nil
+}
+
+// NewJobRepository 创建定时任务执行记录数据访问层
+func NewJobRepository() *JobRepository {
+	return &JobRepository{db: configs.DB}
+}
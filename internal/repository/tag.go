@@ -154,6 +154,30 @@ func (t *TagRepository) SaveTag(tag models.Tag) error {
 	})
 }
 
+// FindOrCreateTag 按名称查找标签，已删除时恢复，不存在时创建，返回是否新建或恢复了标签
+func (t *TagRepository) FindOrCreateTag(name string) (models.Tag, bool, error) {
+	var (
+		tag     models.Tag
+		changed bool
+	)
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("name = ?", name).Limit(1).Find(&tag)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			tag, changed = models.Tag{Name: name}, true
+			return tx.Create(&tag).Error
+		}
+		if tag.DeletedAt.Valid {
+			changed = true
+			return tx.Unscoped().Model(&tag).Update("deleted_at", nil).Error
+		}
+		return nil
+	})
+	return tag, changed, err
+}
+
 // DeleteTags 批量删除标签
 func (t *TagRepository) DeleteTags(ids []int64) error {
 	return t.db.Transaction(func(tx *gorm.DB) error {
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"blog/internal/models"
+	"blog/pkg/configs"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// AccessTokenRepository 个人访问令牌数据访问层
+type AccessTokenRepository struct {
+	db *gorm.DB
+}
+
+// Save 保存个人访问令牌
+func (a *AccessTokenRepository) Save(token *models.AccessToken) error {
+	if err := a.db.Create(token).Error; err != nil {
+		return fmt.Errorf("创建个人访问令牌失败: %w", err)
+	}
+	return nil
+}
+
+// CountByUserId 统计用户的令牌数量
+func (a *AccessTokenRepository) CountByUserId(uid int) (int64, error) {
+	var count int64
+	if err := a.db.Model(&models.AccessToken{}).Where("user_id = ?", uid).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("统计个人访问令牌失败: %w", err)
+	}
+	return count, nil
+}
+
+// FindByUserId 获取用户的所有令牌
+func (a *AccessTokenRepository) FindByUserId(uid int) ([]models.AccessToken, error) {
+	var tokens = make([]models.AccessToken, 0)
+	if err := a.db.Model(&models.AccessToken{}).Where("user_id = ?", uid).Order("created_at desc").Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("查找个人访问令牌失败: %w", err)
+	}
+	return tokens, nil
+}
+
+// FindByHash 根据令牌摘要查找令牌
+func (a *AccessTokenRepository) FindByHash(hash string) (models.AccessToken, error) {
+	var token models.AccessToken
+	if err := a.db.Model(&models.AccessToken{}).First(&token, "token_hash = ?", hash).Error; err != nil {
+		return token, fmt.Errorf("查找个人访问令牌失败: %w", err)
+	}
+	return token, nil
+}
+
+// UpdateLastUsed 更新令牌最后使用时间和IP
+func (a *AccessTokenRepository) UpdateLastUsed(id int, at int64, ip string) error {
+	return a.db.Model(&models.AccessToken{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"last_used_at": at, "last_used_ip": ip}).Error
+}
+
+// DeleteByIdAndUserId 删除用户的令牌，返回被删除令牌的摘要
+func (a *AccessTokenRepository) DeleteByIdAndUserId(id, uid int) (string, error) {
+	var token models.AccessToken
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&token, "id = ? AND user_id = ?", id, uid).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&token).Error
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", errors.New("令牌不存在")
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("删除个人访问令牌失败: %w", err)
+	}
+
+	return token.TokenHash, nil
+}
+
+// NewAccessTokenRepository 创建个人访问令牌数据访问层
+func NewAccessTokenRepository() *AccessTokenRepository {
+	return &AccessTokenRepository{db: configs.DB}
+}
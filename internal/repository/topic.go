@@ -115,6 +115,30 @@ func (t *TopicRepository) GetTopicBlogs(tid int) []response.SimpleBlogResponse {
 	return list
 }
 
+// FindOrCreateTopic 按名称查找专题，不存在时为 uid 创建，已删除时恢复；changed 表示创建或恢复了专题
+func (t *TopicRepository) FindOrCreateTopic(uid int, name string) (models.Topic, bool, error) {
+	var (
+		topic   models.Topic
+		changed bool
+	)
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("name = ?", name).Limit(1).Find(&topic)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			topic, changed = models.Topic{Name: name, UserID: uid}, true
+			return tx.Create(&topic).Error
+		}
+		if topic.DeletedAt.Valid {
+			changed = true
+			return tx.Unscoped().Model(&topic).Update("deleted_at", nil).Error
+		}
+		return nil
+	})
+	return topic, changed, err
+}
+
 // Create 创建专题
 func (t *TopicRepository) Create(topic models.Topic) error {
 	return t.db.Transaction(func(tx *gorm.DB) error {
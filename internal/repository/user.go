@@ -46,10 +46,47 @@ func (u *UserRepository) UpdateLoginStatus(dto dtos.UserLoginStatus) error {
 	return nil
 }
 
+// UpdateVerified 更新邮箱验证状态
+func (u *UserRepository) UpdateVerified(id int, verified bool) error {
+	return u.db.Model(&models.User{}).Where("id = ?", id).Update("verified", verified).Error
+}
+
 func (u *UserRepository) UpdatePassword(id int, password string) error {
 	return u.db.Model(&models.User{}).Where("id = ?", id).Update("password", password).Error
 }
 
+// CreateIfRoleAbsent 持有咨询锁并确认没有该角色的用户后再创建，已有该角色的用户时返回 false
+func (u *UserRepository) CreateIfRoleAbsent(user *models.User, lockKey int64) (bool, error) {
+	created := false
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockKey).Error; err != nil {
+			return fmt.Errorf("获取锁失败: %w", err)
+		}
+		var count int64
+		if err := tx.Model(&models.User{}).Where("role_id = ?", user.RoleID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		if err := tx.Create(user).Error; err != nil {
+			return fmt.Errorf("创建用户失败: %w", err)
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+// Disable 清空密码、禁用账号并降为普通用户
+func (u *UserRepository) Disable(id int) error {
+	return u.db.Model(&models.User{}).Where("id = ?", id).Updates(map[string]any{
+		"password": "",
+		"status":   false,
+		"role_id":  uint(common.UserRoleId),
+	}).Error
+}
+
 // FindByUsername 根据用户名查找用户
 func (u *UserRepository) FindByUsername(username string) (models.User, error) {
 	var user models.User
@@ -61,6 +98,24 @@ func (u *UserRepository) FindByUsername(username string) (models.User, error) {
 	return user, nil
 }
 
+// ExistsByRole 判断是否有指定角色的用户
+func (u *UserRepository) ExistsByRole(roleId uint) (bool, error) {
+	var count int64
+	err := u.db.Model(&models.User{}).Where("role_id = ?", roleId).Count(&count).Error
+	return count > 0, err
+}
+
+// FindByEmail 根据邮箱查找用户
+func (u *UserRepository) FindByEmail(email string) (models.User, error) {
+	var user models.User
+	err := u.db.Model(&models.User{}).Preload("Role").
+		First(&user, "email = ?", email).Error
+	if err != nil {
+		return user, fmt.Errorf("查找用户失败: %w", err)
+	}
+	return user, nil
+}
+
 // FindById 根据用户ID查找用户
 func (u *UserRepository) FindById(id int) (models.User, error) {
 	var user models.User
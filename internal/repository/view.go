@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"blog/internal/dto/response"
+	"blog/internal/models"
+	"blog/pkg/configs"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ViewRepository 浏览统计数据访问层
+type ViewRepository struct {
+	db *gorm.DB
+}
+
+// SaveDailyViews 保存每日浏览统计，已存在时覆盖
+func (v *ViewRepository) SaveDailyViews(views []models.BlogDailyView) error {
+	if len(views) == 0 {
+		return nil
+	}
+	err := v.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}, {Name: "blog_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"pv", "uv"}),
+	}).CreateInBatches(views, 500).Error
+	if err != nil {
+		return fmt.Errorf("保存每日浏览统计失败: %w", err)
+	}
+	return nil
+}
+
+// FindDailyViews 获取日期范围内的每日浏览统计，blogID 为0时查询全站
+func (v *ViewRepository) FindDailyViews(blogID int64, start, end string) ([]models.BlogDailyView, error) {
+	var views = make([]models.BlogDailyView, 0)
+	err := v.db.Model(&models.BlogDailyView{}).
+		Where("blog_id = ? AND date BETWEEN ? AND ?", blogID, start, end).
+		Order("date").Find(&views).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询每日浏览统计失败: %w", err)
+	}
+	return views, nil
+}
+
+// SaveViewRollups 保存每小时的浏览来源汇总，已存在时覆盖
+func (v *ViewRepository) SaveViewRollups(rollups []models.ViewRollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+	err := v.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "hour"}, {Name: "dimension"}, {Name: "value"}},
+		DoUpdates: clause.AssignmentColumns([]string{"count"}),
+	}).CreateInBatches(rollups, 500).Error
+	if err != nil {
+		return fmt.Errorf("保存浏览来源汇总失败: %w", err)
+	}
+	return nil
+}
+
+// SumViewRollups 统计时间范围 [from, to) 内某个维度浏览次数最多的取值
+func (v *ViewRepository) SumViewRollups(dimension string, from, to int64, limit int) ([]response.ViewSourceResponse, error) {
+	var list = make([]response.ViewSourceResponse, 0)
+	err := v.db.Model(&models.ViewRollup{}).
+		Select("value, SUM(count) AS count").
+		Where("dimension = ? AND hour >= ? AND hour < ?", dimension, from, to).
+		Group("value").Order("count DESC").Limit(limit).
+		Scan(&list).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计浏览来源失败: %w", err)
+	}
+	return list, nil
+}
+
+// NewViewRepository 创建浏览统计数据访问层
+func NewViewRepository() *ViewRepository {
+	return &ViewRepository{db: configs.DB}
+}
@@ -0,0 +1,34 @@
+package router
+
+import (
+	"blog/internal/handler"
+	"blog/internal/middleware"
+	"blog/pkg/common"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// RegisterBackupRouter 注册备份相关路由
+func RegisterBackupRouter(router fiber.Router) {
+	backupController := handler.NewBackupController()
+
+	backupRouter := router.Group("/backup")
+
+	// 超级管理员路由
+	{
+		// 创建备份
+		backupRouter.Post("/admin/create", backupController.CreateBackup, middleware.LoggerMiddleware, middleware.JwtMiddle(common.SuperAdminRoleId), middleware.SystemLogMiddleware("backup", "create", "创建备份", true))
+
+		// 获取备份进度
+		backupRouter.Get("/admin/progress/:id", backupController.GetBackupProgress, middleware.JwtMiddle(common.SuperAdminRoleId))
+
+		// 获取备份文件列表
+		backupRouter.Get("/admin/list", backupController.GetBackups, middleware.JwtMiddle(common.SuperAdminRoleId))
+
+		// 重新验证密码，获取一次性下载令牌
+		backupRouter.Post("/admin/reauth", backupController.Reauthenticate, middleware.LoggerMiddleware, middleware.JwtMiddle(common.SuperAdminRoleId), middleware.SystemLogMiddleware("backup", "reauth", "下载备份重新验证", false))
+
+		// 下载备份文件
+		backupRouter.Get("/admin/download/:name", backupController.DownloadBackup, middleware.LoggerMiddleware, middleware.JwtMiddle(common.SuperAdminRoleId), middleware.SystemLogMiddleware("backup", "download", "下载备份", false))
+	}
+}
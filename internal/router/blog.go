@@ -11,6 +11,8 @@ import (
 // RegisterBlogRouter 注册博客相关路由
 func RegisterBlogRouter(router fiber.Router) {
 	blogController := handler.NewBlogController()
+	postImportController := handler.NewPostImportController()
+	postExportController := handler.NewPostExportController()
 	blogRouter := router.Group("/blog")
 
 	// 普通路由
@@ -77,6 +79,12 @@ func RegisterBlogRouter(router fiber.Router) {
 
 		//获取保存的临时博客
 		blogRouter.Get("/admin/get_temp", blogController.GetTempBlog, middleware.LoggerMiddleware, middleware.SystemLogMiddleware("blog", "get", "获取保存临时博客内容", true))
+
+		// 从 Markdown 的 zip 或 WordPress 导出文件导入博客
+		blogRouter.Post("/admin/import", postImportController.ImportPosts, middleware.LoggerMiddleware, middleware.JwtMiddle(common.AdminRoleId), middleware.VerifiedMiddle, middleware.SystemLogMiddleware("blog", "import", "导入博客", false))
+
+		// 导出博客为 Markdown 和图片的 zip，超级管理员可以导出其他用户的博客
+		blogRouter.Get("/admin/export", postExportController.ExportPosts, middleware.LoggerMiddleware, middleware.JwtMiddle(common.AdminRoleId), middleware.SystemLogMiddleware("blog", "export", "导出博客", true))
 	}
 
 	// 超级管理员路由
@@ -9,6 +9,8 @@ import (
 )
 
 func RegisterConsoleRouter(router fiber.Router) {
+	viewController := handler.NewViewController()
+
 	consoleRouter := router.Group("/system")
 
 	{
@@ -21,6 +23,15 @@ func RegisterConsoleRouter(router fiber.Router) {
 		consoleRouter.Get("/admin/system_log", handler.GetSystemLogInfo, middleware.LoggerMiddleware, middleware.JwtMiddle(common.SuperAdminRoleId))
 
 		consoleRouter.Put("/admin/system_log", handler.DeleteSystemInfoLog, middleware.LoggerMiddleware, middleware.JwtMiddle(common.SuperAdminRoleId))
+
+		// 全站浏览趋势
+		consoleRouter.Get("/trend", viewController.GetSiteViewTrend, middleware.LoggerMiddleware, middleware.JwtMiddle(common.AdminRoleId))
+
+		// 单篇博客浏览趋势
+		consoleRouter.Get("/trend/:id", viewController.GetBlogViewTrend, middleware.LoggerMiddleware, middleware.JwtMiddle(common.AdminRoleId))
+
+		// 浏览来源统计
+		consoleRouter.Get("/sources", viewController.GetViewSources, middleware.LoggerMiddleware, middleware.JwtMiddle(common.AdminRoleId))
 	}
 
 }
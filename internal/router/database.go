@@ -11,12 +11,28 @@ import (
 // RegisterTagRouter 标签相关路由
 func RegisterDataBaseRouter(router fiber.Router) {
 	dbController := handler.NewDataBaseController()
+	transferController := handler.NewTransferController()
 
 	dbRouter := router.Group("/database").Use(middleware.JwtMiddle(common.SuperAdminRoleId), middleware.LoggerMiddleware)
 
 	{
 		dbRouter.Get("get", dbController.GetTableInsertSQL)
+
+		// 表结构
+		dbRouter.Get("tables", dbController.GetTables)
+		dbRouter.Get("tables/:name", dbController.GetTable)
+
+		// 只读查询和执行计划，每次执行都记录操作日志
+		dbRouter.Post("query", dbController.QuerySQL)
+
+		// 写入模式，默认只预览并回滚，commit 为 true 时提交
 		dbRouter.Post("exec", dbController.ExecSQL)
+
+		// 导出站点数据
+		dbRouter.Get("export", transferController.Export, middleware.SystemLogMiddleware("database", "export", "导出站点数据", false))
+
+		// 导入站点数据
+		dbRouter.Post("import", transferController.Import, middleware.VerifiedMiddle, middleware.SystemLogMiddleware("database", "import", "导入站点数据", false))
 	}
 
 }
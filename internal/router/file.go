@@ -23,9 +23,9 @@ func RegisterFileRouter(router fiber.Router) {
 
 	// 管理员路由
 	{
-		fileRouter.Post("/upload", fileController.UploadFile, middleware.LoggerMiddleware, middleware.JwtMiddle(common.AdminRoleId), middleware.SystemLogMiddleware("file", "upload", "上传文件", false))
+		fileRouter.Post("/upload", fileController.UploadFile, middleware.LoggerMiddleware, middleware.JwtMiddle(common.AdminRoleId), middleware.VerifiedMiddle, middleware.SystemLogMiddleware("file", "upload", "上传文件", false))
 
-		fileRouter.Post("/upload/image", fileController.UploadImage, middleware.LoggerMiddleware, middleware.JwtMiddle(common.AdminRoleId), middleware.SystemLogMiddleware("file", "upload", "上传图片", false))
+		fileRouter.Post("/upload/image", fileController.UploadImage, middleware.LoggerMiddleware, middleware.JwtMiddle(common.AdminRoleId), middleware.VerifiedMiddle, middleware.SystemLogMiddleware("file", "upload", "上传图片", false))
 
 		fileRouter.Get("/current_list", fileController.GetCurrentFileFileList, middleware.JwtMiddle(common.AdminRoleId))
 
@@ -55,10 +55,6 @@ func RegisterFileRouter(router fiber.Router) {
 
 		fileRouter.Get("/admin/system_file/current_log", fileController.GetCurrentLog, middleware.LoggerMiddleware, middleware.JwtMiddle(common.SuperAdminRoleId))
 
-		fileRouter.Post("/admin/system_file/tar", fileController.TarDockerComposeData, middleware.LoggerMiddleware, middleware.JwtMiddle(common.SuperAdminRoleId))
-
-		fileRouter.Get("/admin/system_file/tar", fileController.DownloadTar)
-
 		fileRouter.Get("/admin/system_file/download", fileController.DownloadSystemFile)
 	}
 }
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"blog/internal/handler"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// RegisterHealthRouter 注册存活与就绪检查路由
+func RegisterHealthRouter(router fiber.Router) {
+	healthController := handler.NewHealthController()
+
+	// 存活检查
+	router.Get("/healthz", healthController.Liveness)
+
+	// 就绪检查，探测数据库、Redis、搜索、上传目录和SMTP
+	router.Get("/readyz", healthController.Readiness)
+}
@@ -0,0 +1,34 @@
+package router
+
+import (
+	"blog/internal/handler"
+	"blog/internal/middleware"
+	"blog/pkg/common"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// RegisterJobRouter 注册定时任务相关路由
+func RegisterJobRouter(router fiber.Router) {
+	jobController := handler.NewJobController()
+
+	jobRouter := router.Group("/job")
+
+	// 超级管理员路由
+	{
+		// 获取任务列表
+		jobRouter.Get("/admin/list", jobController.GetJobs, middleware.JwtMiddle(common.SuperAdminRoleId))
+
+		// 获取任务执行记录
+		jobRouter.Get("/admin/runs", jobController.GetJobRuns, middleware.JwtMiddle(common.SuperAdminRoleId), middleware.PaginationMiddleware)
+
+		// 暂停任务
+		jobRouter.Put("/admin/pause/:name", jobController.PauseJob, middleware.LoggerMiddleware, middleware.JwtMiddle(common.SuperAdminRoleId), middleware.SystemLogMiddleware("job", "update", "暂停定时任务", false))
+
+		// 恢复任务
+		jobRouter.Put("/admin/resume/:name", jobController.ResumeJob, middleware.LoggerMiddleware, middleware.JwtMiddle(common.SuperAdminRoleId), middleware.SystemLogMiddleware("job", "update", "恢复定时任务", false))
+
+		// 立即执行任务
+		jobRouter.Post("/admin/run/:name", jobController.TriggerJob, middleware.LoggerMiddleware, middleware.JwtMiddle(common.SuperAdminRoleId), middleware.SystemLogMiddleware("job", "run", "手动执行定时任务", false))
+	}
+}
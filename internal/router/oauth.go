@@ -0,0 +1,40 @@
+package router
+
+import (
+	"blog/internal/handler"
+	"blog/internal/middleware"
+	"blog/pkg/common"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// RegisterOAuthRouter 注册第三方登录相关路由
+func RegisterOAuthRouter(router fiber.Router) {
+	oauthController := handler.NewOAuthController()
+
+	oauthRouter := router.Group("/oauth")
+
+	// 普通路由
+	{
+		// 获取已启用的第三方登录
+		oauthRouter.Get("/providers", oauthController.GetProviders)
+
+		// 获取第三方登录授权地址
+		oauthRouter.Get("/:provider/authorize", oauthController.Authorize, middleware.LoggerMiddleware)
+
+		// 第三方授权回调
+		oauthRouter.Get("/:provider/callback", oauthController.Callback, middleware.LoggerMiddleware)
+	}
+
+	// 用户路由
+	{
+		// 获取已绑定的第三方账号
+		oauthRouter.Get("/identities", oauthController.GetIdentities, middleware.JwtMiddle(common.UserRoleId))
+
+		// 获取绑定第三方账号的授权地址
+		oauthRouter.Get("/:provider/link", oauthController.Link, middleware.LoggerMiddleware, middleware.JwtMiddle(common.UserRoleId))
+
+		// 解除第三方绑定
+		oauthRouter.Delete("/:provider/unlink", oauthController.Unlink, middleware.LoggerMiddleware, middleware.JwtMiddle(common.UserRoleId), middleware.SystemLogMiddleware("oauth", "unlink", "解除第三方绑定", false))
+	}
+}
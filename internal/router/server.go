@@ -1,14 +1,23 @@
 package router
 
 import (
+	"blog/internal/job"
 	"blog/internal/middleware"
+	"blog/pkg/background"
 	"blog/pkg/configs"
 	"blog/pkg/logger"
+	"blog/pkg/metrics"
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/adaptor"
 	"github.com/gofiber/fiber/v3/middleware/cors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
 
@@ -19,14 +28,60 @@ type Server struct {
 	router fiber.Router         // 路由器
 }
 
-// Run 启动服务器并监听指定地址
+// Run 启动服务器并监听指定地址，收到 SIGINT、SIGTERM 后优雅关闭
 func (s Server) Run() {
 	s.router.Get("/ping", pingHandler)
 	logger.Info("服务器已启动，监听到", zap.String("addr", s.config.Addr))
-	if err := s.app.Listen(s.config.Addr); err != nil {
-		logger.Error("服务器启动失败", zap.Error(err))
-		log.Fatal(err) // 启动服务器
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.app.Listen(s.config.Addr)
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			logger.Error("服务器启动失败", zap.Error(err))
+			log.Fatal(err) // 启动服务器
+		}
+	case sig := <-quit:
+		logger.Info("收到退出信号，开始关闭服务器", zap.String("signal", sig.String()))
 	}
+
+	s.shutdown()
+}
+
+// shutdown 停止监听并等待处理中的请求和后台任务，写入缓冲的计数后关闭数据库和缓存连接；
+// 停止监听和等待任务共用配置的等待时间，超时后不再等待剩余任务，收尾操作各自使用独立的等待时间
+func (s Server) shutdown() {
+	timeout := s.config.GetShutdownTimeout()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	background.Drain()
+
+	if err := s.app.ShutdownWithContext(ctx); err != nil {
+		logger.Error("关闭HTTP服务失败", zap.Error(err))
+	}
+
+	job.Stop()
+
+	if err := background.Shutdown(ctx, s.config.GetShutdownHookTimeout()); err != nil {
+		logger.Error("后台任务未全部完成", zap.Error(err))
+	}
+
+	if err := configs.CloseDB(); err != nil {
+		logger.Error("关闭数据库连接失败", zap.Error(err))
+	}
+	if err := configs.CloseRedis(); err != nil {
+		logger.Error("关闭缓存连接失败", zap.Error(err))
+	}
+
+	logger.Info("服务器已关闭", zap.Duration("timeout", timeout))
+	logger.Sync()
 }
 
 // pingHandler 健康检查路由处理函数
@@ -68,6 +123,12 @@ func NewServer(config *configs.ServerConfig) *Server {
 		BodyLimit:    config.MaxSize * 1024 * 1024,                     // 请求体大小限制：10MB
 		ReadTimeout:  time.Second * time.Duration(config.ReadTimeOut),  // 读取超时
 		WriteTimeout: time.Second * time.Duration(config.WriteTimeOut), // 写入超时
+
+		// 客户端IP只从信任的反向代理传递的请求头中读取，未配置代理时使用连接的IP
+		ProxyHeader:             config.ProxyHeader,
+		TrustedProxies:          config.TrustedProxies,
+		EnableTrustedProxyCheck: true,
+		EnableIPValidation:      true,
 	}
 
 	// 创建 Fiber 应用实例
@@ -79,10 +140,25 @@ func NewServer(config *configs.ServerConfig) *Server {
 	// 配置 CORS
 	setupCORS(app, config.Cors)
 
+	// 配置 Prometheus 指标
+	setupMetrics(app, configs.CONFIG.Metrics)
+
 	// 返回新的 Server 实例
 	return &Server{config: *config, app: app, router: app.Group(config.ApiPrefix)}
 }
 
+// setupMetrics 配置请求指标采集和指标接口
+func setupMetrics(app *fiber.App, metricsConfig configs.MetricsConfig) {
+	if !metricsConfig.Enable {
+		return
+	}
+	app.Use(middleware.MetricsMiddleware)
+
+	exporter := promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})
+	app.Get(metricsConfig.GetPath(), adaptor.HTTPHandler(exporter), middleware.MetricsGuard(metricsConfig))
+	logger.Info("指标接口已启用", zap.String("path", metricsConfig.GetPath()))
+}
+
 // setupCORS 配置跨域资源共享
 func setupCORS(app *fiber.App, corsConfig configs.CorsConfig) {
 	if corsConfig.Enable {
@@ -0,0 +1,21 @@
+package router
+
+import (
+	"blog/internal/handler"
+	"blog/internal/middleware"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// RegisterSetupRouter 注册首次安装路由
+func RegisterSetupRouter(router fiber.Router) {
+	setupController := handler.NewSetupController()
+
+	setupRouter := router.Group("/setup")
+
+	// 是否需要首次安装
+	setupRouter.Get("/", setupController.GetStatus)
+
+	// 创建站长账号和网站配置，已有超级管理员时不可用
+	setupRouter.Post("/", setupController.Setup, middleware.LoggerMiddleware, middleware.SystemLogMiddleware("setup", "create", "首次安装", false))
+}
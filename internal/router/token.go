@@ -0,0 +1,31 @@
+package router
+
+import (
+	"blog/internal/handler"
+	"blog/internal/middleware"
+	"blog/pkg/common"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// RegisterAccessTokenRouter 注册个人访问令牌相关路由
+func RegisterAccessTokenRouter(router fiber.Router) {
+	tokenController := handler.NewAccessTokenController()
+
+	tokenRouter := router.Group("/token")
+
+	// 用户路由
+	{
+		// 获取可选的权限范围
+		tokenRouter.Get("/scopes", tokenController.GetAccessTokenScopes, middleware.JwtMiddle(common.UserRoleId))
+
+		// 获取当前用户的令牌列表
+		tokenRouter.Get("/list", tokenController.GetAccessTokens, middleware.JwtMiddle(common.UserRoleId))
+
+		// 创建令牌
+		tokenRouter.Post("/create", tokenController.CreateAccessToken, middleware.LoggerMiddleware, middleware.JwtMiddle(common.UserRoleId), middleware.SystemLogMiddleware("token", "create", "创建访问令牌", false))
+
+		// 撤销令牌
+		tokenRouter.Delete("/revoke/:id", tokenController.RevokeAccessToken, middleware.LoggerMiddleware, middleware.JwtMiddle(common.UserRoleId), middleware.SystemLogMiddleware("token", "delete", "撤销访问令牌", false))
+	}
+}
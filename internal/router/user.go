@@ -46,6 +46,12 @@ func RegisterUserRouter(router fiber.Router) {
 		// 获取当前用户信息
 		userRouter.Put("/auth/reset", userController.ResetPassword, middleware.JwtMiddle(common.UserRoleId), middleware.SystemLogMiddleware("user", "reset", "重置密码", true))
 
+		// 发送邮箱验证码
+		userRouter.Get("/auth/send_verify", userController.SendVerifyEmail, middleware.LoggerMiddleware, middleware.JwtMiddle(common.UserRoleId))
+
+		// 验证邮箱
+		userRouter.Post("/auth/verify", userController.VerifyEmail, middleware.LoggerMiddleware, middleware.JwtMiddle(common.UserRoleId), middleware.SystemLogMiddleware("user", "verify", "验证邮箱", false))
+
 		// 用户登出
 		userRouter.Get("/logout", userController.Logout, middleware.LoggerMiddleware, middleware.JwtMiddle(common.UserRoleId), middleware.SystemLogMiddleware("user", "logout", "退出登录", true))
 	}
@@ -66,7 +72,7 @@ func RegisterUserRouter(router fiber.Router) {
 		// 修改用户角色
 		userRouter.Put("/admin/update_role", userController.UpdateUserRole, middleware.LoggerMiddleware, middleware.JwtMiddle(common.SuperAdminRoleId), middleware.SystemLogMiddleware("user", "update", "修改用户角色", true))
 
-		// 获取所有 Redis 键
+		// 按游标分页获取 Redis 键
 		userRouter.Get("/admin/redis_keys", userController.GetRedisKeys, middleware.LoggerMiddleware, middleware.JwtMiddle(common.SuperAdminRoleId))
 
 		// 删除 Redis 键
@@ -75,6 +81,12 @@ func RegisterUserRouter(router fiber.Router) {
 		// 匹配删除 Redis 键
 		userRouter.Delete("/admin/redis_match_delete", userController.MatchDelKeys, middleware.LoggerMiddleware, middleware.JwtMiddle(common.SuperAdminRoleId), middleware.SystemLogMiddleware("user", "delete", "匹配删除Redis键", true))
 
+		// 获取登录锁定列表
+		userRouter.Get("/admin/login_locks", userController.GetLoginLocks, middleware.LoggerMiddleware, middleware.JwtMiddle(common.SuperAdminRoleId))
+
+		// 解除登录锁定
+		userRouter.Put("/admin/login_locks", userController.ClearLoginLocks, middleware.LoggerMiddleware, middleware.JwtMiddle(common.SuperAdminRoleId), middleware.SystemLogMiddleware("user", "unlock", "解除登录锁定", true))
+
 		// 修改网站配置
 		userRouter.Put("/admin/config", userController.UpdateWebSiteConfig, middleware.LoggerMiddleware, middleware.JwtMiddle(common.SuperAdminRoleId), middleware.SystemLogMiddleware("user", "config", "修改网站配置", true))
 	}
@@ -2,10 +2,14 @@ package search
 
 import (
 	"blog/internal/utils"
+	"blog/pkg/metrics"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type MeiliSearchClient struct {
@@ -37,14 +41,36 @@ func (c *MeiliSearchClient) SendRequest(method string, endpoint string, body str
 
 	request.Header = c.headers // 设置请求头
 
+	start := time.Now()
 	response, err := client.Do(request) // 发送请求
 	if err != nil {
+		metrics.SearchDuration.WithLabelValues(method, "error").Observe(metrics.Since(start))
 		return nil, err // 请求失败
 	}
+	metrics.SearchDuration.WithLabelValues(method, strconv.Itoa(response.StatusCode)).Observe(metrics.Since(start))
 
 	return response, nil
 }
 
+// Health 检查 Meilisearch 服务是否可用
+func (c *MeiliSearchClient) Health(ctx context.Context) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.uri+"/health", nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("状态码 %d", response.StatusCode)
+	}
+	return nil
+}
+
 // CreateIndex 创建索引
 func (c *MeiliSearchClient) CreateIndex(index string) error {
 	var endpoint = "indexes"
@@ -0,0 +1,457 @@
+package service
+
+import (
+	"blog/internal/backup"
+	"blog/internal/dto/requests"
+	"blog/internal/dto/response"
+	"blog/internal/job"
+	"blog/internal/migrate"
+	"blog/internal/models"
+	"blog/internal/utils"
+	"blog/pkg/background"
+	"blog/pkg/cache"
+	"blog/pkg/common"
+	"blog/pkg/configs"
+	"blog/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 备份状态
+const (
+	BackupRunning = "running"
+	BackupSuccess = "success"
+	BackupFailed  = "failed"
+)
+
+// BackupService 备份服务
+type BackupService struct {
+	cache  *BackupCache
+	users  *UserService
+	blogs  *BlogService
+	config configs.BackupConfig
+	remote backup.Target // 第二存储位置，未配置时为 nil
+}
+
+// CreateBackup 在后台备份上传目录、日志和数据库，返回备份ID，进度通过 GetProgress 查询；
+// 其他备份正在执行时进度中的状态为失败
+func (s *BackupService) CreateBackup(req requests.BackupRequest) (string, error) {
+	progress, opts, err := s.prepare(req)
+	if err != nil {
+		return "", err
+	}
+
+	background.Go(func() {
+		err := job.WithLock(common.BackupLock, func(ctx context.Context) error {
+			return s.execute(ctx, progress, opts)
+		})
+		if errors.Is(err, job.ErrRunning) {
+			s.finish(progress, errors.New("已有备份正在执行"))
+		}
+	})
+	return progress.ID, nil
+}
+
+// Backup 同步执行备份，完成后返回备份结果，用于命令行
+func (s *BackupService) Backup(req requests.BackupRequest) (*response.BackupProgressResponse, error) {
+	progress, opts, err := s.prepare(req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = job.WithLock(common.BackupLock, func(ctx context.Context) error {
+		return s.execute(ctx, progress, opts)
+	})
+	if errors.Is(err, job.ErrRunning) {
+		return nil, errors.New("已有备份正在执行")
+	}
+	return progress, err
+}
+
+// scheduledBackup 定时备份，任务名与备份任务锁相同，执行时已持有锁
+func (s *BackupService) scheduledBackup(ctx context.Context) error {
+	progress, opts, err := s.prepare(requests.BackupRequest{})
+	if err != nil {
+		return err
+	}
+	return s.execute(ctx, progress, opts)
+}
+
+// prepare 生成备份文件路径并保存初始进度
+func (s *BackupService) prepare(req requests.BackupRequest) (*response.BackupProgressResponse, backup.Options, error) {
+	opts := s.options(req)
+	if err := os.MkdirAll(s.config.GetDir(), os.ModePerm); err != nil {
+		return nil, opts, err
+	}
+
+	now := time.Now()
+	id := backup.Name(now)
+	opts.Path = filepath.Join(s.config.GetDir(), id+backup.Ext(opts.Compression))
+	if opts.Passphrase != "" {
+		opts.Path += backup.EncryptedExt
+	}
+
+	progress := &response.BackupProgressResponse{
+		ID:      id,
+		Name:    filepath.Base(opts.Path),
+		Status:  BackupRunning,
+		StartAt: now.UnixMilli(),
+	}
+	s.saveProgress(progress)
+	return progress, opts, nil
+}
+
+// execute 执行备份并记录进度，成功后复制到第二存储位置并按保留策略清理，调用方需持有备份任务锁
+func (s *BackupService) execute(ctx context.Context, progress *response.BackupProgressResponse, opts backup.Options) error {
+	opts.Progress = func(p backup.Progress) {
+		progress.Progress = p
+		s.saveProgress(progress)
+	}
+
+	logger.Info("开始备份", zap.String("path", opts.Path))
+	manifest, err := backup.Build(ctx, opts)
+	if err == nil {
+		if info, statErr := os.Stat(opts.Path); statErr == nil {
+			progress.Size = info.Size()
+		}
+		logger.Info("备份完成", zap.String("path", opts.Path), zap.Int("files", len(manifest.Files)), zap.Int64("size", progress.Size))
+	}
+	s.finish(progress, err)
+	if err != nil {
+		return err
+	}
+
+	// 复制和清理失败不影响本次备份的结果，但会记录到任务的执行记录中
+	return errors.Join(s.replicate(ctx, opts.Path), s.prune(ctx))
+}
+
+// replicate 复制备份文件到第二存储位置
+func (s *BackupService) replicate(ctx context.Context, path string) error {
+	if s.remote == nil {
+		return nil
+	}
+	if err := s.remote.Put(ctx, filepath.Base(path), path); err != nil {
+		logger.Error("复制备份失败", zap.String("path", path), zap.Error(err))
+		return fmt.Errorf("复制备份失败: %w", err)
+	}
+	logger.Info("复制备份完成", zap.String("path", path), zap.String("remote", s.config.Remote.Type))
+	return nil
+}
+
+// prune 按保留策略删除过期的备份，第二存储位置中的同名文件一并删除
+func (s *BackupService) prune(ctx context.Context) error {
+	list, err := s.GetBackups()
+	if err != nil {
+		return err
+	}
+	var names = make([]string, 0, len(list))
+	for _, item := range list {
+		names = append(names, item.Name)
+	}
+
+	var errs []error
+	for _, name := range backup.Expired(names, s.config.KeepDaily, s.config.KeepWeekly) {
+		if err := os.Remove(filepath.Join(s.config.GetDir(), name)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if s.remote != nil {
+			if err := s.remote.Delete(ctx, name); err != nil {
+				errs = append(errs, fmt.Errorf("删除第二存储位置的备份 %s 失败: %w", name, err))
+			}
+		}
+		logger.Info("删除过期备份", zap.String("name", name))
+	}
+	return errors.Join(errs...)
+}
+
+// Restore 校验备份文件并恢复数据库和文件，用于迁移到新实例或灾难恢复；
+// 目标数据库的表已有数据时需要 force 才会覆盖
+func (s *BackupService) Restore(ctx context.Context, path string, opts RestoreOptions) (*backup.RestoreResult, error) {
+	restore := backup.RestoreOptions{
+		Force:      opts.Force,
+		Seeded:     []string{models.UserTable, models.RoleTable},
+		Skip:       []string{migrate.Table},
+		Passphrase: s.passphrase(opts.Passphrase),
+	}
+	if opts.Database {
+		restore.DB = configs.DB
+	}
+	if opts.Files {
+		restore.Dirs = map[string]string{
+			"uploads": configs.CONFIG.Upload.Path,
+			"logs":    configs.CONFIG.Logger.LoggerDir,
+		}
+	}
+
+	var result *backup.RestoreResult
+	err := job.WithLock(common.BackupLock, func(ctx context.Context) error {
+		var err error
+		result, err = backup.Restore(ctx, path, restore)
+		return err
+	})
+	if errors.Is(err, job.ErrRunning) {
+		return nil, errors.New("备份正在执行，请稍后再恢复")
+	}
+	if err == nil && opts.Database {
+		if err := s.blogs.Reload(); err != nil {
+			logger.Error("恢复后刷新缓存和搜索索引失败", zap.Error(err))
+		}
+	}
+	return result, err
+}
+
+// RestoreOptions 恢复选项
+type RestoreOptions struct {
+	Database bool // 恢复数据库
+	Files    bool // 恢复上传文件和日志
+	Force    bool // 覆盖已有数据
+
+	Passphrase string // 加密备份的口令，为空时使用配置中的口令
+}
+
+// passphrase 优先使用传入的口令，否则使用配置中的口令
+func (s *BackupService) passphrase(passphrase string) string {
+	if passphrase != "" {
+		return passphrase
+	}
+	return s.config.GetPassphrase()
+}
+
+// Verify 校验备份文件的完整性，加密备份同时校验口令
+func (s *BackupService) Verify(path, passphrase string) (*backup.Manifest, error) {
+	return backup.VerifyFile(path, s.passphrase(passphrase))
+}
+
+// Decrypt 将加密备份解密为压缩归档，先写入临时文件，完整解密后再替换
+func (s *BackupService) Decrypt(path, output, passphrase string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	r, err := backup.NewDecryptReader(in, s.passphrase(passphrase))
+	if err != nil {
+		return err
+	}
+
+	tmp := output + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, r)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, output)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// Reauthenticate 下载备份或导出站点数据前重新验证当前账号的密码，通过后签发一次性下载令牌；
+// 失败次数与登录共用，超过阈值后锁定账号和IP
+func (s *BackupService) Reauthenticate(uid int, password, ip string) (string, error) {
+	if err := s.users.VerifyPassword(uid, password, ip); err != nil {
+		logger.Warn("下载前重新验证失败", zap.Int("uid", uid), zap.String("ip", ip), zap.Error(err))
+		return "", err
+	}
+
+	token := uuid.NewString()
+	if err := s.cache.SetDownloadToken(token, uid); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ConsumeDownloadToken 校验并作废下载令牌，令牌只能由签发时的账号使用一次
+func (s *BackupService) ConsumeDownloadToken(uid int, token string) bool {
+	if token == "" {
+		return false
+	}
+	owner, err := s.cache.TakeDownloadToken(token)
+	return err == nil && owner == uid
+}
+
+// finish 记录备份结果
+func (s *BackupService) finish(progress *response.BackupProgressResponse, err error) {
+	progress.EndAt = time.Now().UnixMilli()
+	progress.Status = BackupSuccess
+	if err != nil {
+		progress.Status = BackupFailed
+		progress.Error = err.Error()
+		logger.Error("备份失败", zap.String("id", progress.ID), zap.Error(err))
+	}
+	s.saveProgress(progress)
+}
+
+// options 合并请求与配置文件中的备份选项
+func (s *BackupService) options(req requests.BackupRequest) backup.Options {
+	opts := backup.Options{
+		Compression: s.config.Compression,
+		Include:     s.config.Include,
+		Exclude:     s.config.Exclude,
+		DB:          configs.DB,
+		Passphrase:  s.config.GetPassphrase(),
+		Sources: []backup.Source{
+			{Name: "uploads", Dir: configs.CONFIG.Upload.Path},
+			{Name: "logs", Dir: configs.CONFIG.Logger.LoggerDir},
+		},
+	}
+	if req.Compression != "" {
+		opts.Compression = req.Compression
+	}
+	if len(req.Include) > 0 {
+		opts.Include = req.Include
+	}
+	if len(req.Exclude) > 0 {
+		opts.Exclude = req.Exclude
+	}
+	if opts.Compression == "" {
+		opts.Compression = backup.Gzip
+	}
+	return opts
+}
+
+func (s *BackupService) saveProgress(progress *response.BackupProgressResponse) {
+	if err := s.cache.SetProgress(progress); err != nil {
+		logger.Error("保存备份进度失败", zap.String("id", progress.ID), zap.Error(err))
+	}
+}
+
+// GetProgress 获取备份进度，不存在时返回 nil
+func (s *BackupService) GetProgress(id string) (*response.BackupProgressResponse, error) {
+	return s.cache.GetProgress(id)
+}
+
+// GetBackups 获取备份目录中的备份文件，按时间倒序
+func (s *BackupService) GetBackups() ([]response.BackupFileResponse, error) {
+	entries, err := os.ReadDir(s.config.GetDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return []response.BackupFileResponse{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list = make([]response.BackupFileResponse, 0, len(entries))
+	for _, entry := range entries {
+		if _, ok := backup.ParseName(entry.Name()); !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		list = append(list, response.BackupFileResponse{
+			Name:      entry.Name(),
+			Size:      info.Size(),
+			CreatedAt: info.ModTime().UnixMilli(),
+		})
+	}
+	slices.SortFunc(list, func(a, b response.BackupFileResponse) int {
+		return strings.Compare(b.Name, a.Name)
+	})
+	return list, nil
+}
+
+// GetBackupPath 获取备份文件的本地路径，文件名不合法或不存在时返回错误
+func (s *BackupService) GetBackupPath(name string) (string, error) {
+	if _, ok := backup.ParseName(name); !ok || name != filepath.Base(name) {
+		return "", errors.New("备份文件名不合法")
+	}
+	path := filepath.Join(s.config.GetDir(), name)
+	if _, err := os.Stat(path); err != nil {
+		return "", errors.New("备份文件不存在")
+	}
+	return path, nil
+}
+
+// NewBackupService 创建备份服务实例
+func NewBackupService() *BackupService {
+	var service = &BackupService{
+		cache:  NewBackupCache(),
+		users:  NewUserService(),
+		blogs:  NewBlogService(),
+		config: configs.CONFIG.Backup,
+	}
+
+	switch remote := service.config.Remote; remote.Type {
+	case "dir":
+		service.remote = backup.DirTarget{Dir: remote.Dir}
+	case "webdav":
+		service.remote = backup.WebDAVTarget{URL: remote.Url, Username: remote.Username, Password: remote.Password}
+	case "":
+	default:
+		logger.Warn("不支持的备份存储类型，不复制备份", zap.String("type", remote.Type))
+	}
+
+	if configs.CONFIG.Server.Cron && service.config.Schedule != "" {
+		job.AddJob(job.Job{
+			Name:        common.BackupLock,
+			Spec:        service.config.Schedule,
+			Description: "定时备份",
+			Job:         service.scheduledBackup,
+		})
+	}
+
+	return service
+}
+
+// BackupCache 备份进度缓存
+type BackupCache struct {
+	client cache.Cache
+}
+
+// SetProgress 保存备份进度
+func (b *BackupCache) SetProgress(progress *response.BackupProgressResponse) error {
+	return b.client.Set(common.BackupProgressKey+progress.ID, utils.Serialize(progress), common.BackupProgressExpire)
+}
+
+// GetProgress 获取备份进度，不存在时返回 nil
+func (b *BackupCache) GetProgress(id string) (*response.BackupProgressResponse, error) {
+	str, err := b.client.Get(common.BackupProgressKey + id)
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	progress := utils.Deserialize[response.BackupProgressResponse](str)
+	return &progress, nil
+}
+
+// SetDownloadToken 保存下载令牌和签发的账号
+func (b *BackupCache) SetDownloadToken(token string, uid int) error {
+	return b.client.Set(common.BackupDownloadKey+token, strconv.Itoa(uid), common.BackupDownloadExpire)
+}
+
+// TakeDownloadToken 获取并删除下载令牌，返回签发的账号
+func (b *BackupCache) TakeDownloadToken(token string) (int, error) {
+	str, err := b.client.GetDel(common.BackupDownloadKey + token)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(str)
+}
+
+// NewBackupCache 创建备份进度缓存实例
+func NewBackupCache() *BackupCache {
+	return &BackupCache{client: cache.Observe(configs.CACHE, "backup")}
+}
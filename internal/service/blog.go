@@ -8,18 +8,21 @@ import (
 	"blog/internal/repository"
 	"blog/internal/search"
 	"blog/internal/utils"
+	"blog/pkg/background"
+	"blog/pkg/cache"
 	"blog/pkg/common"
 	"blog/pkg/configs"
 	"blog/pkg/logger"
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
 	"sync"
 	"time"
 
-	"github.com/go-redis/redis"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type BlogService struct {
@@ -27,21 +30,39 @@ type BlogService struct {
 	cache      *BlogCache
 	search     *search.MeiliSearchClient
 	index      string
+	loader     *cache.Loader
+	views      *ViewService
 }
 
 // CreateBlog 添加博客
 func (b *BlogService) CreateBlog(uid int, request requests.BlogRequest) (*models.Blog, error) {
+	return b.createBlog(uid, request.ToBlogModel(uid))
+}
+
+// ImportBlog 添加从其他博客系统导入的博客，保留原来的创建和更新时间，为0时使用当前时间
+func (b *BlogService) ImportBlog(uid int, request requests.BlogRequest, createdAt, updatedAt int64) (*models.Blog, error) {
 	blog := request.ToBlogModel(uid)
+	blog.CreatedAt = createdAt
+	blog.UpdatedAt = updatedAt
+	return b.createBlog(uid, blog)
+}
 
+// ExistsBlogTitle 判断用户是否已有同名博客
+func (b *BlogService) ExistsBlogTitle(uid int, title string) (bool, error) {
+	return b.repository.ExistsByTitle(uid, title)
+}
+
+func (b *BlogService) createBlog(uid int, blog models.Blog) (*models.Blog, error) {
 	if err := b.repository.CreateBlog(&blog); err != nil {
 		logger.Info("创建博客失败", zap.String("err", err.Error()))
 		return nil, err
 	}
 
-	go func() {
+	background.Go(func() {
 		b.updateCacheAndSearch(blog)
+		b.cache.DeleteByIds([]int64{blog.ID})
 		b.cache.ClearBlogKeys()
-	}()
+	})
 
 	logger.Info("博客创建成功", zap.Int("user_id", uid), zap.String("title", blog.Title))
 	return &blog, nil
@@ -57,10 +78,10 @@ func (b *BlogService) UpdateBlog(bid int64, uid int, super bool, request request
 		return nil, err
 	}
 
-	go func() {
+	background.Go(func() {
 		b.updateCacheAndSearch(blog)
 		b.cache.DeleteByIds([]int64{blog.ID})
-	}()
+	})
 
 	logger.Info("博客更新成功", zap.Int64("id", bid), zap.Int("user_id", uid))
 	return &blog, nil
@@ -94,10 +115,10 @@ func (b *BlogService) DeleteBlogByIDs(uid *int, ids []int64) error {
 		logger.Info("删除博客失败", zap.String("err", err.Error()))
 		return err
 	}
-	go func() {
+	background.Go(func() {
 		b.cache.DeleteByIds(ids)
 		b.cache.ClearBlogKeys()
-	}()
+	})
 
 	logger.Info("博客删除成功", zap.Int64s("ids", ids))
 	return nil
@@ -110,33 +131,50 @@ func (b *BlogService) UnDeleteBlogByIDs(uid *int, ids []int64) error {
 		return err
 	}
 
-	go b.cache.ClearBlogKeys()
+	background.Go(func() {
+		b.cache.DeleteByIds(ids)
+		b.cache.ClearBlogKeys()
+	})
 
 	logger.Info("博客恢复成功", zap.Int64s("ids", ids))
 	return nil
 }
 
-// GetBlogByID 根据 ID 获取博客
+// GetBlogByID 根据 ID 获取博客，博客不存在时返回 nil
 func (b *BlogService) GetBlogByID(id int64) (*models.Blog, error) {
-	blog, err := b.cache.GetBlogInfo(id)
-	if err == nil {
-		return blog, nil
-	}
+	blog, err := cache.Fetch(b.loader, cache.Source[*models.Blog]{
+		Key:     fmt.Sprintf("%s:%d", common.BlogMapKey, id),
+		Get:     func() (string, error) { return b.cache.GetBlogInfo(id) },
+		Set:     func(val string) error { return b.cache.SetBlogInfo(id, val) },
+		SetMiss: func(val string) error { return b.cache.SetBlogMiss(id, val) },
+		Load: func() (*models.Blog, error) {
+			blog, err := b.repository.GetBlogById(id)
+			if err == nil && (blog == nil || blog.ID == 0) {
+				return nil, cache.ErrMissing
+			}
+			return blog, err
+		},
+		SoftTTL: common.BlogInfoSoftExpire,
+		MissTTL: common.BlogMissExpire,
+	})
 
-	blog, err = b.repository.GetBlogById(id)
-	if err != nil || blog == nil || blog.ID == 0 {
+	if errors.Is(err, cache.ErrMissing) {
+		return nil, nil
+	}
+	if err != nil {
 		logger.Info("获取博客失败", zap.Int64("id", id), zap.String("err", err.Error()))
 		return nil, err
 	}
-
-	go b.cache.SetBlogInfo(id, blog)
 	return blog, nil
 }
 
-// GetBlogEyeCount 获取博客浏览次数
-func (b *BlogService) GetBlogEyeCount(count, id int64) int64 {
+// GetBlogEyeCount 获取博客浏览次数，同时记录当天的浏览量、独立访客和浏览来源
+func (b *BlogService) GetBlogEyeCount(count, id int64, visitor ViewVisitor) int64 {
 	var result = b.cache.GetBlogEyeCount(count, id)
-	go b.UpdateDailyTotalPv()
+	background.Go(func() {
+		b.UpdateDailyTotalPv()
+		b.views.RecordView(id, visitor)
+	})
 	return result
 }
 
@@ -161,7 +199,7 @@ func (b *BlogService) SaveRecommend(ids []int) error {
 		return err
 	}
 
-	go b.cache.SetRecommend(blogs)
+	background.Go(func() { b.cache.SetRecommend(blogs) })
 	logger.Info("保存推荐博客成功", zap.Ints("ids", ids))
 	return nil
 }
@@ -178,18 +216,25 @@ func (b *BlogService) GetRecommend() ([]response.SimpleBlogResponse, error) {
 
 // GetHotBlogs 获取热门博客
 func (b *BlogService) GetHotBlogs() ([]response.SimpleBlogResponse, error) {
-	blogs, err := b.cache.GetHotBlog()
+	blogs, err := cache.Fetch(b.loader, b.hotBlogSource())
 	if err != nil {
-		blogs, err = b.repository.GetHotBlog()
-		if err != nil {
-			logger.Info("获取热门博客失败", zap.String("err", err.Error()))
-			return nil, err
-		}
-		go b.cache.SetHotBlog(blogs)
+		logger.Info("获取热门博客失败", zap.String("err", err.Error()))
+		return nil, err
 	}
 	return blogs, nil
 }
 
+// hotBlogSource 热门博客的缓存与回源方式
+func (b *BlogService) hotBlogSource() cache.Source[[]response.SimpleBlogResponse] {
+	return cache.Source[[]response.SimpleBlogResponse]{
+		Key:     common.HotBlogKey,
+		Get:     b.cache.GetHotBlog,
+		Set:     b.cache.SetHotBlog,
+		Load:    b.repository.GetHotBlog,
+		SoftTTL: common.HotBlogSoftExpire,
+	}
+}
+
 // GetAdminBlogList 获取管理员博客列表
 func (b *BlogService) GetAdminBlogList(uid *int, req requests.AdminFilterRequest, page *response.Page) error {
 	list, err := b.repository.GetBlogAdminList(uid, req, &page.Count)
@@ -203,25 +248,83 @@ func (b *BlogService) GetAdminBlogList(uid *int, req requests.AdminFilterRequest
 
 // GetLatestBlogs 获取最新博客
 func (b *BlogService) GetLatestBlogs() ([]response.SimpleBlogResponse, error) {
-	blogs, err := b.cache.GetLatestBlog()
+	blogs, err := cache.Fetch(b.loader, cache.Source[[]response.SimpleBlogResponse]{
+		Key:     common.LatestBlogKey,
+		Get:     b.cache.GetLatestBlog,
+		Set:     b.cache.SetLatestBlog,
+		Load:    b.repository.GetLatestBlog,
+		SoftTTL: common.LatestBlogSoftExpire,
+	})
 	if err != nil {
-		blogs, err = b.repository.GetLatestBlog()
-		if err != nil {
-			logger.Info("获取最新博客失败", zap.String("err", err.Error()))
-			return nil, err
-		}
-		go b.cache.SetLatestBlog(blogs)
+		logger.Info("获取最新博客失败", zap.String("err", err.Error()))
+		return nil, err
 	}
 	return blogs, nil
 }
 
-// InitEyeCount 初始化博客浏览量
-func (b *BlogService) InitEyeCount() {
+// eyeCountJob 浏览量落库任务的名称，手动执行时与定时任务共用同一把任务锁
+const eyeCountJob = "flush_eye_count"
+
+// InitEyeCount 初始化博客浏览量，其他实例正在执行时返回 job.ErrRunning
+func (b *BlogService) InitEyeCount() error {
+	return job.WithLock(eyeCountJob, b.flushEyeCount)
+}
+
+// flushEyeCount 将缓存中的浏览量写入数据库，写入在比较任务锁令牌的事务中执行，提交后才清理计数
+func (b *BlogService) flushEyeCount(ctx context.Context) error {
+	// 每日浏览统计保存的是累计值，重复写入不会重复计数
+	if err := b.views.SyncDailyViews(); err != nil {
+		logger.Info("同步每日浏览统计失败", zap.String("err", err.Error()))
+	}
+
 	maps := b.cache.GetAllBlogEyeCount()
 	if len(maps) == 0 {
 		logger.Info("没有需要初始化的浏览量数据")
-		return
+		return nil
 	}
+
+	total, err := GetTodayTotalViews()
+	if err != nil {
+		return err
+	}
+	err = job.Transaction(ctx, func(tx *gorm.DB) error {
+		repository := b.repository.WithTx(tx)
+		for id, count := range maps {
+			idNumber, err := strconv.ParseInt(id, 10, 64)
+			if err != nil {
+				continue
+			}
+			countNumber, err := strconv.ParseInt(count, 10, 64)
+			if err != nil {
+				continue
+			}
+			if err := repository.UpdateEyeCount(idNumber, countNumber); err != nil {
+				return err
+			}
+		}
+		return AddEyeCount(tx, total)
+	})
+	if err != nil {
+		return err
+	}
+	logger.Info("初始化浏览量完成")
+
+	b.cache.DeletePvViewCount()
+	b.cache.DeleteBlogEyeCount()
+	return nil
+}
+
+// SaveViewCounts 将缓存中的浏览量和每日浏览统计写入数据库但不清理计数，服务关闭时调用，重复执行结果相同
+func (b *BlogService) SaveViewCounts(ctx context.Context) error {
+	if err := b.views.SyncDailyViews(); err != nil {
+		return err
+	}
+	b.saveEyeCount(b.cache.GetAllBlogEyeCount())
+	return nil
+}
+
+// saveEyeCount 并发将各博客的累计浏览量写入数据库
+func (b *BlogService) saveEyeCount(maps map[string]string) {
 	var (
 		wg            sync.WaitGroup
 		concurrentSem = make(chan struct{}, 10) // 控制最大并发数，例如 10
@@ -230,7 +333,7 @@ func (b *BlogService) InitEyeCount() {
 		wg.Add(1)
 		// 使用带缓冲的通道限制 Goroutine 并发数
 		concurrentSem <- struct{}{}
-		go func(id, count string) {
+		background.Go(func() {
 			defer wg.Done()
 			defer func() { <-concurrentSem }() // 释放通道占用
 			// 转换 ID 和 count，并处理可能的错误
@@ -244,41 +347,38 @@ func (b *BlogService) InitEyeCount() {
 			}
 			// 更新数据库中的浏览量
 			b.repository.UpdateEyeCount(idNumber, countNumber)
-		}(id, count)
+		})
 	}
 	wg.Wait()
-	logger.Info("初始化浏览量完成")
-	var count, err = GetTodayTotalViews()
-	if err == nil {
-		err = AddEyeCount(count)
-		if err == nil {
-			b.cache.DeletePvViewCount()
-		}
-
-	}
-	b.cache.DeleteBlogEyeCount()
 }
 
 // GetBlogList 获取博客列表
 func (b *BlogService) GetBlogList(prequest requests.RequestQuery, page *response.Page) error {
-	pageInfo, err := b.cache.GetPageInfo(prequest)
-
-	if err == nil {
-		*page = *pageInfo
-		return nil
+	key, err := b.cache.PageInfoKey(prequest)
+	if err != nil {
+		return err
 	}
 
-	list, err := b.repository.GetBlogList(prequest, &page.Count)
+	var base = *page
+	pageInfo, err := cache.Fetch(b.loader, cache.Source[response.Page]{
+		Key: key,
+		Get: func() (string, error) { return b.cache.GetPageInfo(key) },
+		Set: func(val string) error { return b.cache.SetPageInfo(key, val) },
+		Load: func() (response.Page, error) {
+			var result = base
+			list, err := b.repository.GetBlogList(prequest, &result.Count)
+			result.Data = list
+			return result, err
+		},
+		SoftTTL: common.PageInfoSoftExpire,
+	})
 
 	if err != nil {
 		logger.Info("获取博客列表失败", zap.String("err", err.Error()))
 		return err
 	}
 
-	page.Data = list
-	go func() {
-		b.cache.SetPageInfo(prequest, page)
-	}()
+	*page = pageInfo
 	return nil
 }
 
@@ -331,7 +431,7 @@ func (b *BlogService) SetPinnedBlog(req requests.PinnedBlogRequest) error {
 		return err
 	}
 
-	go b.cache.ClearPinnedKey()
+	background.Go(func() { b.cache.ClearPinnedKey() })
 
 	return nil
 }
@@ -380,13 +480,30 @@ func (b *BlogService) SearchBlog(req requests.SearchBlogRequest) response.Page {
 	}
 }
 
-func (b *BlogService) initHotBlog() {
-	b.cache.ClearHotBlog()
-	b.cache.GetHotBlog()
+func (b *BlogService) initHotBlog(ctx context.Context) error {
+	if err := job.Check(ctx); err != nil {
+		return err
+	}
+	_, err := cache.Refresh(b.loader, b.hotBlogSource())
+	return err
+}
+
+// Reload 博客数据被整体替换（恢复备份、导入站点数据）后清空博客、分类、标签和专题的缓存并重建搜索索引
+func (b *BlogService) Reload() error {
+	return errors.Join(
+		b.cache.ClearAll(),
+		NewCategoryCache().ClearCategoryKeys(),
+		NewTagCache().ClearTagKeys(),
+		NewTopicCache().ClearTopicKeys(),
+		b.InitSearch(),
+	)
 }
 
-func (b *BlogService) initSearch() {
-	b.InitSearch()
+func (b *BlogService) initSearch(ctx context.Context) error {
+	if err := job.Check(ctx); err != nil {
+		return err
+	}
+	return b.InitSearch()
 }
 
 // getBlogSearchRequest 构建博客搜索请求
@@ -409,120 +526,128 @@ func NewBlogService() *BlogService {
 		cache:      NewBlogCache(),
 		search:     configs.SEARCH,
 		index:      configs.CONFIG.Search.BlogIndex,
+		loader:     cache.NewLoader(),
+		views:      NewViewService(),
 	}
 
 	if configs.CONFIG.Server.Cron {
 		job.AddJob(job.Job{
-			Hour:        0,
-			Eq:          true,
+			Name:        eyeCountJob,
+			Spec:        "0 0 * * *",
 			Description: "初始化浏览量",
-			Job:         service.InitEyeCount,
+			Job:         service.flushEyeCount,
 		})
 
 		job.AddJob(job.Job{
-			Hour:        1,
-			Eq:          true,
+			Name:        "init_search",
+			Spec:        "0 1 * * *",
 			Description: "初始化搜索",
 			Job:         service.initSearch,
 		})
 
 		job.AddJob(job.Job{
-			Hour:        6,
-			Eq:          false,
+			Name:        "hot_blog",
+			Spec:        "0 */6 * * *",
 			Description: "更新热门博客",
 			Job:         service.initHotBlog,
 		})
 	}
 
+	// 关闭时保存缓存中尚未落库的浏览量
+	background.OnShutdown("save_view_counts", service.SaveViewCounts)
+
 	return service
 }
 
 type BlogCache struct {
-	redis *redis.Client
+	client cache.Cache
+	pages  *cache.Namespace // 博客列表分页的命名空间
 }
 
 // SetBlogInfo 缓存博客详情信息
-func (b *BlogCache) SetBlogInfo(id int64, blog *models.Blog) error {
-	var str string
-	if blog != nil {
-		str = utils.Serialize(blog)
-	}
-	return b.redis.HSet(common.BlogMapKey, strconv.FormatInt(id, 10), str).Err()
+func (b *BlogCache) SetBlogInfo(id int64, val string) error {
+	return b.client.HSet(common.BlogMapKey, strconv.FormatInt(id, 10), val)
 }
 
-// DeleteByIds 删除缓存中的博客
+// DeleteByIds 删除缓存中的博客以及对应的负缓存
 func (b *BlogCache) DeleteByIds(ids []int64) error {
 	fields := make([]string, len(ids))
+	misses := make([]string, len(ids))
 	for i, id := range ids {
 		fields[i] = strconv.FormatInt(id, 10)
+		misses[i] = common.BlogMissKey + fields[i]
+	}
+	if err := b.client.Del(misses...); err != nil {
+		return err
 	}
-	return b.redis.HDel(common.BlogMapKey, fields...).Err()
+	return b.client.HDel(common.BlogMapKey, fields...)
 }
 
 // GetAllBlogEyeCount 获取所有的浏览量
 func (b *BlogCache) GetAllBlogEyeCount() map[string]string {
-	return b.redis.HGetAll(common.BlogEyeCountMapKey).Val()
+	counts, _ := b.client.HGetAll(common.BlogEyeCountMapKey)
+	return counts
 }
 
 // GetBlogInfo 获取博客详情信息
-func (b *BlogCache) GetBlogInfo(id int64) (*models.Blog, error) {
-	r := b.redis.HGet(common.BlogMapKey, strconv.FormatInt(id, 10))
-	if r.Err() != nil {
-		return nil, errors.New("获取博客信息失败")
+func (b *BlogCache) GetBlogInfo(id int64) (string, error) {
+	val, err := b.client.HGet(common.BlogMapKey, strconv.FormatInt(id, 10))
+	if errors.Is(err, cache.ErrNotFound) {
+		return b.client.Get(common.BlogMissKey + strconv.FormatInt(id, 10))
 	}
-	return utils.Deserialize[*models.Blog](r.Val()), nil
+	return val, err
+}
+
+// SetBlogMiss 缓存不存在的博客ID，哈希表的字段不会过期，负缓存使用独立的键
+func (b *BlogCache) SetBlogMiss(id int64, val string) error {
+	field := strconv.FormatInt(id, 10)
+	if err := b.client.HDel(common.BlogMapKey, field); err != nil {
+		return err
+	}
+	return b.client.Set(common.BlogMissKey+field, val, common.BlogMissExpire)
 }
 
 // SetRecommend 缓存推荐博客
 func (b *BlogCache) SetRecommend(blogs []response.SimpleBlogResponse) error {
 	blogsJson := utils.Serialize(blogs)
-	return b.redis.Set(common.RecommendKey, blogsJson, -1).Err()
+	return b.client.Set(common.RecommendKey, blogsJson, 0)
 }
 
 // DeleteBlogEyeCount 删除浏览量
 func (b *BlogCache) DeleteBlogEyeCount() error {
-	return b.redis.Del(common.BlogEyeCountMapKey).Err()
+	return b.client.Del(common.BlogEyeCountMapKey)
 }
 
 func (b *BlogCache) DeletePvViewCount() error {
-	return b.redis.Del(common.EyeView).Err()
+	return b.client.Del(common.EyeView)
 }
 
 // GetRecommend 从缓存获取推荐博客
 func (b *BlogCache) GetRecommend() ([]response.SimpleBlogResponse, error) {
-	str := b.redis.Get(common.RecommendKey).Val()
+	str, _ := b.client.Get(common.RecommendKey)
 	if str == "" {
 		return nil, errors.New("推荐博客未找到")
 	}
 	return utils.Deserialize[[]response.SimpleBlogResponse](str), nil
 }
 
-// getSimpleBlog 获取简单博客列表缓存
-func (b *BlogCache) getSimpleBlog(key string) ([]response.SimpleBlogResponse, error) {
-	str := b.redis.Get(key)
-	if str.Err() != nil {
-		return nil, errors.New("获取失败")
-	}
-	return utils.Deserialize[[]response.SimpleBlogResponse](str.Val()), nil
-}
-
 // SetLatestBlog 缓存最新的10条博客
-func (b *BlogCache) SetLatestBlog(blogs []response.SimpleBlogResponse) error {
-	return b.setSimpleBlog(common.LatestBlogKey, common.LatestBlogExpire, blogs)
+func (b *BlogCache) SetLatestBlog(val string) error {
+	return b.client.Set(common.LatestBlogKey, val, common.LatestBlogExpire)
 }
 
 // GetLatestBlog 从缓存获取最新博客
-func (b *BlogCache) GetLatestBlog() ([]response.SimpleBlogResponse, error) {
-	return b.getSimpleBlog(common.LatestBlogKey)
+func (b *BlogCache) GetLatestBlog() (string, error) {
+	return b.client.Get(common.LatestBlogKey)
 }
 
 func (b *BlogCache) SetPinnedBlog(blogs []response.BlogResponse) error {
 	blogsJson := utils.Serialize(blogs)
-	return b.redis.Set(common.PinnedBlog, blogsJson, -1).Err()
+	return b.client.Set(common.PinnedBlog, blogsJson, 0)
 }
 
 func (b *BlogCache) GetPinnedBlog() ([]response.BlogResponse, error) {
-	var jsonStr, err = b.redis.Get(common.PinnedBlog).Result()
+	var jsonStr, err = b.client.Get(common.PinnedBlog)
 	if err != nil {
 		return nil, err
 	}
@@ -530,64 +655,80 @@ func (b *BlogCache) GetPinnedBlog() ([]response.BlogResponse, error) {
 }
 
 // GetHotBlog 从缓存获取热门博客
-func (b *BlogCache) GetHotBlog() ([]response.SimpleBlogResponse, error) {
-	return b.getSimpleBlog(common.HotBlogKey)
-}
-
-func (b *BlogCache) ClearHotBlog() error {
-	return b.redis.Del(common.HotBlogKey).Err()
+func (b *BlogCache) GetHotBlog() (string, error) {
+	return b.client.Get(common.HotBlogKey)
 }
 
 // SetHotBlog 缓存热门的10条博客
-func (b *BlogCache) SetHotBlog(blogs []response.SimpleBlogResponse) error {
-	return b.setSimpleBlog(common.HotBlogKey, common.HotBlogExpire, blogs)
-}
-
-// setSimpleBlog 设置简单博客列表缓存
-func (b *BlogCache) setSimpleBlog(key string, expire time.Duration, blogs []response.SimpleBlogResponse) error {
-	blogsJson := utils.Serialize(blogs)
-	return b.redis.Set(key, blogsJson, expire).Err()
+func (b *BlogCache) SetHotBlog(val string) error {
+	return b.client.Set(common.HotBlogKey, val, common.HotBlogExpire)
 }
 
 // ClearBlogPageInfo 清空所有博客页面缓存
 func (b *BlogCache) ClearBlogPageInfo() error {
-	keys := b.redis.Keys(common.PageInfoPrefixKey + "*").Val()
-	return b.redis.Del(keys...).Err()
+	return b.pages.Invalidate()
+}
+
+// PageInfoKey 博客列表在当前命名空间版本下的key
+func (b *BlogCache) PageInfoKey(req requests.RequestQuery) (string, error) {
+	return b.pages.Key(fmt.Sprintf("page_%d_cid_%d_sort:%s", req.Page, req.Cid, req.Sort))
 }
 
 // SetPageInfo 将博客列表存入redis
-func (b *BlogCache) SetPageInfo(req requests.RequestQuery, pageInfo *response.Page) error {
-	key := fmt.Sprintf("%s:page_%d_cid_%d_sort:%s", common.PageInfoPrefixKey, req.Page, req.Cid, req.Sort)
-	str := utils.Serialize(pageInfo)
-	return b.redis.Set(key, str, common.PageInfoExpire).Err()
+func (b *BlogCache) SetPageInfo(key, val string) error {
+	return b.client.Set(key, val, common.PageInfoExpire)
 }
 
 // GetPageInfo 从redis获取博客列表
-func (b *BlogCache) GetPageInfo(req requests.RequestQuery) (*response.Page, error) {
-	key := fmt.Sprintf("%s:page_%d_cid_%d_sort:%s", common.PageInfoPrefixKey, req.Page, req.Cid, req.Sort)
-	str := b.redis.Get(key).Val()
-	if str == "" {
-		return nil, errors.New("not found")
-	}
-	return utils.Deserialize[*response.Page](str), nil
+func (b *BlogCache) GetPageInfo(key string) (string, error) {
+	return b.client.Get(key)
 }
 
 func (b *BlogCache) ClearPinnedKey() error {
-	return b.redis.Del(common.PinnedBlog).Err()
+	return b.client.Del(common.PinnedBlog)
 }
 
 func (b *BlogCache) ClearBlogKeys() error {
-	var pages = b.redis.Keys(common.PageInfoPrefixKey + "*").Val()
-	return b.redis.Del(append(pages, common.BlogMapKey, common.HotBlogKey, common.LatestBlogKey)...).Err()
+	if err := b.pages.Invalidate(); err != nil {
+		return err
+	}
+	return b.client.Del(common.BlogMapKey, common.HotBlogKey, common.LatestBlogKey)
+}
+
+// ClearAll 清除所有博客缓存，包括负缓存和缓存中的浏览量，避免旧数据覆盖替换后的数据
+func (b *BlogCache) ClearAll() error {
+	if err := b.ClearBlogKeys(); err != nil {
+		return err
+	}
+	if err := b.client.Del(common.PinnedBlog, common.RecommendKey, common.BlogEyeCountMapKey); err != nil {
+		return err
+	}
+
+	var cursor uint64
+	for {
+		keys, next, err := b.client.Scan(cursor, common.BlogMissKey+"*", common.ScanBatchCount)
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := b.client.Del(keys...); err != nil {
+				return err
+			}
+		}
+		if cursor = next; cursor == 0 {
+			return nil
+		}
+	}
 }
 
 func (b *BlogCache) SetTempBlog(id string, time time.Duration, blog requests.TmpBlog) error {
 	str := utils.Serialize(blog)
-	return b.redis.SetNX(common.TmpBlogKey+id, str, time).Err()
+	_, err := b.client.SetNX(common.TmpBlogKey+id, str, time)
+	return err
 }
 
 func (b *BlogCache) GetTempBlog(id string) (*requests.TmpBlog, error) {
-	str, err := b.redis.Get(common.TmpBlogKey + id).Result()
+	str, err := b.client.Get(common.TmpBlogKey + id)
 	if err != nil {
 		return nil, err
 	}
@@ -598,21 +739,14 @@ func (b *BlogCache) GetTempBlog(id string) (*requests.TmpBlog, error) {
 // IncrementDailyPv 更新当天的全局浏览量
 func (c *BlogCache) IncrementDailyPv() error {
 	// Redis 原子操作增加计数
-	return c.redis.Incr(common.EyeView).Err()
+	_, err := c.client.Incr(common.EyeView, 0)
+	return err
 }
 
 // GetBlogEyeCount 获取博客浏览次数
 func (b *BlogCache) GetBlogEyeCount(defaultCount int64, id int64) int64 {
 	field := strconv.FormatInt(id, 10)
-	luaScript := `
-		local count = redis.call("HINCRBY", KEYS[1], ARGV[1], 1)
-		if count == 1 then
-			redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
-			return ARGV[2]
-		end
-		return count
-	`
-	count, err := b.redis.Eval(luaScript, []string{common.BlogEyeCountMapKey}, field, defaultCount+1).Int64()
+	count, err := b.client.HIncrOrInit(common.BlogEyeCountMapKey, field, defaultCount+1)
 	if err != nil {
 		logger.Info("获取博客浏览次数失败", zap.String("err", err.Error()))
 		return defaultCount
@@ -622,5 +756,8 @@ func (b *BlogCache) GetBlogEyeCount(defaultCount int64, id int64) int64 {
 
 // NewBlogCache 创建新的 BlogCache 实例
 func NewBlogCache() *BlogCache {
-	return &BlogCache{redis: configs.REDIS}
+	return &BlogCache{
+		client: cache.Observe(configs.CACHE, "blog"),
+		pages:  cache.NewNamespace(configs.CACHE, common.PageInfoPrefixKey),
+	}
 }
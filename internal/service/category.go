@@ -6,11 +6,12 @@ import (
 	"blog/internal/models"
 	"blog/internal/repository"
 	"blog/internal/utils"
+	"blog/pkg/background"
+	"blog/pkg/cache"
 	"blog/pkg/common"
 	"blog/pkg/configs"
 	"blog/pkg/logger"
 
-	"github.com/go-redis/redis"
 	"go.uber.org/zap"
 )
 
@@ -33,17 +34,29 @@ func (c *CategoryService) SaveCategory(name string) error {
 		return err
 	}
 
-	go c.clearCategoryCache("保存分类")
+	background.Go(func() { c.clearCategoryCache("保存分类") })
 	return nil
 }
 
+// EnsureCategory 按名称获取分类ID，不存在时自动创建
+func (c *CategoryService) EnsureCategory(name string) (int, error) {
+	category, changed, err := c.repository.FindOrCreateCategory(name)
+	if err != nil {
+		return 0, err
+	}
+	if changed {
+		background.Go(func() { c.clearCategoryCache("创建分类") })
+	}
+	return category.ID, nil
+}
+
 // UpdateCategory 更新分类
 func (c *CategoryService) UpdateCategory(req requests.CategoryRequest) error {
 	if err := c.repository.UpdateCategory(req.ID, req.Name); err != nil {
 		return err
 	}
 
-	go c.clearCategoryCache("更新分类")
+	background.Go(func() { c.clearCategoryCache("更新分类") })
 	return nil
 }
 
@@ -53,12 +66,12 @@ func (c *CategoryService) DeleteByIDs(ids []int64) error {
 		return err
 	}
 
-	go func() {
+	background.Go(func() {
 		if err := c.repository.DeleteCategoryBlogs(ids); err != nil {
 			logger.Info("删除分类博客失败", zap.String("error", err.Error()))
 		}
 		c.clearCategoryCache("删除分类")
-	}()
+	})
 
 	return nil
 }
@@ -69,12 +82,12 @@ func (c *CategoryService) UnDeleteByIDs(ids []int64) error {
 		return err
 	}
 
-	go func() {
+	background.Go(func() {
 		if err := c.repository.UndeleteCategoryBlogs(ids); err != nil {
 			logger.Info("恢复分类博客失败", zap.String("error", err.Error()))
 		}
 		c.clearCategoryCache("恢复分类")
-	}()
+	})
 
 	return nil
 }
@@ -85,11 +98,11 @@ func (c *CategoryService) GetCategoryList() []response.SimpleCategoryResponse {
 	if err != nil {
 		categories = c.repository.GetCategoryList()
 		logger.Info("缓存分类列表")
-		go func() {
+		background.Go(func() {
 			if err := c.cache.SetCategoryList(categories); err != nil {
 				logger.Info("设置分类列表缓存失败", zap.String("error", err.Error()))
 			}
-		}()
+		})
 	}
 	return categories
 }
@@ -111,24 +124,27 @@ func NewCategoryService() *CategoryService {
 
 // CategoryCache 分类缓存
 type CategoryCache struct {
-	redis *redis.Client
+	client cache.Cache
+	pages  *cache.Namespace // 博客列表分页的命名空间
 }
 
 // SetCategoryList 缓存分类列表
 func (c *CategoryCache) SetCategoryList(categories []response.SimpleCategoryResponse) error {
 	str := utils.Serialize(categories)
-	return c.redis.Set(common.CategoryListKey, str, common.CategoryListExpire).Err()
+	return c.client.Set(common.CategoryListKey, str, common.CategoryListExpire)
 }
 
 // ClearCategoryKeys 清除分类相关的缓存
 func (c *CategoryCache) ClearCategoryKeys() error {
-	pages := c.redis.Keys(common.PageInfoPrefixKey + "*").Val()
-	return c.redis.Del(append(pages, common.BlogMapKey, common.CategoryListKey)...).Err()
+	if err := c.pages.Invalidate(); err != nil {
+		return err
+	}
+	return c.client.Del(common.BlogMapKey, common.CategoryListKey)
 }
 
 // GetCategoryList 从缓存获取分类列表
 func (c *CategoryCache) GetCategoryList() ([]response.SimpleCategoryResponse, error) {
-	r, err := c.redis.Get(common.CategoryListKey).Result()
+	r, err := c.client.Get(common.CategoryListKey)
 	if err != nil {
 		return nil, err
 	}
@@ -137,5 +153,8 @@ func (c *CategoryCache) GetCategoryList() ([]response.SimpleCategoryResponse, er
 
 // NewCategoryCache 创建新的 CategoryCache 实例
 func NewCategoryCache() *CategoryCache {
-	return &CategoryCache{redis: configs.REDIS}
+	return &CategoryCache{
+		client: cache.Observe(configs.CACHE, "category"),
+		pages:  cache.NewNamespace(configs.CACHE, common.PageInfoPrefixKey),
+	}
 }
@@ -3,49 +3,49 @@ package service
 import (
 	"blog/internal/dto/response"
 	"blog/internal/models"
+	"blog/pkg/cache"
 	"blog/pkg/common"
 	"blog/pkg/configs"
+	"errors"
 	"fmt"
 	"runtime"
+	"strconv"
 	"time"
 
-	"github.com/go-redis/redis"
 	"github.com/shirou/gopsutil/cpu"
 	"github.com/shirou/gopsutil/host"
 	"github.com/shirou/gopsutil/mem"
 	"gorm.io/gorm"
 )
 
-// AddEyeCount 增加访问量记录
-func AddEyeCount(count int64) error {
+// AddEyeCount 保存今日访问量记录，db 可以是事务
+func AddEyeCount(db *gorm.DB, count int64) error {
 	today := time.Now().Format("2006-01-02") // 格式化为 "YYYY-MM-DD"
 
-	var db = configs.DB
-
 	var eyeView models.EyeView
 	result := db.First(&eyeView, "id = ?", today)
 
 	if result.Error != nil {
+		if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return result.Error
+		}
 		// 如果找不到记录，创建新记录
 		eyeView = models.EyeView{
 			ID:    today,
 			Count: count,
 		}
-		db.Create(&eyeView)
-	} else {
-		eyeView.Count = count
-		db.Save(&eyeView)
+		return db.Create(&eyeView).Error
 	}
-	return nil
+	eyeView.Count = count
+	return db.Save(&eyeView).Error
 }
 
 // GetTodayTotalViews 计算今日所有博客的访问量总和
 func GetTodayTotalViews() (int64, error) {
-	var rdb = configs.REDIS
-	// 从 Redis 获取当天总访问量
-	totalViews, err := rdb.Get(common.EyeView).Int64()
+	// 从缓存获取当天总访问量
+	str, err := configs.CACHE.Get(common.EyeView)
 	if err != nil {
-		if err == redis.Nil {
+		if errors.Is(err, cache.ErrNotFound) {
 			// 如果键不存在，说明今天还没有访问量，返回 0
 			return 0, nil
 		}
@@ -53,7 +53,7 @@ func GetTodayTotalViews() (int64, error) {
 		return 0, err
 	}
 
-	return totalViews, nil
+	return strconv.ParseInt(str, 10, 64)
 }
 
 // func GetTodayTotalViews() (int64, error) {
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"blog/internal/dto/response"
 	"blog/internal/models"
 	"blog/pkg/configs"
 	"blog/pkg/logger"
@@ -16,9 +17,6 @@ import (
 
 // DataBaseService 定义数据库服务接口
 type DataBaseService interface {
-	// 执行数据库SQL
-	ExecDataBaseSQL(sql string) int64
-
 	// 获取各种表的插入SQL
 	GetBlogInsertSQL(page int) []string
 	GetTagInsertSQL(page int) []string
@@ -30,7 +28,12 @@ type DataBaseService interface {
 	GetFileMd5InsertSQL(page int) []string
 	GetBlogTagInsertSQL(page int) []string
 
-	// 通用的获取插入SQL方法
+	// 获取当前 schema 下的表、表结构，以及判断表是否存在
+	GetTables() ([]response.TableResponse, error)
+	GetTable(name string) (*response.TableDetailResponse, error)
+	HasTable(name string) bool
+
+	// 通用的获取插入SQL方法，生成的SQL只适用于 PostgreSQL，迁移到其他站点请使用 TransferService 导出
 	GetInsertSQL(tableName string, page int) []string
 }
 
@@ -94,16 +97,6 @@ type DataBaseServiceImpl struct {
 	db         *gorm.DB
 }
 
-// ExecDataBaseSQL 执行数据库 SQL 语句
-func (d *DataBaseServiceImpl) ExecDataBaseSQL(sql string) int64 {
-	result := d.db.Exec(sql)
-	if result.Error != nil {
-		logger.Error("执行 SQL 失败", zap.Error(result.Error))
-		return 0
-	}
-	return result.RowsAffected
-}
-
 // getGlobalResult 获取全局查询结果并生成插入 SQL
 func (d *DataBaseServiceImpl) getGlobalResult(tableName string, page int) []string {
 	var records []map[string]interface{}
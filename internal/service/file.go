@@ -7,9 +7,11 @@ import (
 	"blog/internal/repository"
 	"blog/internal/store"
 	"blog/internal/utils"
+	"blog/pkg/background"
 	"blog/pkg/common"
 	"blog/pkg/configs"
 	"blog/pkg/logger"
+	"blog/pkg/metrics"
 	"fmt"
 	"mime/multipart"
 	"os"
@@ -50,7 +52,7 @@ func (f *FileService) SaveFile(form *multipart.Form, uid *int, isPub, isImg bool
 
 	for _, file := range form.File["files"] {
 		wg.Add(1)
-		go func(file *multipart.FileHeader) {
+		background.Go(func() {
 			defer wg.Done()
 			if file.Size > int64(f.config.MaxFileSize)*mb {
 				logger.Info("文件大小超过限制", zap.String("filename", file.Filename))
@@ -59,7 +61,7 @@ func (f *FileService) SaveFile(form *multipart.Form, uid *int, isPub, isImg bool
 			if err := f.processFile(file, uid, isPub, isImg, &fileMap); err != nil {
 				logger.Info("处理文件时出错", zap.String("error", err.Error()))
 			}
-		}(file)
+		})
 	}
 
 	wg.Wait()
@@ -83,6 +85,23 @@ func (f *FileService) SaveFile(form *multipart.Form, uid *int, isPub, isImg bool
 	return simpleList
 }
 
+// SaveImage 保存内存中的图片，与上传图片相同地选择存储位置并记录文件信息
+func (f *FileService) SaveImage(filename string, data []byte, uid *int) (response.SimpleFileResponse, error) {
+	if len(data) > f.config.MaxFileSize*mb {
+		return response.SimpleFileResponse{}, fmt.Errorf("文件大小超过限制: %s", filename)
+	}
+	header, err := utils.NewFileHeader("files", filename, data)
+	if err != nil {
+		return response.SimpleFileResponse{}, err
+	}
+
+	list := f.SaveFile(&multipart.Form{File: map[string][]*multipart.FileHeader{"files": {header}}}, uid, true, true)
+	if len(list) == 0 {
+		return response.SimpleFileResponse{}, fmt.Errorf("保存图片失败: %s", filename)
+	}
+	return list[0], nil
+}
+
 // processFile 处理单个文件的上传逻辑
 func (f *FileService) processFile(file *multipart.FileHeader, userId *int, isPub, isImg bool, fileMap *sync.Map) error {
 	md5Value := utils.CalculateMD5(file)
@@ -95,6 +114,7 @@ func (f *FileService) processFile(file *multipart.FileHeader, userId *int, isPub
 	url := f.repository.FindByMd5(md5Value)
 	var path string
 	if url == "" {
+		backend := "local"
 		dstPath := filepath.Join(f.config.Path, newFileName)
 		if isImg {
 			if image, err := store.UploadImageToVeyme(file, f.config.VeymeToken); err != nil {
@@ -107,6 +127,7 @@ func (f *FileService) processFile(file *multipart.FileHeader, userId *int, isPub
 			} else {
 				path = image.Url
 				url = image.Image
+				backend = "veyme"
 				logger.Info("Veyme上传图片成功")
 			}
 		} else if f.config.Store == "github" {
@@ -118,6 +139,7 @@ func (f *FileService) processFile(file *multipart.FileHeader, userId *int, isPub
 			}
 			logger.Info("Github上传文件成功")
 			path = name
+			backend = "github"
 		} else {
 			if err := store.SaveToFile(file, dstPath); err != nil {
 				return err
@@ -134,6 +156,7 @@ func (f *FileService) processFile(file *multipart.FileHeader, userId *int, isPub
 		if err := f.repository.SaveFileMd5(&newMd5Info); err != nil {
 			return err
 		}
+		metrics.UploadBytes.WithLabelValues(backend).Add(float64(file.Size))
 	}
 
 	newFile := models.FileInfo{
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"blog/internal/dto/response"
+	"blog/pkg/background"
+	"blog/pkg/common"
+	"blog/pkg/configs"
+	"blog/pkg/logger"
+	"blog/pkg/smail"
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// startTime 服务启动时间
+var startTime = time.Now()
+
+// dependency 需要探测的依赖，probe 为 nil 表示未启用
+type dependency struct {
+	name     string
+	critical bool
+	probe    func(ctx context.Context) error
+}
+
+// HealthService 存活与就绪检查服务
+type HealthService struct {
+	timeout time.Duration
+
+	mu        sync.Mutex
+	last      response.HealthResponse // 最近一次就绪检查的结果
+	checkedAt time.Time               // 最近一次就绪检查的时间
+}
+
+// Liveness 存活检查，只表示进程可以处理请求，不探测依赖
+func (h *HealthService) Liveness() response.HealthResponse {
+	return response.HealthResponse{
+		Status: response.HealthUp,
+		Ready:  true,
+		Uptime: int64(time.Since(startTime).Seconds()),
+	}
+}
+
+// Readiness 就绪检查，服务开始关闭后直接返回未就绪，否则返回缓存时间内最近一次的探测结果
+func (h *HealthService) Readiness(ctx context.Context) response.HealthResponse {
+	if background.Draining() {
+		return response.HealthResponse{
+			Status: response.HealthDown,
+			Ready:  false,
+			Uptime: int64(time.Since(startTime).Seconds()),
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if time.Since(h.checkedAt) >= common.HealthCacheExpire {
+		// 结果会共享给其他请求，不跟随当前请求取消
+		h.last = h.probe(context.WithoutCancel(ctx))
+		h.checkedAt = time.Now()
+	}
+
+	resp := h.last
+	resp.Uptime = int64(time.Since(startTime).Seconds())
+	return resp
+}
+
+// probe 并发探测所有依赖，关键依赖全部可用时才算就绪
+func (h *HealthService) probe(ctx context.Context) response.HealthResponse {
+	deps := h.dependencies()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results = make(map[string]response.DependencyHealth, len(deps))
+	)
+	for _, dep := range deps {
+		wg.Add(1)
+		go func(dep dependency) {
+			defer wg.Done()
+			result := h.check(ctx, dep)
+			mu.Lock()
+			results[dep.name] = result
+			mu.Unlock()
+		}(dep)
+	}
+	wg.Wait()
+
+	resp := response.HealthResponse{
+		Status:       response.HealthUp,
+		Ready:        true,
+		Uptime:       int64(time.Since(startTime).Seconds()),
+		Dependencies: results,
+	}
+	for _, result := range results {
+		if result.Status != response.HealthDown {
+			continue
+		}
+		if result.Critical {
+			resp.Status = response.HealthDown
+			resp.Ready = false
+		} else if resp.Ready {
+			resp.Status = response.HealthDegraded
+		}
+	}
+	return resp
+}
+
+// check 在超时时间内探测单个依赖，探测函数不支持取消时超时后直接返回；
+// 就绪检查不需要登录，失败原因只返回概括的描述，详细错误写入日志
+func (h *HealthService) check(ctx context.Context, dep dependency) response.DependencyHealth {
+	result := response.DependencyHealth{Critical: dep.critical}
+	if dep.probe == nil {
+		result.Status = response.HealthDisabled
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- dep.probe(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result.Latency = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		logger.Warn("依赖探测失败", zap.String("dependency", dep.name), zap.Error(err))
+		result.Status = response.HealthDown
+		result.Error = "不可用"
+		if errors.Is(err, context.DeadlineExceeded) {
+			result.Error = "探测超时"
+		}
+		return result
+	}
+	result.Status = response.HealthUp
+	return result
+}
+
+// dependencies 当前配置下需要探测的依赖；
+// Redis 故障时缓存会降级到进程内缓存，搜索和邮件只影响部分功能，因此不作为关键依赖
+func (h *HealthService) dependencies() []dependency {
+	deps := []dependency{
+		{name: "database", critical: true},
+		{name: "redis"},
+		{name: "search"},
+		{name: "upload", critical: true, probe: probeUploadDir},
+		{name: "smtp"},
+	}
+
+	if configs.DB != nil {
+		deps[0].probe = func(ctx context.Context) error {
+			db, err := configs.DB.DB()
+			if err != nil {
+				return err
+			}
+			return db.PingContext(ctx)
+		}
+	}
+	if configs.REDIS != nil {
+		deps[1].probe = func(ctx context.Context) error {
+			return configs.REDIS.Ping().Err()
+		}
+	}
+	if configs.SEARCH != nil && configs.CONFIG.Search.Host != "" {
+		deps[2].probe = configs.SEARCH.Health
+	}
+	if configs.CONFIG.Mail.Addr != "" {
+		deps[4].probe = smail.Ping
+	}
+	return deps
+}
+
+// probeUploadDir 在上传目录写入并删除临时文件，确认目录可写
+func probeUploadDir(ctx context.Context) error {
+	file, err := os.CreateTemp(configs.CONFIG.Upload.Path, ".healthz-*")
+	if err != nil {
+		return err
+	}
+	name := file.Name()
+	defer os.Remove(name)
+
+	if _, err := file.WriteString("ok"); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// NewHealthService 创建存活与就绪检查服务实例
+func NewHealthService() *HealthService {
+	return &HealthService{timeout: common.HealthCheckTimeout}
+}
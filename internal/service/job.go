@@ -0,0 +1,130 @@
+package service
+
+import (
+	"blog/internal/dto/response"
+	"blog/internal/job"
+	"blog/internal/models"
+	"blog/internal/repository"
+	"blog/pkg/cache"
+	"blog/pkg/common"
+	"blog/pkg/configs"
+	"blog/pkg/logger"
+	"errors"
+
+	"go.uber.org/zap"
+)
+
+// JobService 定时任务服务，同时作为调度器的执行记录存储
+type JobService struct {
+	repository *repository.JobRepository
+	cache      *JobCache
+}
+
+// GetJobs 获取调度器与所有定时任务的状态
+func (j *JobService) GetJobs() job.Overview {
+	return job.Info()
+}
+
+// GetJobRuns 分页获取任务执行记录
+func (j *JobService) GetJobRuns(name string, page *response.Page) error {
+	runs, err := j.repository.FindRuns(name, page.Page, page.Size, &page.Count)
+	if err != nil {
+		return err
+	}
+	page.Data = runs
+	return nil
+}
+
+// PauseJob 暂停任务
+func (j *JobService) PauseJob(name string) error {
+	return job.Pause(name)
+}
+
+// ResumeJob 恢复任务
+func (j *JobService) ResumeJob(name string) error {
+	return job.Resume(name)
+}
+
+// TriggerJob 立即执行任务
+func (j *JobService) TriggerJob(name string) error {
+	return job.Trigger(name)
+}
+
+// CreateRun 保存执行记录
+func (j *JobService) CreateRun(run *models.JobRun) error {
+	return j.repository.CreateRun(run)
+}
+
+// FinishRun 更新执行结果
+func (j *JobService) FinishRun(run *models.JobRun) error {
+	return j.repository.FinishRun(run)
+}
+
+// PausedJobs 获取已暂停的任务
+func (j *JobService) PausedJobs() ([]string, error) {
+	return j.repository.FindPausedNames()
+}
+
+// SetPaused 保存任务的暂停状态
+func (j *JobService) SetPaused(name string, paused bool) error {
+	return j.repository.SavePaused(name, paused)
+}
+
+// importPaused 把旧版本保存在缓存中的暂停状态写入数据库，完成后删除缓存
+func (j *JobService) importPaused() {
+	names, err := j.cache.GetPaused()
+	if err != nil || len(names) == 0 {
+		return
+	}
+	for _, name := range names {
+		if err := j.repository.SavePaused(name, true); err != nil {
+			logger.Warn("迁移任务暂停状态失败", zap.String("name", name), zap.Error(err))
+			return
+		}
+	}
+	if err := j.cache.ClearPaused(); err != nil {
+		logger.Warn("删除缓存中的任务暂停状态失败", zap.Error(err))
+	}
+	logger.Info("已将缓存中的任务暂停状态迁移到数据库", zap.Strings("names", names))
+}
+
+// NewJobService 创建定时任务服务实例
+func NewJobService() *JobService {
+	var service = &JobService{
+		repository: repository.NewJobRepository(),
+		cache:      NewJobCache(),
+	}
+	if configs.DB != nil {
+		service.importPaused()
+	}
+	job.SetStore(service)
+	return service
+}
+
+// JobCache 旧版本的定时任务缓存，只用于迁移暂停状态
+type JobCache struct {
+	client cache.Cache
+}
+
+// GetPaused 获取已暂停的任务名称
+func (j *JobCache) GetPaused() ([]string, error) {
+	maps, err := j.client.HGetAll(common.JobPausedKey)
+	if err != nil && !errors.Is(err, cache.ErrNotFound) {
+		return nil, err
+	}
+	var names = make([]string, 0, len(maps))
+	for name := range maps {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// ClearPaused 删除暂停记录
+func (j *JobCache) ClearPaused() error {
+	return j.client.Del(common.JobPausedKey)
+}
+
+// NewJobCache 创建定时任务缓存实例
+func NewJobCache() *JobCache {
+	return &JobCache{client: cache.Observe(configs.CACHE, "job")}
+}
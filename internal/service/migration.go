@@ -0,0 +1,78 @@
+package service
+
+import (
+	"blog/internal/migrate"
+	"blog/pkg/configs"
+	"blog/pkg/logger"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// MigrationService 数据库迁移服务
+type MigrationService struct{}
+
+// migrator 使用程序中的迁移创建迁移器
+func (s *MigrationService) migrator() (*migrate.Migrator, error) {
+	return migrate.New(configs.DB, migrate.Migrations)
+}
+
+// Status 获取所有迁移的执行状态
+func (s *MigrationService) Status() ([]migrate.Status, error) {
+	m, err := s.migrator()
+	if err != nil {
+		return nil, err
+	}
+	return m.Status()
+}
+
+// Up 执行所有未执行的迁移
+func (s *MigrationService) Up(ctx context.Context) error {
+	m, err := s.migrator()
+	if err != nil {
+		return err
+	}
+	return m.Up(ctx, func(migration migrate.Migration, elapsed time.Duration) {
+		logger.Info("执行迁移完成", zap.Int64("version", migration.Version), zap.String("name", migration.Name), zap.Duration("elapsed", elapsed))
+	})
+}
+
+// Down 回滚最近执行的 steps 个迁移
+func (s *MigrationService) Down(ctx context.Context, steps int) error {
+	m, err := s.migrator()
+	if err != nil {
+		return err
+	}
+	return m.Down(ctx, steps, func(migration migrate.Migration, elapsed time.Duration) {
+		logger.Warn("回滚迁移完成", zap.Int64("version", migration.Version), zap.String("name", migration.Name), zap.Duration("elapsed", elapsed))
+	})
+}
+
+// Prepare 启动前检查数据库结构，auto 为 true 时先执行未执行的迁移，仍不是最新版本时返回错误
+func (s *MigrationService) Prepare(ctx context.Context, auto bool) error {
+	m, err := s.migrator()
+	if err != nil {
+		return err
+	}
+	if auto {
+		if err := s.Up(ctx); err != nil {
+			return err
+		}
+	}
+	return m.Check()
+}
+
+// Check 检查数据库是否为最新版本
+func (s *MigrationService) Check() error {
+	m, err := s.migrator()
+	if err != nil {
+		return err
+	}
+	return m.Check()
+}
+
+// NewMigrationService 创建数据库迁移服务实例
+func NewMigrationService() *MigrationService {
+	return &MigrationService{}
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"blog/internal/dto/dtos"
+	"blog/internal/dto/response"
+	"blog/internal/models"
+	"blog/internal/oauth"
+	"blog/internal/repository"
+	"blog/internal/utils"
+	"blog/pkg/background"
+	"blog/pkg/cache"
+	"blog/pkg/common"
+	"blog/pkg/configs"
+	"blog/pkg/logger"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// OAuthService 第三方登录服务
+type OAuthService struct {
+	repository *repository.UserIdentityRepository
+	users      *UserService
+	cache      *OAuthCache
+	providers  map[string]oauth.Provider
+	trustEmail map[string]bool // 信任邮箱的提供方
+	config     configs.OAuthConfig
+}
+
+// OAuthState 授权过程中保存的状态
+type OAuthState struct {
+	Provider string `json:"provider"` // 提供方名称
+	Verifier string `json:"verifier"` // PKCE verifier
+	UserID   int    `json:"userId"`   // 不为0时表示绑定到该用户
+	Nonce    string `json:"nonce"`    // 发起授权的浏览器 Cookie 中随机数的摘要
+}
+
+// OAuthResult 授权回调的处理结果
+type OAuthResult struct {
+	Linked bool                   // 是否为绑定操作
+	Token  response.TokenResponse // 登录成功返回的令牌
+}
+
+// GetProviders 获取已启用的第三方登录提供方
+func (o *OAuthService) GetProviders() []string {
+	var names = make([]string, 0, len(o.providers))
+	for name := range o.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// AuthCodeURL 生成授权跳转地址和需要保存到浏览器 Cookie 的随机数，uid 不为0时表示为当前用户绑定第三方账号
+func (o *OAuthService) AuthCodeURL(name string, uid int) (string, string, error) {
+	provider, ok := o.providers[name]
+	if !ok {
+		return "", "", fmt.Errorf("未启用的第三方登录: %s", name)
+	}
+
+	state, err := utils.RandomToken(16)
+	if err != nil {
+		return "", "", fmt.Errorf("生成state失败: %w", err)
+	}
+
+	verifier, err := utils.RandomToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("生成verifier失败: %w", err)
+	}
+
+	nonce, err := utils.RandomToken(16)
+	if err != nil {
+		return "", "", fmt.Errorf("生成nonce失败: %w", err)
+	}
+
+	url, err := provider.AuthCodeURL(state, verifier, o.callbackURL(name))
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := o.cache.SetState(state, OAuthState{Provider: name, Verifier: verifier, UserID: uid, Nonce: utils.HashToken(nonce)}); err != nil {
+		return "", "", fmt.Errorf("保存state失败: %w", err)
+	}
+
+	return url, nonce, nil
+}
+
+// Callback 处理授权回调，登录、自动注册或绑定第三方账号；
+// nonce 来自浏览器 Cookie，必须与发起授权时一致，防止他人的授权流程在当前用户的浏览器中完成
+func (o *OAuthService) Callback(name, code, stateKey, nonce, ip string) (OAuthResult, error) {
+	if !o.cache.CheckNonce(stateKey, nonce) {
+		logger.Warn("第三方授权回调的nonce不匹配", zap.String("provider", name), zap.String("ip", ip))
+		return OAuthResult{}, errors.New("授权状态无效或已过期，请重新登录")
+	}
+
+	state, err := o.cache.TakeState(stateKey)
+	if err != nil || state.Provider != name {
+		return OAuthResult{}, errors.New("授权状态无效或已过期，请重新登录")
+	}
+
+	provider, ok := o.providers[name]
+	if !ok {
+		return OAuthResult{}, fmt.Errorf("未启用的第三方登录: %s", name)
+	}
+
+	identity, err := provider.Exchange(code, state.Verifier, o.callbackURL(name))
+	if err != nil {
+		logger.Info("第三方授权失败", zap.String("provider", name), zap.String("error", err.Error()))
+		return OAuthResult{}, errors.New("第三方授权失败")
+	}
+
+	if state.UserID != 0 {
+		return OAuthResult{Linked: true}, o.link(state.UserID, identity)
+	}
+
+	user, err := o.findOrRegister(identity, ip)
+	if err != nil {
+		return OAuthResult{}, err
+	}
+
+	token, err := o.users.issueToken(user)
+	if err != nil {
+		return OAuthResult{}, err
+	}
+
+	background.Go(func() {
+		o.users.UpdateUserStatus(dtos.UserLoginStatus{
+			ID:        user.ID,
+			LastLogin: time.Now().Unix(),
+			LoginIp:   ip,
+			LoginCity: utils.GetIpCity(ip),
+		})
+	})
+
+	return OAuthResult{Token: token}, nil
+}
+
+// link 为已登录用户绑定第三方账号
+func (o *OAuthService) link(uid int, identity *oauth.Identity) error {
+	if exists, err := o.repository.FindIdentity(identity.Provider, identity.Subject); err == nil {
+		if exists.UserID == uid {
+			return nil
+		}
+		return errors.New("该第三方账号已绑定其他用户")
+	}
+
+	if err := o.repository.Save(toIdentityModel(uid, identity)); err != nil {
+		return err
+	}
+
+	logger.Info("绑定第三方账号成功", zap.Int("uid", uid), zap.String("provider", identity.Provider))
+	return nil
+}
+
+// findOrRegister 根据第三方账号查找用户，首次登录时自动注册
+func (o *OAuthService) findOrRegister(identity *oauth.Identity, ip string) (models.User, error) {
+	if exists, err := o.repository.FindIdentity(identity.Provider, identity.Subject); err == nil {
+		return o.users.dao.FindById(exists.UserID)
+	}
+
+	if identity.Email == "" {
+		return models.User{}, errors.New("第三方账号未提供邮箱，无法注册")
+	}
+
+	// 邮箱已注册时，只有信任的提供方确认过邮箱所有权才自动绑定普通用户，管理员只能登录后手动绑定，避免账号被冒领
+	if user, err := o.users.dao.FindByEmail(identity.Email); err == nil {
+		if !canLinkByEmail(user, identity, o.trustEmail[identity.Provider]) {
+			logger.Warn("第三方账号的邮箱已注册，拒绝自动绑定", zap.Int("uid", user.ID), zap.String("provider", identity.Provider))
+			return models.User{}, errors.New("该邮箱已注册，请登录后在个人资料中绑定")
+		}
+		if err := o.repository.Save(toIdentityModel(user.ID, identity)); err != nil {
+			return models.User{}, err
+		}
+		logger.Info("第三方账号按邮箱自动绑定", zap.Int("uid", user.ID), zap.String("provider", identity.Provider))
+		return user, nil
+	}
+
+	random, err := utils.RandomToken(5)
+	if err != nil {
+		return models.User{}, fmt.Errorf("生成用户名失败: %w", err)
+	}
+
+	nickName := identity.Name
+	if nickName == "" {
+		nickName = identity.Username
+	}
+	if nickName == "" {
+		nickName = random
+	}
+
+	user := models.User{
+		Username: "oauth_" + random,
+		Email:    identity.Email,
+		NickName: nickName,
+		RegIp:    ip,
+		RegCity:  utils.GetIpCity(ip),
+		RegTime:  time.Now().Unix(),
+		Status:   true,
+		Verified: identity.EmailVerified,
+		RoleID:   uint(common.UserRoleId),
+	}
+
+	if identity.Avatar != "" {
+		user.Avatar = identity.Avatar
+	}
+
+	if err := o.repository.SaveUserWithIdentity(&user, toIdentityModel(0, identity)); err != nil {
+		logger.Info("第三方账号注册失败", zap.String("error", err.Error()), zap.String("provider", identity.Provider))
+		return models.User{}, err
+	}
+
+	logger.Info("第三方账号注册成功", zap.Int("uid", user.ID), zap.String("provider", identity.Provider))
+	return o.users.dao.FindById(user.ID)
+}
+
+// canLinkByEmail 第三方账号能否按邮箱自动绑定到已注册的用户，
+// 需要提供方受信任且验证过邮箱，已禁用的账号和管理员不自动绑定
+func canLinkByEmail(user models.User, identity *oauth.Identity, trusted bool) bool {
+	return trusted && identity.EmailVerified && user.Status && user.RoleID == uint(common.UserRoleId)
+}
+
+// GetIdentities 获取用户绑定的第三方账号
+func (o *OAuthService) GetIdentities(uid int) ([]response.UserIdentityResponse, error) {
+	identities, err := o.repository.FindByUserId(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	var list = make([]response.UserIdentityResponse, 0, len(identities))
+	for _, identity := range identities {
+		list = append(list, identity.ToVo())
+	}
+	return list, nil
+}
+
+// Unlink 解除第三方绑定，未设置密码的用户不能解除最后一个绑定
+func (o *OAuthService) Unlink(user *models.User, provider string) error {
+	identities, err := o.repository.FindByUserId(user.ID)
+	if err != nil {
+		return err
+	}
+
+	if user.Password == "" && len(identities) <= 1 {
+		return errors.New("这是唯一的登录方式，请先设置密码")
+	}
+
+	if err := o.repository.DeleteByUserIdAndProvider(user.ID, provider); err != nil {
+		return err
+	}
+
+	logger.Info("解除第三方绑定成功", zap.Int("uid", user.ID), zap.String("provider", provider))
+	return nil
+}
+
+// callbackURL 提供方的回调地址
+func (o *OAuthService) callbackURL(name string) string {
+	return strings.TrimSuffix(o.config.CallbackURL, "/") + "/" + name + "/callback"
+}
+
+// toIdentityModel 转换为绑定模型
+func toIdentityModel(uid int, identity *oauth.Identity) *models.UserIdentity {
+	name := identity.Username
+	if name == "" {
+		name = identity.Name
+	}
+	return &models.UserIdentity{
+		UserID:   uid,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+		Name:     name,
+		Avatar:   identity.Avatar,
+	}
+}
+
+// NewOAuthService 创建第三方登录服务实例
+func NewOAuthService() *OAuthService {
+	return &OAuthService{
+		repository: repository.NewUserIdentityRepository(),
+		users:      NewUserService(),
+		cache:      NewOAuthCache(),
+		providers:  configs.OAUTH,
+		trustEmail: configs.OAUTH_TRUST_EMAIL,
+		config:     configs.CONFIG.OAuth,
+	}
+}
+
+// OAuthCache 第三方登录缓存
+type OAuthCache struct {
+	client cache.Cache
+}
+
+// SetState 保存授权状态
+func (o *OAuthCache) SetState(key string, state OAuthState) error {
+	return o.client.Set(common.OAuthStateKey+key, utils.Serialize(state), common.OAuthStateExpire)
+}
+
+// CheckNonce 校验浏览器带回的随机数与授权状态中保存的是否一致，不会取出授权状态
+func (o *OAuthCache) CheckNonce(key, nonce string) bool {
+	if nonce == "" {
+		return false
+	}
+	val, err := o.client.Get(common.OAuthStateKey + key)
+	if err != nil {
+		return false
+	}
+	state := utils.Deserialize[OAuthState](val)
+	return subtle.ConstantTimeCompare([]byte(state.Nonce), []byte(utils.HashToken(nonce))) == 1
+}
+
+// TakeState 取出授权状态，每个state只能使用一次
+func (o *OAuthCache) TakeState(key string) (OAuthState, error) {
+	val, err := o.client.GetDel(common.OAuthStateKey + key)
+	if err != nil {
+		return OAuthState{}, err
+	}
+	return utils.Deserialize[OAuthState](val), nil
+}
+
+// NewOAuthCache 创建第三方登录缓存实例
+func NewOAuthCache() *OAuthCache {
+	return &OAuthCache{client: cache.Observe(configs.CACHE, "oauth")}
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"blog/internal/models"
+	"blog/internal/oauth"
+	"blog/pkg/common"
+	"testing"
+)
+
+func TestCanLinkByEmail(t *testing.T) {
+	user := models.User{Status: true, RoleID: uint(common.UserRoleId)}
+	verified := &oauth.Identity{EmailVerified: true}
+
+	if !canLinkByEmail(user, verified, true) {
+		t.Fatal("信任的提供方验证过邮箱时应自动绑定普通用户")
+	}
+	if canLinkByEmail(user, verified, false) {
+		t.Error("未信任的提供方不应自动绑定")
+	}
+	if canLinkByEmail(user, &oauth.Identity{}, true) {
+		t.Error("邮箱未验证时不应自动绑定")
+	}
+
+	for _, role := range []common.RoleId{common.AdminRoleId, common.SuperAdminRoleId} {
+		admin := user
+		admin.RoleID = uint(role)
+		if canLinkByEmail(admin, verified, true) {
+			t.Errorf("角色 %d 不应自动绑定", role)
+		}
+	}
+
+	disabled := user
+	disabled.Status = false
+	if canLinkByEmail(disabled, verified, true) {
+		t.Error("已禁用的账号不应自动绑定")
+	}
+}
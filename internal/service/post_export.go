@@ -0,0 +1,171 @@
+package service
+
+import (
+	"blog/internal/dto/requests"
+	"blog/internal/exporter"
+	"blog/internal/importer"
+	"blog/internal/models"
+	"blog/internal/repository"
+	"blog/pkg/common"
+	"blog/pkg/logger"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PostExportService 将博客导出为带 front matter 的 Markdown 和图片
+type PostExportService struct {
+	repository *repository.BlogRepository
+	files      *repository.FileRepository
+	client     *http.Client
+}
+
+// FindBlogs 查找要导出的博客，超级管理员指定分类或专题时可以不限用户，其他情况只导出 uid 的博客
+func (s *PostExportService) FindBlogs(uid int, super bool, req requests.PostExportRequest) ([]models.Blog, error) {
+	owner := &uid
+	if super {
+		if req.User != nil {
+			owner = req.User
+		} else if req.Category != nil || req.Topic != nil {
+			owner = nil
+		}
+	}
+	return s.repository.FindExportBlogs(owner, req.Category, req.Topic)
+}
+
+// Export 将博客写入 zip，本站上传的图片写入 assets 目录并改写链接，读取失败的图片保留原地址；ctx 取消时停止导出
+func (s *PostExportService) Export(ctx context.Context, w io.Writer, blogs []models.Blog) error {
+	writer := exporter.NewWriter(w)
+	for _, blog := range blogs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		post := toExportPost(blog)
+
+		refs := importer.Images(post.Content)
+		if post.Cover != "" {
+			refs = append(refs, post.Cover)
+		}
+		mapping, err := s.writeAssets(writer, refs)
+		if err != nil {
+			return err
+		}
+		post.Content = importer.ReplaceImages(post.Content, mapping)
+		if asset, ok := mapping[post.Cover]; ok {
+			post.Cover = asset
+		}
+
+		if _, err := writer.WritePost(post); err != nil {
+			return fmt.Errorf("写入博客 %d 失败: %w", blog.ID, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	logger.Info("导出博客完成", zap.Int("count", len(blogs)))
+	return nil
+}
+
+// writeAssets 写入文章引用的本站图片，返回原地址到 assets 中路径的映射
+func (s *PostExportService) writeAssets(writer *exporter.Writer, refs []string) (map[string]string, error) {
+	infos, err := s.files.FindMd5InfosByUrls(refs)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := make(map[string]string, len(infos))
+	for _, info := range infos {
+		name := info.Md5 + assetExt(info.Url)
+		if !writer.HasAsset(name) {
+			data, err := s.readAsset(info)
+			if err != nil {
+				logger.Warn("读取导出图片失败", zap.String("url", info.Url), zap.Error(err))
+				continue
+			}
+			if _, err := writer.WriteAsset(name, bytes.NewReader(data)); err != nil {
+				return nil, err
+			}
+		}
+		mapping[info.Url] = path.Join(exporter.AssetsDir, name)
+	}
+	return mapping, nil
+}
+
+// readAsset 优先读取本地文件，不在本地时（如图床）通过地址下载，大小限制与导入时解压单个文件相同
+func (s *PostExportService) readAsset(info models.FileMd5Info) ([]byte, error) {
+	if info.AbsolutePath != "" {
+		if data, err := os.ReadFile(info.AbsolutePath); err == nil {
+			return data, nil
+		}
+	}
+
+	resp, err := s.client.Get(info.Url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("状态码 %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, importer.MaxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > importer.MaxFileSize {
+		return nil, importer.ErrTooLarge
+	}
+	return data, nil
+}
+
+// assetExt 取图片地址中的扩展名
+func assetExt(ref string) string {
+	if u, err := url.Parse(ref); err == nil {
+		ref = u.Path
+	}
+	return strings.ToLower(path.Ext(ref))
+}
+
+// toExportPost 将博客转为导出的文章
+func toExportPost(blog models.Blog) exporter.Post {
+	post := exporter.Post{
+		ID:          blog.ID,
+		Title:       blog.Title,
+		Description: blog.Description,
+		Content:     blog.Content,
+		Cover:       blog.CoverImage,
+		CreatedAt:   time.Unix(blog.CreatedAt, 0),
+		UpdatedAt:   time.Unix(blog.UpdatedAt, 0),
+		Private:     blog.IsPrivate,
+	}
+	if blog.Category != nil {
+		post.Category = blog.Category.Name
+	}
+	if blog.Topic != nil {
+		post.Topic = blog.Topic.Name
+	}
+	if blog.SourceURL != nil {
+		post.SourceURL = *blog.SourceURL
+	}
+	for _, tag := range blog.Tags {
+		post.Tags = append(post.Tags, tag.Name)
+	}
+	return post
+}
+
+// NewPostExportService 创建博客导出服务实例
+func NewPostExportService() *PostExportService {
+	return &PostExportService{
+		repository: repository.NewBlogRepository(),
+		files:      repository.NewFileRepository(),
+		client:     &http.Client{Timeout: common.RemoteImageTimeout},
+	}
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"blog/internal/dto/requests"
+	"blog/internal/dto/response"
+	"blog/internal/importer"
+	"blog/internal/utils"
+	"blog/pkg/common"
+	"blog/pkg/logger"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PostImportService 从 Hexo、Hugo、Jekyll 的 Markdown 或 WordPress 导出文件导入文章
+type PostImportService struct {
+	blogs      *BlogService
+	tags       *TagService
+	categories *CategoryService
+	topics     *TopicService
+	files      *FileService
+	client     *http.Client
+}
+
+// postImport 一次导入的状态，同一图片只转存一次
+type postImport struct {
+	uid      int
+	archive  *importer.Archive
+	request  requests.PostImportRequest
+	uploaded map[string]string // 图片在导入文件中的路径或远程地址 → 转存后的地址
+}
+
+// ImportPosts 导入文件中的文章，按文章返回结果；单篇文章失败不影响其他文章
+func (s *PostImportService) ImportPosts(uid int, name string, request requests.PostImportRequest) (*response.PostImportReport, error) {
+	archive, err := importer.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	report := &response.PostImportReport{Format: archive.Format, Posts: []response.PostImportResult{}}
+	for _, fe := range archive.Errors {
+		report.Failed++
+		report.Posts = append(report.Posts, response.PostImportResult{Source: fe.Source, Status: response.PostImportFailed, Error: fe.Err.Error()})
+	}
+
+	state := &postImport{uid: uid, archive: archive, request: request, uploaded: make(map[string]string)}
+	for _, post := range archive.Posts {
+		result := s.importPost(state, post)
+		switch result.Status {
+		case response.PostImportCreated:
+			report.Created++
+		case response.PostImportSkipped:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+		report.Posts = append(report.Posts, result)
+	}
+
+	logger.Info("导入文章完成", zap.Int("uid", uid), zap.String("format", report.Format),
+		zap.Int("created", report.Created), zap.Int("skipped", report.Skipped), zap.Int("failed", report.Failed))
+	return report, nil
+}
+
+// importPost 导入单篇文章
+func (s *PostImportService) importPost(state *postImport, post importer.Post) response.PostImportResult {
+	result := response.PostImportResult{Source: post.Source, Title: post.Title}
+	skip := func(reason string) response.PostImportResult {
+		result.Status, result.Error = response.PostImportSkipped, reason
+		return result
+	}
+	fail := func(err error) response.PostImportResult {
+		result.Status, result.Error = response.PostImportFailed, err.Error()
+		return result
+	}
+
+	if post.Draft && !state.request.Drafts {
+		return skip("草稿")
+	}
+	title := importer.Truncate(post.Title, common.ImportTextLimit)
+	if title == "" {
+		return fail(errors.New("缺少标题"))
+	}
+	if strings.TrimSpace(post.Content) == "" {
+		return fail(errors.New("正文为空"))
+	}
+	exists, err := s.blogs.ExistsBlogTitle(state.uid, title)
+	if err != nil {
+		return fail(err)
+	}
+	if exists {
+		return skip("已存在同名博客")
+	}
+
+	// 转存正文中的图片和封面，失败时保留原地址
+	mapping := make(map[string]string)
+	for _, ref := range importer.Images(post.Content) {
+		newURL, err := s.rehost(state, post, ref)
+		if err != nil {
+			result.Warnings = append(result.Warnings, err.Error())
+			continue
+		}
+		if newURL != "" {
+			mapping[ref] = newURL
+			result.Images++
+		}
+	}
+	content := importer.ReplaceImages(post.Content, mapping)
+
+	coverImage := post.Cover
+	if coverImage != "" {
+		if newURL, err := s.rehost(state, post, coverImage); err != nil {
+			result.Warnings = append(result.Warnings, err.Error())
+		} else if newURL != "" {
+			coverImage = newURL
+		}
+	}
+	if coverImage == "" {
+		if images := importer.Images(content); len(images) > 0 {
+			coverImage = images[0]
+		} else {
+			result.Warnings = append(result.Warnings, "没有封面")
+		}
+	}
+
+	description := importer.Summary(post.Description, common.ImportTextLimit)
+	if description == "" {
+		description = importer.Summary(content, common.ImportTextLimit)
+	}
+
+	blogRequest := requests.BlogRequest{
+		Title:       title,
+		Description: description,
+		CoverImage:  coverImage,
+		Content:     content,
+		IsPrivate:   post.Draft,
+	}
+	if u, err := url.Parse(post.Link); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		blogRequest.SourceURL = &post.Link
+	}
+
+	// 博客属于专题时没有分类和标签，专题属于其他用户时按分类导入
+	if post.Topic != "" {
+		id, err := s.topics.EnsureTopic(state.uid, post.Topic)
+		if err == nil {
+			blogRequest.TopicID = &id
+		} else {
+			result.Warnings = append(result.Warnings, err.Error())
+		}
+	}
+
+	// 博客只有一个分类，没有分类时不能保存标签
+	category := state.request.Category
+	if len(post.Categories) > 0 {
+		category = post.Categories[0]
+		if len(post.Categories) > 1 {
+			result.Warnings = append(result.Warnings, "只保留第一个分类: "+category)
+		}
+	}
+	if blogRequest.TopicID != nil {
+		category = ""
+	}
+	if category != "" {
+		id, err := s.categories.EnsureCategory(category)
+		if err != nil {
+			return fail(fmt.Errorf("创建分类 %s 失败: %w", category, err))
+		}
+		if blogRequest.Tags, err = s.tags.EnsureTags(post.Tags); err != nil {
+			return fail(err)
+		}
+		blogRequest.CategoryID = &id
+	} else if len(post.Tags) > 0 {
+		result.Warnings = append(result.Warnings, "没有分类，标签未保存")
+	}
+
+	updatedAt := post.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = post.CreatedAt
+	}
+	blog, err := s.blogs.ImportBlog(state.uid, blogRequest, unix(post.CreatedAt), unix(updatedAt))
+	if err != nil {
+		return fail(err)
+	}
+	result.Status, result.BlogID = response.PostImportCreated, blog.ID
+	return result
+}
+
+// rehost 转存图片，返回新地址；本站图片、data URI 和保留的远程图片返回空字符串
+func (s *PostImportService) rehost(state *postImport, post importer.Post, ref string) (string, error) {
+	if strings.HasPrefix(ref, "data:") {
+		return "", nil
+	}
+	if uri := s.files.GetConfig().Uri; uri != "" && strings.HasPrefix(ref, uri) {
+		return "", nil
+	}
+
+	var (
+		key  string
+		name string
+		data []byte
+		err  error
+	)
+	if asset, ok := state.archive.Asset(post, ref); ok {
+		key = "asset:" + asset
+		if newURL, ok := state.uploaded[key]; ok {
+			return newURL, nil
+		}
+		name = path.Base(asset)
+		data, err = state.archive.Read(asset)
+	} else if importer.IsRemote(ref) {
+		if state.request.KeepRemote {
+			return "", nil
+		}
+		key = ref
+		if newURL, ok := state.uploaded[key]; ok {
+			return newURL, nil
+		}
+		name, data, err = s.download(ref)
+	} else {
+		return "", fmt.Errorf("找不到图片: %s", ref)
+	}
+	if err != nil {
+		return "", fmt.Errorf("读取图片 %s 失败: %w", ref, err)
+	}
+
+	file, err := s.files.SaveImage(name, data, &state.uid)
+	if err != nil {
+		return "", err
+	}
+	state.uploaded[key] = file.Url
+	return file.Url, nil
+}
+
+// download 下载远程图片，限制大小并检查类型；地址中没有扩展名时按类型补上
+func (s *PostImportService) download(ref string) (string, []byte, error) {
+	if strings.HasPrefix(ref, "//") {
+		ref = "https:" + ref
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", nil, err
+	}
+
+	resp, err := s.client.Get(u.String())
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("状态码 %d", resp.StatusCode)
+	}
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !strings.HasPrefix(contentType, "image/") {
+		return "", nil, fmt.Errorf("不是图片: %s", contentType)
+	}
+
+	limit := int64(s.files.GetConfig().MaxFileSize) * mb
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return "", nil, err
+	}
+	if int64(len(data)) > limit {
+		return "", nil, errors.New("文件大小超过限制")
+	}
+
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		name = "image"
+	}
+	if path.Ext(name) == "" {
+		if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+	return name, data, nil
+}
+
+// unix 转为秒级时间戳，零值返回0
+func unix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// NewPostImportService 创建文章导入服务实例
+func NewPostImportService() *PostImportService {
+	return &PostImportService{
+		blogs:      NewBlogService(),
+		tags:       NewTagService(),
+		categories: NewCategoryService(),
+		topics:     NewTopicService(),
+		files:      NewFileService(),
+		client:     utils.NewPublicClient(common.RemoteImageTimeout),
+	}
+}
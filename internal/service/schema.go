@@ -0,0 +1,194 @@
+package service
+
+import (
+	"blog/internal/dto/response"
+	"errors"
+	"strings"
+)
+
+// ErrTableNotFound 表不存在
+var ErrTableNotFound = errors.New("表不存在")
+
+// tableSQL 当前 schema 下的普通表和分区表，行数优先使用统计信息中的存活行数
+const tableSQL = `
+SELECT c.oid::bigint AS oid,
+       c.relname AS name,
+       COALESCE(obj_description(c.oid, 'pg_class'), '') AS comment,
+       COALESCE(NULLIF(s.n_live_tup, 0), GREATEST(c.reltuples, 0))::bigint AS rows,
+       pg_total_relation_size(c.oid) AS total_size,
+       pg_relation_size(c.oid) AS table_size,
+       pg_indexes_size(c.oid) AS index_size
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+LEFT JOIN pg_stat_user_tables s ON s.relid = c.oid
+WHERE n.nspname = current_schema() AND c.relkind IN ('r', 'p')`
+
+// columnSQL 表的列，注释来自 gorm 建表时根据 comment 标签生成的 COMMENT ON COLUMN
+const columnSQL = `
+SELECT a.attname AS name,
+       format_type(a.atttypid, a.atttypmod) AS type,
+       NOT a.attnotnull AS nullable,
+       pg_get_expr(d.adbin, d.adrelid) AS "default",
+       COALESCE(col_description(a.attrelid, a.attnum), '') AS comment,
+       EXISTS (SELECT 1 FROM pg_index i WHERE i.indrelid = a.attrelid AND i.indisprimary AND a.attnum = ANY (i.indkey)) AS primary_key
+FROM pg_attribute a
+LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE a.attrelid = ?::oid AND a.attnum > 0 AND NOT a.attisdropped
+ORDER BY a.attnum`
+
+// indexSQL 表的索引，列名按索引中的顺序用逗号拼接
+const indexSQL = `
+SELECT i.relname AS name,
+       COALESCE((SELECT string_agg(a.attname, ',' ORDER BY k.ord)
+                 FROM unnest(ix.indkey) WITH ORDINALITY k(attnum, ord)
+                 JOIN pg_attribute a ON a.attrelid = ix.indrelid AND a.attnum = k.attnum), '') AS columns,
+       ix.indisunique AS "unique",
+       ix.indisprimary AS "primary",
+       pg_get_indexdef(ix.indexrelid) AS definition,
+       pg_relation_size(ix.indexrelid) AS size
+FROM pg_index ix
+JOIN pg_class i ON i.oid = ix.indexrelid
+WHERE ix.indrelid = ?::oid
+ORDER BY i.relname`
+
+// foreignKeySQL 表的外键
+const foreignKeySQL = `
+SELECT con.conname AS name,
+       (SELECT string_agg(a.attname, ',' ORDER BY k.ord)
+        FROM unnest(con.conkey) WITH ORDINALITY k(attnum, ord)
+        JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum) AS columns,
+       con.confrelid::regclass::text AS ref_table,
+       (SELECT string_agg(a.attname, ',' ORDER BY k.ord)
+        FROM unnest(con.confkey) WITH ORDINALITY k(attnum, ord)
+        JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum) AS ref_columns,
+       con.confupdtype::text AS on_update,
+       con.confdeltype::text AS on_delete
+FROM pg_constraint con
+WHERE con.conrelid = ?::oid AND con.contype = 'f'
+ORDER BY con.conname`
+
+// foreignKeyActions pg_constraint 中外键动作的编码
+var foreignKeyActions = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+type tableRow struct {
+	Oid int64
+	response.TableResponse
+}
+
+type indexRow struct {
+	Name       string
+	Columns    string
+	Unique     bool
+	Primary    bool
+	Definition string
+	Size       int64
+}
+
+type foreignKeyRow struct {
+	Name       string
+	Columns    string
+	RefTable   string
+	RefColumns string
+	OnUpdate   string
+	OnDelete   string
+}
+
+// GetTables 获取当前 schema 下的所有表，包括估计行数和占用空间
+func (d *DataBaseServiceImpl) GetTables() ([]response.TableResponse, error) {
+	var rows []tableRow
+	if err := d.db.Raw(tableSQL + "\nORDER BY c.relname").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	tables := make([]response.TableResponse, len(rows))
+	for i, row := range rows {
+		tables[i] = row.TableResponse
+	}
+	return tables, nil
+}
+
+// HasTable 判断当前 schema 下是否存在表
+func (d *DataBaseServiceImpl) HasTable(name string) bool {
+	_, err := d.findTable(name)
+	return err == nil
+}
+
+// GetTable 获取表的列、索引、外键、实际行数和占用空间
+func (d *DataBaseServiceImpl) GetTable(name string) (*response.TableDetailResponse, error) {
+	table, err := d.findTable(name)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &response.TableDetailResponse{TableResponse: table.TableResponse}
+	if err := d.db.Raw(columnSQL, table.Oid).Scan(&detail.Columns).Error; err != nil {
+		return nil, err
+	}
+
+	var indexes []indexRow
+	if err := d.db.Raw(indexSQL, table.Oid).Scan(&indexes).Error; err != nil {
+		return nil, err
+	}
+	detail.Indexes = make([]response.IndexResponse, len(indexes))
+	for i, index := range indexes {
+		detail.Indexes[i] = response.IndexResponse{
+			Name:       index.Name,
+			Columns:    splitNames(index.Columns),
+			Unique:     index.Unique,
+			Primary:    index.Primary,
+			Definition: index.Definition,
+			Size:       index.Size,
+		}
+	}
+
+	var foreignKeys []foreignKeyRow
+	if err := d.db.Raw(foreignKeySQL, table.Oid).Scan(&foreignKeys).Error; err != nil {
+		return nil, err
+	}
+	detail.ForeignKeys = make([]response.ForeignKeyResponse, len(foreignKeys))
+	for i, fk := range foreignKeys {
+		detail.ForeignKeys[i] = response.ForeignKeyResponse{
+			Name:       fk.Name,
+			Columns:    splitNames(fk.Columns),
+			RefTable:   fk.RefTable,
+			RefColumns: splitNames(fk.RefColumns),
+			OnUpdate:   foreignKeyActions[fk.OnUpdate],
+			OnDelete:   foreignKeyActions[fk.OnDelete],
+		}
+	}
+
+	if err := d.db.Raw("SELECT count(*) FROM " + quoteIdent(table.Name)).Scan(&detail.ExactRows).Error; err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
+
+// findTable 按名称查找当前 schema 下的表
+func (d *DataBaseServiceImpl) findTable(name string) (*tableRow, error) {
+	var rows []tableRow
+	if err := d.db.Raw(tableSQL+"\nAND c.relname = ?", name).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrTableNotFound
+	}
+	return &rows[0], nil
+}
+
+// quoteIdent 为标识符加上双引号
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// splitNames 拆分逗号拼接的列名
+func splitNames(names string) []string {
+	if names == "" {
+		return []string{}
+	}
+	return strings.Split(names, ",")
+}
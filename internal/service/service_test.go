@@ -0,0 +1,14 @@
+package service
+
+import (
+	"blog/pkg/logger"
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"blog/internal/dto/requests"
+	"blog/internal/models"
+	"blog/internal/repository"
+	"blog/internal/utils"
+	"blog/pkg/common"
+	"blog/pkg/configs"
+	"blog/pkg/logger"
+	"cmp"
+	"crypto/subtle"
+	"errors"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrSetupDone  = errors.New("站点已完成初始化")
+	ErrSetupToken = errors.New("安装令牌错误")
+)
+
+// SetupService 首次安装服务，没有超级管理员时凭安装令牌创建站长账号和网站配置，完成后不再可用
+type SetupService struct {
+	dao   *repository.UserRepository
+	users *UserService
+	mu    sync.Mutex
+	token string // 安装令牌，完成安装后清空
+	done  bool   // 已有超级管理员
+}
+
+// Required 是否需要首次安装，已有超级管理员后不再查询数据库
+func (s *SetupService) Required() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.required()
+}
+
+func (s *SetupService) required() (bool, error) {
+	if s.done {
+		return false, nil
+	}
+	exists, err := s.dao.ExistsByRole(uint(common.SuperAdminRoleId))
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		s.done, s.token = true, ""
+	}
+	return !exists, nil
+}
+
+// Setup 校验安装令牌后创建站长账号并保存网站配置
+func (s *SetupService) Setup(req requests.SetupRequest) (models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	required, err := s.required()
+	if err != nil {
+		return models.User{}, err
+	}
+	if !required {
+		return models.User{}, ErrSetupDone
+	}
+	if s.token == "" || subtle.ConstantTimeCompare([]byte(req.Token), []byte(s.token)) != 1 {
+		logger.Warn("首次安装的令牌错误")
+		return models.User{}, ErrSetupToken
+	}
+
+	create := requests.CreateUserRequest{
+		Username: req.Username,
+		Password: req.Password,
+		Email:    req.Email,
+		NickName: req.NickName,
+		RoleID:   uint(common.SuperAdminRoleId),
+	}
+	if err := s.users.checkUnique(create); err != nil {
+		return models.User{}, err
+	}
+	// 进程内的锁只能避免同一实例重复安装，多个实例之间由数据库的咨询锁保证只创建一个站长账号
+	user := create.ToUserModel()
+	created, err := s.dao.CreateIfRoleAbsent(&user, common.SetupLockKey)
+	if err != nil {
+		logger.Info("创建站长账号失败", zap.String("error", err.Error()), zap.String("username", user.Username))
+		return models.User{}, err
+	}
+	s.done, s.token = true, ""
+	if !created {
+		return models.User{}, ErrSetupDone
+	}
+	if err := configs.CACHE.Del(common.SetupTokenKey); err != nil {
+		logger.Warn("删除安装令牌缓存失败", zap.Error(err))
+	}
+
+	site := req.Site
+	site.Name = cmp.Or(site.Name, user.NickName)
+	if err := s.users.SetWebSiteConfig(site); err != nil {
+		logger.Warn("保存网站配置失败", zap.Error(err))
+	}
+
+	logger.Info("首次安装完成", zap.Int("UserID", user.ID), zap.String("username", user.Username))
+	return user, nil
+}
+
+// NewSetupService 创建首次安装服务实例，需要安装时使用环境变量中的令牌，
+// 未设置时生成令牌保存到缓存并打印到日志，多个实例共用 Redis 中的同一个令牌
+func NewSetupService() *SetupService {
+	service := &SetupService{
+		dao:   repository.NewUserRepository(),
+		users: NewUserService(),
+	}
+	if configs.DB == nil {
+		return service
+	}
+
+	required, err := service.Required()
+	if err != nil {
+		logger.Error("检查首次安装状态失败", zap.Error(err))
+		return service
+	}
+	if !required {
+		return service
+	}
+
+	if service.token = os.Getenv(common.SetupTokenEnv); service.token != "" {
+		logger.Warn("站点尚未初始化，请使用环境变量中的安装令牌完成首次安装", zap.String("env", common.SetupTokenEnv))
+		return service
+	}
+	token, err := sharedSetupToken()
+	if err != nil {
+		logger.Error("生成安装令牌失败", zap.Error(err))
+		return service
+	}
+	service.token = token
+	logger.Warn("站点尚未初始化，请使用安装令牌完成首次安装", zap.String("token", token))
+	return service
+}
+
+// sharedSetupToken 生成安装令牌并保存到缓存，多个实例启动时只有第一个写入成功，其余实例使用已有的令牌
+func sharedSetupToken() (string, error) {
+	token, err := utils.RandomToken(16)
+	if err != nil {
+		return "", err
+	}
+	ok, err := configs.CACHE.SetNX(common.SetupTokenKey, token, 0)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return token, nil
+	}
+	return configs.CACHE.Get(common.SetupTokenKey)
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"blog/internal/dto/requests"
+	"blog/internal/models"
+	"blog/internal/sqlconsole"
+	"blog/pkg/common"
+	"blog/pkg/configs"
+	"blog/pkg/logger"
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SQLConsoleService SQL 控制台，查询在只读事务中执行，修改数据需要写入模式，每次执行都记录操作日志
+type SQLConsoleService struct {
+	db *gorm.DB
+}
+
+// Query 执行只读查询或 EXPLAIN
+func (s *SQLConsoleService) Query(ctx context.Context, req requests.SQLQueryRequest, audit *models.SystemLogInfo) (*sqlconsole.Result, error) {
+	action := "query"
+	if req.Explain {
+		action = "explain"
+	}
+
+	stmt, err := sqlconsole.Parse(req.SQL)
+	if err != nil {
+		s.record(audit, action, req.SQL, nil, err)
+		return nil, err
+	}
+
+	db, err := s.db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if req.Size == 0 {
+		req.Size = common.SQLConsolePageSize
+	}
+	result, err := sqlconsole.Query(ctx, db, stmt, sqlconsole.QueryOptions{
+		Page:    req.Page,
+		Size:    req.Size,
+		Explain: req.Explain,
+		Analyze: req.Analyze,
+		Timeout: consoleTimeout(req.Timeout),
+	})
+	s.record(audit, action, req.SQL, result, err)
+	return result, err
+}
+
+// Execute 以写入模式执行语句，commit 为 false 时执行后回滚，只返回影响的行数和部分影响的行
+func (s *SQLConsoleService) Execute(ctx context.Context, req requests.SQLExecRequest, audit *models.SystemLogInfo) (*sqlconsole.Result, error) {
+	action := "preview"
+	if req.Commit {
+		action = "write"
+	}
+
+	stmt, err := sqlconsole.Parse(req.SQL)
+	if err != nil {
+		s.record(audit, action, req.SQL, nil, err)
+		return nil, err
+	}
+
+	db, err := s.db.DB()
+	if err != nil {
+		return nil, err
+	}
+	result, err := sqlconsole.Execute(ctx, db, stmt, sqlconsole.ExecOptions{
+		Commit:  req.Commit,
+		Preview: common.SQLConsolePreviewRows,
+		Timeout: consoleTimeout(req.Timeout),
+	})
+	s.record(audit, action, req.SQL, result, err)
+	if err == nil && result.Committed {
+		logger.Warn("SQL 控制台提交了写入", zap.Int("operator", audit.OperatorID), zap.String("sql", req.SQL), zap.Int64("rows", result.RowsAffected))
+	}
+	return result, err
+}
+
+// record 保存执行结果到操作日志，保存失败只记录日志
+func (s *SQLConsoleService) record(audit *models.SystemLogInfo, action, sql string, result *sqlconsole.Result, err error) {
+	audit.Module = "database"
+	audit.Action = action
+	audit.Params = sql
+	switch {
+	case err != nil:
+		audit.Message = "执行失败: " + err.Error()
+	case result.Plan != nil:
+		audit.Message = fmt.Sprintf("执行计划，耗时 %dms", result.Duration)
+	case action == "query":
+		audit.Message = fmt.Sprintf("返回 %d 行，耗时 %dms", len(result.Rows), result.Duration)
+	case result.Committed:
+		audit.Message = fmt.Sprintf("已提交，影响 %d 行，耗时 %dms", result.RowsAffected, result.Duration)
+	default:
+		audit.Message = fmt.Sprintf("预览，影响 %d 行，已回滚，耗时 %dms", result.RowsAffected, result.Duration)
+	}
+
+	if err := s.db.Model(&models.SystemLogInfo{}).Create(audit).Error; err != nil {
+		logger.Error("保存 SQL 控制台操作日志失败", zap.Error(err))
+	}
+}
+
+// consoleTimeout 将请求中的秒数转为超时时间，为0时使用默认值
+func consoleTimeout(seconds int) time.Duration {
+	if seconds <= 0 {
+		return common.SQLConsoleTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// NewSQLConsoleService 创建 SQL 控制台服务实例
+func NewSQLConsoleService() *SQLConsoleService {
+	return &SQLConsoleService{db: configs.DB}
+}
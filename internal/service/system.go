@@ -6,6 +6,7 @@ import (
 	"blog/internal/job"
 	"blog/pkg/configs"
 	"blog/pkg/logger"
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -86,29 +87,41 @@ func (fs *SystemService) ClearFileContent(path string) error {
 	return os.Truncate(path, 0)
 }
 
-func (fs *SystemService) autoCreateFileLog() {
+func (fs *SystemService) autoCreateFileLog(ctx context.Context) error {
 	var logConfig = configs.CONFIG.Logger
 
 	var logPath = filepath.Join(logConfig.LoggerDir, logConfig.DefaultName)
 
 	var fileName = time.Now().Add(-time.Minute).Format("2006-01-02") + ".log"
 
-	var file, err = os.OpenFile(filepath.Join(logConfig.LoggerDir, fileName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.ModePerm)
+	if err := job.Check(ctx); err != nil {
+		return err
+	}
 
-	defer file.Close()
+	var file, err = os.OpenFile(filepath.Join(logConfig.LoggerDir, fileName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.ModePerm)
 
 	if err != nil {
 		logger.Info("创建日志文件失败", zap.String("error", err.Error()))
-		return
+		return err
 	}
 
-	fs.ClearFileContent(logPath)
+	defer file.Close()
+
+	buff, err := os.ReadFile(logPath)
+	if err != nil {
+		return err
+	}
 
-	var buff, _ = os.ReadFile(logPath)
+	if _, err := file.Write(buff); err != nil {
+		return err
+	}
 
-	file.WriteString(string(buff))
+	if err := fs.ClearFileContent(logPath); err != nil {
+		return err
+	}
 
 	logger.Info("已重新创建日志", zap.String("file_name", fileName))
+	return nil
 }
 
 // NewSystemService 创建新的 SystemService 实例
@@ -117,8 +130,8 @@ func NewSystemService() *SystemService {
 
 	if configs.CONFIG.Server.Cron {
 		job.AddJob(job.Job{
-			Hour:        0,
-			Eq:          true,
+			Name:        "rotate_log",
+			Spec:        "0 0 * * *",
 			Description: "自动创建日志",
 			Job:         service.autoCreateFileLog,
 		})
@@ -6,6 +6,8 @@ import (
 	"blog/internal/models"
 	"blog/internal/repository"
 	"blog/internal/utils"
+	"blog/pkg/background"
+	"blog/pkg/cache"
 	"blog/pkg/common"
 	"blog/pkg/configs"
 	"blog/pkg/logger"
@@ -13,7 +15,6 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/go-redis/redis"
 	"go.uber.org/zap"
 )
 
@@ -39,11 +40,11 @@ func (t *TagService) RandomTags() []response.SimpleTagResponse {
 	}
 
 	tagList := t.repository.GetTagList()
-	go func() {
+	background.Go(func() {
 		if err := t.cache.SetTags(tagList); err != nil {
 			logger.Error("缓存随机标签失败", zap.Error(err))
 		}
-	}()
+	})
 
 	logger.Info("添加随机标签缓存")
 	return tagList
@@ -70,26 +71,51 @@ func (t *TagService) SaveTag(name string) error {
 		return fmt.Errorf("保存标签失败: %w", err)
 	}
 
-	go func() {
+	background.Go(func() {
 		if err := t.cache.ClearTagKeys(); err != nil {
 			logger.Error("清除标签缓存失败", zap.Error(err))
 		}
-	}()
+	})
 
 	return nil
 }
 
+// EnsureTags 按名称获取标签ID，不存在的标签自动创建
+func (t *TagService) EnsureTags(names []string) ([]int, error) {
+	var (
+		ids     = make([]int, 0, len(names))
+		created bool
+	)
+	for _, name := range names {
+		tag, changed, err := t.repository.FindOrCreateTag(name)
+		if err != nil {
+			return nil, fmt.Errorf("创建标签 %s 失败: %w", name, err)
+		}
+		created = created || changed
+		ids = append(ids, tag.ID)
+	}
+
+	if created {
+		background.Go(func() {
+			if err := t.cache.ClearTagKeys(); err != nil {
+				logger.Error("清除标签缓存失败", zap.Error(err))
+			}
+		})
+	}
+	return ids, nil
+}
+
 // UpdateTag 更新标签
 func (t *TagService) UpdateTag(req requests.TagRequest) error {
 	if err := t.repository.UpdateTag(req.ID, req.Name); err != nil {
 		return fmt.Errorf("更新标签失败: %w", err)
 	}
 
-	go func() {
+	background.Go(func() {
 		if err := t.cache.ClearTagKeys(); err != nil {
 			logger.Error("清除标签缓存失败", zap.Error(err))
 		}
-	}()
+	})
 
 	return nil
 }
@@ -100,11 +126,11 @@ func (t *TagService) DeleteByIDs(ids []int64) error {
 		return fmt.Errorf("删除标签失败: %w", err)
 	}
 
-	go func() {
+	background.Go(func() {
 		if err := t.cache.ClearTagKeys(); err != nil {
 			logger.Error("清除标签缓存失败", zap.Error(err))
 		}
-	}()
+	})
 
 	return nil
 }
@@ -115,11 +141,11 @@ func (t *TagService) UnDeleteByIDs(ids []int64) error {
 		return fmt.Errorf("恢复标签失败: %w", err)
 	}
 
-	go func() {
+	background.Go(func() {
 		if err := t.cache.ClearTagKeys(); err != nil {
 			logger.Error("清除标签缓存失败", zap.Error(err))
 		}
-	}()
+	})
 
 	return nil
 }
@@ -129,11 +155,11 @@ func (t *TagService) GetTagByID(id int) *response.SimpleTagResponse {
 	result, err := t.cache.GetTagFromMap(id)
 	if err != nil {
 		result = t.repository.GetTagByID(id)
-		go func() {
+		background.Go(func() {
 			if err := t.cache.SetTagToMap(id, result); err != nil {
 				logger.Error("缓存标签信息失败", zap.Error(err), zap.Int("tagID", id))
 			}
-		}()
+		})
 		logger.Info("缓存标签信息", zap.Any("标签信息", result))
 	}
 	return result
@@ -151,17 +177,17 @@ func (t *TagService) GetTagIdBlogs(req requests.RequestQuery, page *response.Pag
 
 // TagCache 标签缓存
 type TagCache struct {
-	redis *redis.Client
+	client cache.Cache
 }
 
 // ClearTagKeys 清除标签相关的所有缓存
 func (t *TagCache) ClearTagKeys() error {
-	return t.redis.Del(common.TagMapKey, common.RandomTagListKey).Err()
+	return t.client.Del(common.TagMapKey, common.RandomTagListKey)
 }
 
 // GetRandomTags 获取随机标签
 func (t *TagCache) GetRandomTags() ([]response.SimpleTagResponse, error) {
-	r := t.redis.SRandMemberN(common.RandomTagListKey, common.RandomTagCount).Val()
+	r, _ := t.client.SRandMemberN(common.RandomTagListKey, common.RandomTagCount)
 	if len(r) == 0 {
 		return nil, errors.New("获取随机标签失败")
 	}
@@ -179,16 +205,15 @@ func (t *TagCache) SetTags(tags []response.SimpleTagResponse) error {
 		return errors.New("标签列表为空")
 	}
 
-	jsons := make([]interface{}, len(tags))
+	jsons := make([]string, len(tags))
 	for i, tag := range tags {
 		jsons[i] = utils.Serialize(tag)
 	}
 
-	pipe := t.redis.Pipeline()
-	pipe.SAdd(common.RandomTagListKey, jsons...)
-	pipe.Expire(common.RandomTagListKey, common.RandomTagListExpire)
-	_, err := pipe.Exec()
-	return err
+	if err := t.client.SAdd(common.RandomTagListKey, jsons...); err != nil {
+		return err
+	}
+	return t.client.Expire(common.RandomTagListKey, common.RandomTagListExpire)
 }
 
 // SetTagToMap 将标签信息存入缓存map
@@ -196,12 +221,12 @@ func (t *TagCache) SetTagToMap(id int, tag *response.SimpleTagResponse) error {
 	if tag == nil {
 		return errors.New("标签信息为空")
 	}
-	return t.redis.HSet(common.TagMapKey, strconv.Itoa(id), utils.Serialize(tag)).Err()
+	return t.client.HSet(common.TagMapKey, strconv.Itoa(id), utils.Serialize(tag))
 }
 
 // GetTagFromMap 从缓存map中获取标签信息
 func (t *TagCache) GetTagFromMap(tagID int) (*response.SimpleTagResponse, error) {
-	val, err := t.redis.HGet(common.TagMapKey, strconv.Itoa(tagID)).Result()
+	val, err := t.client.HGet(common.TagMapKey, strconv.Itoa(tagID))
 	if err != nil {
 		return nil, fmt.Errorf("从缓存获取标签信息失败: %w", err)
 	}
@@ -210,5 +235,5 @@ func (t *TagCache) GetTagFromMap(tagID int) (*response.SimpleTagResponse, error)
 
 // NewTagCache 创建标签缓存实例
 func NewTagCache() *TagCache {
-	return &TagCache{redis: configs.REDIS}
+	return &TagCache{client: cache.Observe(configs.CACHE, "tag")}
 }
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"blog/internal/dto/requests"
+	"blog/internal/dto/response"
+	"blog/internal/models"
+	"blog/internal/repository"
+	"blog/internal/utils"
+	"blog/pkg/background"
+	"blog/pkg/cache"
+	"blog/pkg/common"
+	"blog/pkg/configs"
+	"blog/pkg/logger"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AccessTokenService 个人访问令牌服务
+type AccessTokenService struct {
+	repository *repository.AccessTokenRepository
+	cache      *AccessTokenCache
+}
+
+// CreateAccessToken 创建个人访问令牌，令牌明文只在此处返回一次
+func (a *AccessTokenService) CreateAccessToken(uid int, request requests.AccessTokenRequest) (response.CreateAccessTokenResponse, error) {
+	for _, scope := range request.Scopes {
+		if !slices.Contains(common.AccessTokenScopes, scope) {
+			return response.CreateAccessTokenResponse{}, fmt.Errorf("无效的权限范围: %s", scope)
+		}
+	}
+
+	count, err := a.repository.CountByUserId(uid)
+	if err != nil {
+		return response.CreateAccessTokenResponse{}, err
+	}
+
+	if count >= common.AccessTokenMaxCount {
+		return response.CreateAccessTokenResponse{}, fmt.Errorf("每个用户最多创建%d个令牌", common.AccessTokenMaxCount)
+	}
+
+	random, err := utils.RandomToken(20)
+	if err != nil {
+		return response.CreateAccessTokenResponse{}, fmt.Errorf("生成令牌失败: %w", err)
+	}
+
+	plain := common.AccessTokenPrefix + random
+
+	token := models.AccessToken{
+		UserID:    uid,
+		Name:      request.Name,
+		TokenHash: utils.HashToken(plain),
+		Prefix:    plain[:len(common.AccessTokenPrefix)+4],
+		Scopes:    strings.Join(slices.Compact(slices.Sorted(slices.Values(request.Scopes))), ","),
+	}
+
+	if request.ExpireDays > 0 {
+		token.ExpiresAt = time.Now().AddDate(0, 0, request.ExpireDays).Unix()
+	}
+
+	if err := a.repository.Save(&token); err != nil {
+		return response.CreateAccessTokenResponse{}, err
+	}
+
+	logger.Info("创建个人访问令牌成功", zap.Int("uid", uid), zap.String("name", token.Name), zap.String("scopes", token.Scopes))
+
+	return response.CreateAccessTokenResponse{AccessTokenResponse: token.ToVo(), Token: plain}, nil
+}
+
+// GetAccessTokens 获取用户的所有个人访问令牌
+func (a *AccessTokenService) GetAccessTokens(uid int) ([]response.AccessTokenResponse, error) {
+	tokens, err := a.repository.FindByUserId(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	var list = make([]response.AccessTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		list = append(list, token.ToVo())
+	}
+	return list, nil
+}
+
+// RevokeAccessToken 撤销个人访问令牌
+func (a *AccessTokenService) RevokeAccessToken(id, uid int) error {
+	hash, err := a.repository.DeleteByIdAndUserId(id, uid)
+	if err != nil {
+		return err
+	}
+
+	if err := a.cache.RemoveAccessToken(hash); err != nil {
+		logger.Info("删除令牌缓存失败", zap.String("error", err.Error()), zap.Int("id", id))
+	}
+
+	logger.Info("撤销个人访问令牌成功", zap.Int("uid", uid), zap.Int("id", id))
+	return nil
+}
+
+// ParseAccessToken 校验个人访问令牌，返回所属用户和权限范围
+func (a *AccessTokenService) ParseAccessToken(plain string, ip string) (*models.User, []string) {
+	hash := utils.HashToken(plain)
+
+	token, err := a.cache.GetAccessToken(hash)
+	if err != nil || token == nil {
+		dbToken, err := a.repository.FindByHash(hash)
+		if err != nil {
+			return nil, nil
+		}
+		token = &dbToken
+		if err := a.cache.SetAccessToken(hash, token); err != nil {
+			logger.Info("缓存个人访问令牌失败", zap.String("error", err.Error()), zap.Int("id", token.ID))
+		}
+	}
+
+	now := time.Now()
+
+	if token.ExpiresAt > 0 && token.ExpiresAt < now.Unix() {
+		return nil, nil
+	}
+
+	user := common.GetJwtUser(token.UserID)
+	if user == nil || !user.Status {
+		return nil, nil
+	}
+
+	if now.Unix()-token.LastUsedAt >= int64(common.AccessTokenTouchInterval/time.Second) {
+		token.LastUsedAt = now.Unix()
+		token.LastUsedIp = ip
+		background.Go(func() { a.touchAccessToken(hash, token) })
+	}
+
+	return user, token.ScopeList()
+}
+
+// touchAccessToken 更新令牌的最后使用时间
+func (a *AccessTokenService) touchAccessToken(hash string, token *models.AccessToken) {
+	if err := a.repository.UpdateLastUsed(token.ID, token.LastUsedAt, token.LastUsedIp); err != nil {
+		logger.Info("更新令牌使用时间失败", zap.String("error", err.Error()), zap.Int("id", token.ID))
+		return
+	}
+	a.cache.SetAccessToken(hash, token)
+}
+
+// NewAccessTokenService 创建个人访问令牌服务实例
+func NewAccessTokenService() *AccessTokenService {
+	service := &AccessTokenService{
+		repository: repository.NewAccessTokenRepository(),
+		cache:      NewAccessTokenCache(),
+	}
+
+	common.GetAccessToken = service.ParseAccessToken
+
+	return service
+}
+
+// AccessTokenCache 个人访问令牌缓存
+type AccessTokenCache struct {
+	client cache.Cache
+}
+
+// SetAccessToken 缓存个人访问令牌
+func (a *AccessTokenCache) SetAccessToken(hash string, token *models.AccessToken) error {
+	if token == nil {
+		return errors.New("令牌信息为空")
+	}
+	return a.client.Set(common.AccessTokenKey+hash, utils.Serialize(token), common.AccessTokenKeyExpire)
+}
+
+// GetAccessToken 从缓存获取个人访问令牌
+func (a *AccessTokenCache) GetAccessToken(hash string) (*models.AccessToken, error) {
+	val, err := a.client.Get(common.AccessTokenKey + hash)
+	if err != nil {
+		return nil, fmt.Errorf("从缓存获取令牌失败: %w", err)
+	}
+	return utils.Deserialize[*models.AccessToken](val), nil
+}
+
+// RemoveAccessToken 删除个人访问令牌缓存
+func (a *AccessTokenCache) RemoveAccessToken(hash string) error {
+	return a.client.Del(common.AccessTokenKey + hash)
+}
+
+// NewAccessTokenCache 创建个人访问令牌缓存实例
+func NewAccessTokenCache() *AccessTokenCache {
+	return &AccessTokenCache{client: cache.Observe(configs.CACHE, "token")}
+}
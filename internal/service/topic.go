@@ -6,6 +6,8 @@ import (
 	"blog/internal/models"
 	"blog/internal/repository"
 	"blog/internal/utils"
+	"blog/pkg/background"
+	"blog/pkg/cache"
 	"blog/pkg/common"
 	"blog/pkg/configs"
 	"blog/pkg/logger"
@@ -13,7 +15,6 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/go-redis/redis"
 	"go.uber.org/zap"
 )
 
@@ -44,7 +45,7 @@ func (t *TopicService) GetTopicByPage(pindex int) *response.Page {
 
 	pageInfo.Data = list
 
-	go t.cache.SetPageInfo(pindex, pageInfo)
+	background.Go(func() { t.cache.SetPageInfo(pindex, pageInfo) })
 
 	return pageInfo
 }
@@ -65,26 +66,45 @@ func (t *TopicService) SaveTopic(uid int, req requests.TopicRequest) error {
 		return fmt.Errorf("创建专题失败: %w", err)
 	}
 
-	go func() {
+	background.Go(func() {
 		if err := t.cache.ClearTopicKeys(); err != nil {
 			logger.Error("清除专题缓存失败", zap.Error(err))
 		}
-	}()
+	})
 
 	return nil
 }
 
+// EnsureTopic 返回名称对应的专题ID，不存在时为 uid 创建；专题属于其他用户时返回错误
+func (t *TopicService) EnsureTopic(uid int, name string) (int, error) {
+	topic, changed, err := t.repository.FindOrCreateTopic(uid, name)
+	if err != nil {
+		return 0, err
+	}
+	if changed {
+		background.Go(func() {
+			if err := t.cache.ClearTopicKeys(); err != nil {
+				logger.Error("清除专题缓存失败", zap.Error(err))
+			}
+		})
+	}
+	if topic.UserID != uid {
+		return 0, fmt.Errorf("专题 %s 属于其他用户", name)
+	}
+	return topic.ID, nil
+}
+
 // UpdateTopic 更新专题
 func (t *TopicService) UpdateTopic(uid int, req requests.TopicRequest) error {
 	if err := t.repository.Update(req.ToModel(uid)); err != nil {
 		return fmt.Errorf("更新专题失败: %w", err)
 	}
 
-	go func() {
+	background.Go(func() {
 		if err := t.cache.ClearTopicKeys(); err != nil {
 			logger.Error("清除专题缓存失败", zap.Error(err))
 		}
-	}()
+	})
 
 	return nil
 }
@@ -95,14 +115,14 @@ func (t *TopicService) DeleteByIDs(ids []int64) error {
 		return fmt.Errorf("删除专题失败: %w", err)
 	}
 
-	go func() {
+	background.Go(func() {
 		if err := t.cache.ClearTopicKeys(); err != nil {
 			logger.Error("清除专题缓存失败", zap.Error(err))
 		}
 		if err := t.repository.DeleteTopicBlogs(ids); err != nil {
 			logger.Error("删除专题博客失败", zap.Error(err))
 		}
-	}()
+	})
 
 	return nil
 }
@@ -113,14 +133,14 @@ func (t *TopicService) UnDeleteByIDs(ids []int64) error {
 		return fmt.Errorf("恢复专题失败: %w", err)
 	}
 
-	go func() {
+	background.Go(func() {
 		if err := t.cache.ClearTopicKeys(); err != nil {
 			logger.Error("清除专题缓存失败", zap.Error(err))
 		}
 		if err := t.repository.UndeleteTopicBlogs(ids); err != nil {
 			logger.Error("恢复专题博客失败", zap.Error(err))
 		}
-	}()
+	})
 
 	return nil
 }
@@ -153,11 +173,11 @@ func (t *TopicService) GetTopicInfo(id int) *response.SimpleTopicResponse {
 	result, err := t.cache.GetTopicFromMap(id)
 	if err != nil {
 		result = t.repository.FindById(id)
-		go func() {
+		background.Go(func() {
 			if err := t.cache.SetTopicToMap(id, result); err != nil {
 				logger.Error("缓存专题信息失败", zap.Error(err), zap.Int("topicID", id))
 			}
-		}()
+		})
 		logger.Info("缓存专题信息", zap.Any("专题信息", result))
 	}
 	return result
@@ -173,7 +193,8 @@ func NewTopicService() *TopicService {
 
 // TopicCache 专题缓存
 type TopicCache struct {
-	redis *redis.Client
+	client cache.Cache
+	pages  *cache.Namespace // 专题分页的命名空间
 }
 
 // SetTopicToMap 将专题信息存入缓存
@@ -181,18 +202,20 @@ func (t *TopicCache) SetTopicToMap(id int, topic *response.SimpleTopicResponse)
 	if topic == nil {
 		return errors.New("专题信息为空")
 	}
-	return t.redis.HSet(common.TopicMapKey, strconv.Itoa(id), utils.Serialize(topic)).Err()
+	return t.client.HSet(common.TopicMapKey, strconv.Itoa(id), utils.Serialize(topic))
 }
 
 // ClearTopicKeys 清除专题相关的所有缓存
 func (t *TopicCache) ClearTopicKeys() error {
-	var keys = t.redis.Keys(common.TopicPageKey + "*").Val()
-	return t.redis.Del(append(keys, common.TopicMapKey)...).Err()
+	if err := t.pages.Invalidate(); err != nil {
+		return err
+	}
+	return t.client.Del(common.TopicMapKey)
 }
 
 // GetTopicFromMap 从缓存获取专题信息
 func (t *TopicCache) GetTopicFromMap(topicID int) (*response.SimpleTopicResponse, error) {
-	val, err := t.redis.HGet(common.TopicMapKey, strconv.Itoa(topicID)).Result()
+	val, err := t.client.HGet(common.TopicMapKey, strconv.Itoa(topicID))
 	if err != nil {
 		return nil, fmt.Errorf("从缓存获取专题信息失败: %w", err)
 	}
@@ -201,15 +224,21 @@ func (t *TopicCache) GetTopicFromMap(topicID int) (*response.SimpleTopicResponse
 
 // SetPageInfo 将博客列表存入redis
 func (b *TopicCache) SetPageInfo(page int, pageInfo *response.Page) error {
-	key := fmt.Sprintf(common.TopicPageKey+"%d", page)
+	key, err := b.pages.Key(strconv.Itoa(page))
+	if err != nil {
+		return err
+	}
 	str := utils.Serialize(pageInfo)
-	return b.redis.Set(key, str, common.PageInfoExpire).Err()
+	return b.client.Set(key, str, common.PageInfoExpire)
 }
 
 // GetPageInfo 从redis获取博客列表
 func (b *TopicCache) GetPageInfo(page int) (*response.Page, error) {
-	key := fmt.Sprintf(common.TopicPageKey+"%d", page)
-	str := b.redis.Get(key).Val()
+	key, err := b.pages.Key(strconv.Itoa(page))
+	if err != nil {
+		return nil, err
+	}
+	str, _ := b.client.Get(key)
 	if str == "" {
 		return nil, errors.New("not found")
 	}
@@ -218,5 +247,8 @@ func (b *TopicCache) GetPageInfo(page int) (*response.Page, error) {
 
 // NewTopicCache 创建专题缓存实例
 func NewTopicCache() *TopicCache {
-	return &TopicCache{redis: configs.REDIS}
+	return &TopicCache{
+		client: cache.Observe(configs.CACHE, "topic"),
+		pages:  cache.NewNamespace(configs.CACHE, common.TopicPageKey),
+	}
 }
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"archive/zip"
+	"blog/internal/job"
+	"blog/internal/models"
+	"blog/internal/transfer"
+	"blog/pkg/common"
+	"blog/pkg/configs"
+	"blog/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+// transferTables 可导出和导入的表，按导入顺序排列，被引用的表在前
+var transferTables = []transfer.Table{
+	{Name: models.RoleTable, Key: "id", Natural: []string{"name"}},
+	// 密码不导出，导入的新用户需要重置密码；已有用户的邮箱、角色和状态不会被导入覆盖
+	{Name: models.UserTable, Key: "id", Natural: []string{"username"},
+		Refs:      map[string]transfer.Ref{"role_id": {Table: models.RoleTable}},
+		Secrets:   map[string]any{"password": ""},
+		Protected: []string{"email", "role_id", "status", "verified"}},
+	{Name: models.CategoryTable, Key: "id", Natural: []string{"name"}},
+	{Name: models.TagTable, Key: "id", Natural: []string{"name"}},
+	{Name: models.TopicTable, Key: "id", Natural: []string{"name"},
+		Refs: map[string]transfer.Ref{"user_id": {Table: models.UserTable}}},
+	{Name: models.FileInfoMd5Table, Natural: []string{"md5"}},
+	{Name: models.FileInfoTable, Key: "id", Natural: []string{"new_name"},
+		Refs: map[string]transfer.Ref{"user_id": {Table: models.UserTable, Nullable: true}}},
+	{Name: models.BlogTable, Key: "id", Natural: []string{"title", "created_at"}, Counters: []string{"eye_count"},
+		Refs: map[string]transfer.Ref{
+			"user_id":     {Table: models.UserTable},
+			"category_id": {Table: models.CategoryTable, Nullable: true},
+			"topic_id":    {Table: models.TopicTable, Nullable: true},
+		}},
+	{Name: models.BlogTagTable, Natural: []string{"blog_id", "tag_id"},
+		Refs: map[string]transfer.Ref{"blog_id": {Table: models.BlogTable}, "tag_id": {Table: models.TagTable}}},
+	{Name: models.EyeCountTable, Natural: []string{"id"}, Counters: []string{"count"}},
+	{Name: models.BlogDailyViewTable, Natural: []string{"date", "blog_id"}, Counters: []string{"pv", "uv"},
+		Refs: map[string]transfer.Ref{"blog_id": {Table: models.BlogTable}}},
+	{Name: models.ViewRollupTable, Natural: []string{"hour", "dimension", "value"}, Counters: []string{"count"}},
+}
+
+// TransferService 站点内容的导出与导入服务，用于在不同站点之间迁移数据
+type TransferService struct {
+	blogs *BlogService
+}
+
+// Tables 可导出和导入的表名
+func (s *TransferService) Tables() []string {
+	names := make([]string, len(transferTables))
+	for i, table := range transferTables {
+		names[i] = table.Name
+	}
+	return names
+}
+
+// selectTables 按名称选择表并保持导入顺序，names 为空时选择全部
+func (s *TransferService) selectTables(names []string) ([]transfer.Table, error) {
+	if len(names) == 0 {
+		return transferTables, nil
+	}
+	for _, name := range names {
+		if !slices.Contains(s.Tables(), name) {
+			return nil, fmt.Errorf("不支持导出的表: %s", name)
+		}
+	}
+	var tables []transfer.Table
+	for _, table := range transferTables {
+		if slices.Contains(names, table.Name) {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
+
+// CheckTables 检查表名是否都支持导出
+func (s *TransferService) CheckTables(names []string) error {
+	_, err := s.selectTables(names)
+	return err
+}
+
+// Export 将选择的表导出为 zip 写入 w，names 为空时导出全部
+func (s *TransferService) Export(ctx context.Context, w io.Writer, names []string) (*transfer.Manifest, error) {
+	tables, err := s.selectTables(names)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := transfer.Export(ctx, configs.DB, w, tables)
+	if err != nil {
+		logger.Error("导出站点数据失败", zap.Error(err))
+		return nil, err
+	}
+	logger.Info("导出站点数据完成", zap.Int("tables", len(manifest.Tables)))
+	return manifest, nil
+}
+
+// Import 导入导出文件，与备份和恢复共用任务锁，避免同时写入
+func (s *TransferService) Import(ctx context.Context, path string, opts transfer.ImportOptions) (*transfer.Report, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开导出文件失败: %w", err)
+	}
+	defer zr.Close()
+
+	var report *transfer.Report
+	err = job.WithLock(common.BackupLock, func(ctx context.Context) error {
+		var err error
+		report, err = transfer.Import(ctx, configs.DB, &zr.Reader, transferTables, opts)
+		return err
+	})
+	if errors.Is(err, job.ErrRunning) {
+		return nil, errors.New("备份或恢复正在执行，请稍后再导入")
+	}
+	if err != nil {
+		logger.Error("导入站点数据失败", zap.Error(err))
+		return nil, err
+	}
+	logger.Info("导入站点数据完成", zap.String("strategy", report.Strategy), zap.Bool("dryRun", report.DryRun))
+
+	if !report.DryRun {
+		if err := s.blogs.Reload(); err != nil {
+			logger.Error("导入后刷新缓存和搜索索引失败", zap.Error(err))
+		}
+	}
+	return report, nil
+}
+
+// NewTransferService 创建站点内容导出与导入服务实例
+func NewTransferService() *TransferService {
+	return &TransferService{blogs: NewBlogService()}
+}
@@ -7,15 +7,19 @@ import (
 	"blog/internal/models"
 	"blog/internal/repository"
 	"blog/internal/utils"
+	"blog/pkg/background"
+	"blog/pkg/cache"
 	"blog/pkg/common"
 	"blog/pkg/configs"
 	"blog/pkg/logger"
 	"blog/pkg/smail"
+	"crypto/subtle"
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
-	"github.com/go-redis/redis"
 	"go.uber.org/zap"
 )
 
@@ -25,24 +29,93 @@ type UserService struct {
 	cache *UserCache
 }
 
+// ErrEmailTooFrequent 验证码发送过于频繁
+var ErrEmailTooFrequent = errors.New("验证码发送过于频繁，请稍后再试")
+
 // SendCodeToEmail 发送注册验证码
 func (u *UserService) SendCodeToEmail(email string) error {
-	code := utils.RandomNumberCode()
+	return u.sendEmailCode(email, "注册验证码")
+}
 
-	if err := smail.SendEmail(email, "注册验证码", false, code); err != nil {
-		logger.Info("发送邮箱验证码失败", zap.String("error", err.Error()), zap.String("email", email))
+// SendVerifyEmail 为已注册但未验证邮箱的用户发送验证码
+func (u *UserService) SendVerifyEmail(user *models.User) error {
+	if user.Verified {
+		return errors.New("邮箱已验证")
+	}
+	return u.sendEmailCode(user.Email, "邮箱验证码")
+}
+
+// VerifyEmail 校验验证码并标记用户邮箱已验证
+func (u *UserService) VerifyEmail(user *models.User, code string) error {
+	if !u.checkEmailCode(user.Email, code) {
+		return errors.New("邮箱验证码错误")
+	}
+
+	if err := u.dao.UpdateVerified(user.ID, true); err != nil {
+		logger.Info("更新邮箱验证状态失败", zap.String("error", err.Error()), zap.Int("UserID", user.ID))
+		return err
+	}
+
+	background.Go(func() { u.cache.ClearUserInfoByID(user.ID) })
+
+	logger.Info("邮箱验证成功", zap.Int("UserID", user.ID), zap.String("email", user.Email))
+	return nil
+}
+
+// sendEmailCode 发送邮箱验证码，同一邮箱在冷却时间内只能发送一次
+func (u *UserService) sendEmailCode(email, subject string) error {
+	mail := configs.CONFIG.Mail
+
+	ok, err := u.cache.LockEmailResend(email, mail.GetResendInterval())
+	if err != nil {
 		return fmt.Errorf("发送验证码失败: %w", err)
 	}
 
-	if err := u.cache.SetEmailCode(code, email); err != nil {
+	if !ok {
+		return ErrEmailTooFrequent
+	}
+
+	code := utils.RandomNumberCode()
+
+	if err := u.cache.SetEmailCode(code, email, mail.GetCodeExpire()); err != nil {
 		logger.Info("缓存邮箱验证码失败", zap.String("error", err.Error()), zap.String("email", email))
 		return fmt.Errorf("保存验证码失败: %w", err)
 	}
 
+	text := fmt.Sprintf("您的验证码为 %s，%d 分钟内有效。", code, int(mail.GetCodeExpire().Minutes()))
+
+	if err := smail.SendEmail(email, subject, false, text); err != nil {
+		logger.Info("发送邮箱验证码失败", zap.String("error", err.Error()), zap.String("email", email))
+		u.cache.DeleteKeys([]string{common.EmailResendKey + email})
+		return fmt.Errorf("发送验证码失败: %w", err)
+	}
+
 	logger.Info("发送邮箱验证码成功", zap.String("email", email))
 	return nil
 }
 
+// checkEmailCode 校验邮箱验证码，校验成功或输错次数达到上限后验证码失效
+func (u *UserService) checkEmailCode(email, code string) bool {
+	cacheCode := u.cache.GetEmailCode(email)
+	if code == "" || cacheCode == "" {
+		return false
+	}
+
+	if subtle.ConstantTimeCompare([]byte(cacheCode), []byte(code)) != 1 {
+		count, err := u.cache.IncrEmailCodeFail(email, configs.CONFIG.Mail.GetCodeExpire())
+		if err != nil {
+			logger.Info("记录验证码输错次数失败", zap.String("error", err.Error()), zap.String("email", email))
+		} else if count >= common.EmailCodeMaxAttempt {
+			u.cache.RemoveEmailCode(email)
+			logger.Warn("验证码输错次数过多，验证码已失效", zap.String("email", email), zap.Int64("count", count))
+		}
+		return false
+	}
+
+	u.cache.RemoveEmailCode(email)
+	return true
+}
+
 // GetWebSiteConfig 获取网站配置
 func (u *UserService) GetWebSiteConfig() response.BlogConfigInfo {
 	config := u.cache.GetWebSiteConfig()
@@ -78,12 +151,13 @@ func (u *UserService) GetAdminUserList(req requests.UserAdminFilter, page *respo
 }
 
 // GetRedisKeys 获取所有Redis里的key
-func (u *UserService) GetRedisKeys() ([]string, error) {
-	keys := u.cache.GetAllKey()
-	if len(keys) == 0 {
-		return nil, errors.New("未找到任何键")
+func (u *UserService) GetRedisKeys(cursor uint64, match string, count int64) (response.ScanKeysResponse, error) {
+	keys, next, err := u.cache.ScanKeys(cursor, match, count)
+	if err != nil {
+		logger.Info("获取Redis键失败", zap.String("error", err.Error()), zap.String("match", match))
+		return response.ScanKeysResponse{}, fmt.Errorf("获取Redis键失败: %w", err)
 	}
-	return keys, nil
+	return response.ScanKeysResponse{Keys: keys, Cursor: next}, nil
 }
 
 // DeleteRedisKeys 删除指定的Redis键
@@ -97,23 +171,25 @@ func (u *UserService) DeleteRedisKeys(keys []string) error {
 
 // DeleteMatchKeys 删除匹配的Redis键
 func (u *UserService) DeleteMatchKeys(key string) error {
-	if err := u.cache.MatchDelete(key); err != nil {
+	count, err := u.cache.MatchDelete(key)
+	if err != nil {
 		logger.Info("删除匹配的Redis键失败", zap.String("error", err.Error()), zap.String("key", key))
 		return fmt.Errorf("删除匹配的Redis键失败: %w", err)
 	}
+	logger.Info("删除匹配的Redis键成功", zap.String("key", key), zap.Int("count", count))
 	return nil
 }
 
 // RegisteredUser 用户注册
 func (u *UserService) RegisteredUser(ip string, req requests.UserRequest) (models.User, error) {
-	if common.EnableEmailCode {
-		cacheCode := u.cache.GetEmailCode(req.Email)
-		if cacheCode == "" || cacheCode != req.Code {
-			return models.User{}, errors.New("邮箱验证码错误")
-		}
+	verify := configs.CONFIG.Mail.Verify
+
+	if verify && !u.checkEmailCode(req.Email, req.Code) {
+		return models.User{}, errors.New("邮箱验证码错误")
 	}
 
 	user := req.ToUserModel(ip)
+	user.Verified = verify
 
 	if err := u.dao.Save(&user); err != nil {
 		logger.Info("用户注册失败", zap.String("error", err.Error()), zap.Any("user", user))
@@ -126,6 +202,33 @@ func (u *UserService) RegisteredUser(ip string, req requests.UserRequest) (model
 	return user, nil
 }
 
+// CreateUser 直接创建账号，账号或邮箱已存在时返回错误
+func (u *UserService) CreateUser(req requests.CreateUserRequest) (models.User, error) {
+	if err := u.checkUnique(req); err != nil {
+		return models.User{}, err
+	}
+
+	user := req.ToUserModel()
+	if err := u.dao.Save(&user); err != nil {
+		logger.Info("创建用户失败", zap.String("error", err.Error()), zap.String("username", user.Username))
+		return models.User{}, err
+	}
+
+	logger.Info("创建用户成功", zap.Int("UserID", user.ID), zap.String("username", user.Username), zap.Uint("RoleID", user.RoleID))
+	return user, nil
+}
+
+// checkUnique 检查账号和邮箱是否已被使用
+func (u *UserService) checkUnique(req requests.CreateUserRequest) error {
+	if _, err := u.dao.FindByUsername(req.Username); err == nil {
+		return errors.New("账号已存在")
+	}
+	if _, err := u.dao.FindByEmail(req.Email); err == nil {
+		return errors.New("邮箱已被使用")
+	}
+	return nil
+}
+
 // UpdateUserStatus 更新用户登录状态
 func (u *UserService) UpdateUserStatus(dto dtos.UserLoginStatus) error {
 	if err := u.dao.UpdateLoginStatus(dto); err != nil {
@@ -143,12 +246,63 @@ func (u *UserService) ResetPassword(id int, password string) error {
 	var err = u.dao.UpdatePassword(id, hashPassword)
 
 	if err == nil {
-		go u.cache.RemoveToken(id)
+		background.Go(func() { u.cache.RemoveToken(id) })
 	}
 
 	return err
 }
 
+// ResetPasswordByUsername 根据账号重置密码，并让已登录的会话失效
+func (u *UserService) ResetPasswordByUsername(username, password string) error {
+	user, err := u.dao.FindByUsername(username)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+	if err := u.dao.UpdatePassword(user.ID, utils.EncryptPassword(password)); err != nil {
+		return err
+	}
+	if err := u.cache.RemoveToken(user.ID); err != nil {
+		logger.Warn("清除用户令牌失败", zap.Int("UserID", user.ID), zap.Error(err))
+	}
+
+	logger.Info("重置密码成功", zap.Int("UserID", user.ID))
+	return nil
+}
+
+// 旧版本启动时写入的超级管理员默认密码
+const legacyAdminPassword = "shuyu2001"
+
+// ExpireLegacyAdmin 旧版本启动时会写入使用固定密码的超级管理员，升级后仍使用默认密码时清空密码、禁用账号并降为普通用户，
+// 注销其登录状态和个人访问令牌；没有其他超级管理员时站点回到首次安装状态
+func (u *UserService) ExpireLegacyAdmin() {
+	user, err := u.dao.FindByUsername(common.LegacyAdminUsername)
+	if err != nil || user.Password == "" || !utils.VerifyPassword(user.Password, legacyAdminPassword) {
+		return
+	}
+	if err := u.dao.Disable(user.ID); err != nil {
+		logger.Error("禁用旧版内置管理员失败", zap.Int("UserID", user.ID), zap.Error(err))
+		return
+	}
+	u.cache.ClearUserInfoByID(user.ID)
+	if err := u.cache.RemoveToken(user.ID); err != nil {
+		logger.Warn("清除用户令牌失败", zap.Int("UserID", user.ID), zap.Error(err))
+	}
+
+	tokens := NewAccessTokenService()
+	list, err := tokens.GetAccessTokens(user.ID)
+	if err != nil {
+		logger.Error("查询旧版内置管理员的访问令牌失败", zap.Int("UserID", user.ID), zap.Error(err))
+	}
+	for _, token := range list {
+		if err := tokens.RevokeAccessToken(token.ID, user.ID); err != nil {
+			logger.Error("撤销旧版内置管理员的访问令牌失败", zap.Int("id", token.ID), zap.Error(err))
+		}
+	}
+
+	logger.Warn("旧版本内置的超级管理员仍在使用默认密码，已禁用并降为普通用户，请通过首次安装或其他超级管理员重新授权",
+		zap.String("username", user.Username))
+}
+
 func (u *UserService) UpdateRoleID(uid int, rid uint) error {
 	err := u.dao.UpdateUserRole(uid, rid)
 	if err != nil {
@@ -156,24 +310,50 @@ func (u *UserService) UpdateRoleID(uid int, rid uint) error {
 		return err
 	}
 
-	go u.cache.ClearUserInfoByID(uid)
+	background.Go(func() { u.cache.ClearUserInfoByID(uid) })
 
 	logger.Info("更新用户角色成功", zap.Int("UserID", uid), zap.Uint("RoleID", rid))
 	return nil
 }
 
+// LoginLockError 登录被锁定的错误
+type LoginLockError struct {
+	Code  common.Code // 锁定对应的状态码
+	Until int64       // 解锁时间
+}
+
+func (e *LoginLockError) Error() string {
+	return fmt.Sprintf("登录失败次数过多，请于%s后重试", utils.FormatDate(time.Unix(e.Until, 0)))
+}
+
 // Login 用户登录
-func (u *UserService) Login(request requests.LoginRequest) (response.TokenResponse, error) {
+func (u *UserService) Login(request requests.LoginRequest, ip string) (response.TokenResponse, error) {
+	if err := u.checkLoginLock(request.Username, ip); err != nil {
+		logger.Info("登录已被锁定", zap.String("username", request.Username), zap.String("ip", ip))
+		return response.TokenResponse{}, err
+	}
+
 	user, err := u.dao.FindByUsername(request.Username)
 	if err != nil {
 		logger.Info("用户登录失败", zap.String("username", request.Username), zap.String("error", err.Error()))
+		u.recordLoginFail(request.Username, ip, nil)
 		return response.TokenResponse{}, errors.New("用户未找到")
 	}
 
 	if !utils.VerifyPassword(user.Password, request.Password) {
+		u.recordLoginFail(request.Username, ip, &user)
 		return response.TokenResponse{}, errors.New("密码错误")
 	}
 
+	if err := u.cache.DeleteKeys([]string{common.LoginFailUserKey + user.Username}); err != nil {
+		logger.Info("清除登录失败次数失败", zap.String("error", err.Error()), zap.String("username", user.Username))
+	}
+
+	return u.issueToken(user)
+}
+
+// issueToken 为已通过验证的用户签发登录令牌
+func (u *UserService) issueToken(user models.User) (response.TokenResponse, error) {
 	if !user.Status {
 		return response.TokenResponse{}, errors.New("用户被禁用")
 	}
@@ -192,6 +372,135 @@ func (u *UserService) Login(request requests.LoginRequest) (response.TokenRespon
 	return response.TokenResponse{Token: token.Token, User: user.ToVo()}, nil
 }
 
+// VerifyPassword 重新验证已登录用户的密码，与登录共用失败计数和锁定
+func (u *UserService) VerifyPassword(uid int, password, ip string) error {
+	user, err := u.dao.FindById(uid)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+	if err := u.checkLoginLock(user.Username, ip); err != nil {
+		return err
+	}
+	if !utils.VerifyPassword(user.Password, password) {
+		u.recordLoginFail(user.Username, ip, &user)
+		return errors.New("密码错误")
+	}
+	return nil
+}
+
+// checkLoginLock 检查账号和IP是否处于锁定期
+func (u *UserService) checkLoginLock(username, ip string) error {
+	now := time.Now().Unix()
+
+	if lock := u.cache.GetLoginLock(common.LoginLockUser, username); lock != nil && lock.Until > now {
+		return &LoginLockError{Code: common.AccountLocked, Until: lock.Until}
+	}
+
+	if lock := u.cache.GetLoginLock(common.LoginLockIp, ip); lock != nil && lock.Until > now {
+		return &LoginLockError{Code: common.IpLocked, Until: lock.Until}
+	}
+
+	return nil
+}
+
+// recordLoginFail 记录登录失败次数，超过阈值后按指数退避锁定账号和IP
+func (u *UserService) recordLoginFail(username, ip string, user *models.User) {
+	count, err := u.cache.IncrLoginFail(common.LoginFailUserKey + username)
+	if err != nil {
+		logger.Info("记录账号登录失败次数失败", zap.String("error", err.Error()), zap.String("username", username))
+	} else if count >= common.LoginUserMaxAttempt {
+		lock := u.lockLogin(common.LoginLockUser, username, count, common.LoginUserMaxAttempt)
+		if user != nil {
+			locked := *user
+			background.Go(func() { u.notifyLoginLock(locked, lock) })
+		}
+	}
+
+	count, err = u.cache.IncrLoginFail(common.LoginFailIpKey + ip)
+	if err != nil {
+		logger.Info("记录IP登录失败次数失败", zap.String("error", err.Error()), zap.String("ip", ip))
+	} else if count >= common.LoginIpMaxAttempt {
+		u.lockLogin(common.LoginLockIp, ip, count, common.LoginIpMaxAttempt)
+	}
+}
+
+// lockLogin 锁定账号或IP，锁定时长随失败次数翻倍，最长不超过 LoginLockMax
+func (u *UserService) lockLogin(lockType, target string, failures, maxAttempt int64) response.LoginLockResponse {
+	duration := common.LoginLockMax
+	if exp := failures - maxAttempt; exp < 16 {
+		duration = min(common.LoginLockBase<<exp, common.LoginLockMax)
+	}
+
+	now := time.Now()
+	lock := response.LoginLockResponse{
+		Type:     lockType,
+		Target:   target,
+		Failures: failures,
+		LockedAt: now.Unix(),
+		Until:    now.Add(duration).Unix(),
+	}
+
+	if err := u.cache.SetLoginLock(lock); err != nil {
+		logger.Info("保存登录锁定信息失败", zap.String("error", err.Error()), zap.String("target", target))
+	}
+
+	logger.Warn("登录失败次数过多，已临时锁定", zap.String("type", lockType), zap.String("target", target),
+		zap.Int64("failures", failures), zap.Duration("duration", duration))
+	return lock
+}
+
+// notifyLoginLock 通知账号邮箱登录已被锁定
+func (u *UserService) notifyLoginLock(user models.User, lock response.LoginLockResponse) {
+	text := fmt.Sprintf("<p>您好，%s：</p><p>您的账号 %s 已连续 %d 次登录失败，为保护账号安全，已被临时锁定至 %s。</p><p>如果不是您本人操作，请尽快修改密码。</p>",
+		user.NickName, user.Username, lock.Failures, utils.FormatDate(time.Unix(lock.Until, 0)))
+
+	if err := smail.SendEmail(user.Email, "账号登录已被锁定", true, text); err != nil {
+		logger.Info("发送账号锁定通知失败", zap.String("error", err.Error()), zap.String("email", user.Email))
+	}
+}
+
+// GetLoginLocks 获取所有生效中的登录锁定
+func (u *UserService) GetLoginLocks() []response.LoginLockResponse {
+	now := time.Now().Unix()
+	locks := make([]response.LoginLockResponse, 0)
+	for _, lock := range u.cache.GetAllLoginLock() {
+		if lock.Until > now {
+			locks = append(locks, lock)
+		}
+	}
+	return locks
+}
+
+// ClearLoginLocks 解除登录锁定，keys 格式为 "user:账号" 或 "ip:IP"
+func (u *UserService) ClearLoginLocks(keys []string) error {
+	failKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lockType, target, ok := strings.Cut(key, ":")
+		if !ok {
+			return fmt.Errorf("无效的锁定键: %s", key)
+		}
+		switch lockType {
+		case common.LoginLockUser:
+			failKeys = append(failKeys, common.LoginFailUserKey+target)
+		case common.LoginLockIp:
+			failKeys = append(failKeys, common.LoginFailIpKey+target)
+		default:
+			return fmt.Errorf("无效的锁定类型: %s", lockType)
+		}
+	}
+
+	if err := u.cache.DeleteLoginLock(keys...); err != nil {
+		return fmt.Errorf("解除登录锁定失败: %w", err)
+	}
+
+	if err := u.cache.DeleteKeys(failKeys); err != nil {
+		return fmt.Errorf("清除登录失败次数失败: %w", err)
+	}
+
+	logger.Info("解除登录锁定成功", zap.Strings("keys", keys))
+	return nil
+}
+
 // GetUsetBlogByPage 获取用户博客分页
 func (u *UserService) GetUsetBlogByPage(prequest requests.RequestQuery, page *response.Page) error {
 	list, err := u.dao.GetUserBlogList(prequest, &page.Count)
@@ -260,7 +569,7 @@ func (u *UserService) UpdateUser(userRequest *requests.UpdateUserRequest) (*mode
 		return nil, fmt.Errorf("更新用户信息失败: %w", err)
 	}
 
-	go u.cache.ClearUserInfoByID(userModel.ID)
+	background.Go(func() { u.cache.ClearUserInfoByID(userModel.ID) })
 
 	logger.Info("更新用户信息成功", zap.Int("UserID", userModel.ID))
 	return &userModel, nil
@@ -281,28 +590,47 @@ func NewUserService() *UserService {
 
 // UserCache 用户缓存
 type UserCache struct {
-	redis *redis.Client
+	client cache.Cache
 }
 
 // SetEmailCode 缓存邮箱验证码
-func (u *UserCache) SetEmailCode(code, email string) error {
+func (u *UserCache) SetEmailCode(code, email string, expire time.Duration) error {
 	key := fmt.Sprintf(common.EmailCodeKey+"%s", email)
-	return u.redis.Set(key, code, common.EmailCodeKeyExpire).Err()
+	if err := u.client.Del(common.EmailCodeFailKey + email); err != nil {
+		return err
+	}
+	return u.client.Set(key, code, expire)
+}
+
+// RemoveEmailCode 删除邮箱验证码和输错次数
+func (u *UserCache) RemoveEmailCode(email string) error {
+	return u.client.Del(common.EmailCodeKey+email, common.EmailCodeFailKey+email)
+}
+
+// IncrEmailCodeFail 验证码输错次数加一，并返回当前次数
+func (u *UserCache) IncrEmailCodeFail(email string, expire time.Duration) (int64, error) {
+	return u.client.Incr(common.EmailCodeFailKey+email, expire)
+}
+
+// LockEmailResend 占用邮箱验证码的发送冷却，冷却期内返回 false
+func (u *UserCache) LockEmailResend(email string, interval time.Duration) (bool, error) {
+	return u.client.SetNX(common.EmailResendKey+email, "1", interval)
 }
 
 // GetEmailCode 获取邮箱验证码
 func (u *UserCache) GetEmailCode(email string) string {
 	key := fmt.Sprintf(common.EmailCodeKey+"%s", email)
-	return u.redis.Get(key).Val()
+	val, _ := u.client.Get(key)
+	return val
 }
 
 func (u *UserCache) ClearUserInfoByID(id int) error {
-	return u.redis.Del(fmt.Sprintf(common.UserInfoKey+"%d", id)).Err()
+	return u.client.Del(fmt.Sprintf(common.UserInfoKey+"%d", id))
 }
 
 // GetWebSiteConfig 获取网站配置
 func (u *UserCache) GetWebSiteConfig() response.BlogConfigInfo {
-	str := u.redis.Get(common.WebSiteConfigKey).Val()
+	str, _ := u.client.Get(common.WebSiteConfigKey)
 	if str == "" {
 		return response.GetDefaultBlogConfigInfo()
 	}
@@ -312,7 +640,7 @@ func (u *UserCache) GetWebSiteConfig() response.BlogConfigInfo {
 // SetWebSiteConfig 缓存网站信息
 func (u *UserCache) SetWebSiteConfig(siteConfig response.BlogConfigInfo) error {
 	str := utils.Serialize(siteConfig)
-	return u.redis.Set(common.WebSiteConfigKey, str, -1).Err()
+	return u.client.Set(common.WebSiteConfigKey, str, 0)
 }
 
 // SetUser 缓存用户信息
@@ -322,13 +650,13 @@ func (u *UserCache) SetUser(id int, user *models.User) error {
 	}
 
 	key := common.UserInfoKey + strconv.Itoa(id)
-	return u.redis.Set(key, utils.Serialize(user), common.UserInfoKeyExpire).Err()
+	return u.client.Set(key, utils.Serialize(user), common.UserInfoKeyExpire)
 }
 
 // GetUser 获取用户缓存
 func (u *UserCache) GetUser(id int) *models.User {
 	key := common.UserInfoKey + strconv.Itoa(id)
-	val := u.redis.Get(key).Val()
+	val, _ := u.client.Get(key)
 	if val == "" {
 		return nil
 	}
@@ -342,13 +670,14 @@ func (u *UserCache) SetToken(id int, token string) error {
 	}
 
 	key := fmt.Sprintf(common.UserTokenKey+"%d", id)
-	return u.redis.Set(key, token, common.TokenExpire).Err()
+	return u.client.Set(key, token, common.TokenExpire)
 }
 
 // GetToken 获取用户token
 func (u *UserCache) GetToken(id int) string {
 	key := fmt.Sprintf(common.UserTokenKey+"%d", id)
-	return u.redis.Get(key).Val()
+	val, _ := u.client.Get(key)
+	return val
 }
 
 // RemoveToken 删除用户token
@@ -356,12 +685,66 @@ func (u *UserCache) RemoveToken(uid int) error {
 	var uidStr = strconv.Itoa(uid)
 	key := common.UserTokenKey + uidStr
 	key2 := common.UserInfoKey + uidStr
-	return u.redis.Del(key, key2).Err()
+	return u.client.Del(key, key2)
 }
 
-// GetAllKey 获取所有Redis键
-func (u *UserCache) GetAllKey() []string {
-	return u.redis.Keys("*").Val()
+// IncrLoginFail 登录失败次数加一，并返回当前次数
+func (u *UserCache) IncrLoginFail(key string) (int64, error) {
+	return u.client.Incr(key, common.LoginFailExpire)
+}
+
+// SetLoginLock 保存登录锁定信息，到解锁时间后自动过期
+func (u *UserCache) SetLoginLock(lock response.LoginLockResponse) error {
+	expire := time.Until(time.Unix(lock.Until, 0))
+	if expire <= 0 {
+		return nil
+	}
+	return u.client.Set(common.LoginLockKey+lock.Type+":"+lock.Target, utils.Serialize(lock), expire)
+}
+
+// GetLoginLock 获取账号或IP的登录锁定信息
+func (u *UserCache) GetLoginLock(lockType, target string) *response.LoginLockResponse {
+	val, err := u.client.Get(common.LoginLockKey + lockType + ":" + target)
+	if err != nil {
+		return nil
+	}
+	return utils.Deserialize[*response.LoginLockResponse](val)
+}
+
+// GetAllLoginLock 获取所有登录锁定信息，使用 SCAN 分批遍历
+func (u *UserCache) GetAllLoginLock() []response.LoginLockResponse {
+	locks := make([]response.LoginLockResponse, 0)
+	var cursor uint64
+	for {
+		keys, next, err := u.client.Scan(cursor, common.LoginLockKey+"*", common.ScanBatchCount)
+		if err != nil {
+			return locks
+		}
+		for _, key := range keys {
+			if val, err := u.client.Get(key); err == nil {
+				locks = append(locks, utils.Deserialize[response.LoginLockResponse](val))
+			}
+		}
+		if cursor = next; cursor == 0 {
+			return locks
+		}
+	}
+}
+
+// DeleteLoginLock 删除登录锁定信息，keys 格式为 "类型:目标"
+func (u *UserCache) DeleteLoginLock(keys ...string) error {
+	if len(keys) == 0 {
+		return errors.New("键数组为空")
+	}
+	for i, key := range keys {
+		keys[i] = common.LoginLockKey + key
+	}
+	return u.client.Del(keys...)
+}
+
+// ScanKeys 按游标分页获取Redis键
+func (u *UserCache) ScanKeys(cursor uint64, match string, count int64) ([]string, uint64, error) {
+	return u.client.Scan(cursor, match, count)
 }
 
 // DeleteKeys 删除Redis键
@@ -370,16 +753,31 @@ func (u *UserCache) DeleteKeys(keys []string) error {
 		return errors.New("键数组为空")
 	}
 
-	return u.redis.Del(keys...).Err()
+	return u.client.Del(keys...)
 }
 
-// MatchDelete 删除匹配的Redis键
-func (u *UserCache) MatchDelete(key string) error {
-	arrays := u.redis.Keys(key).Val()
-	return u.DeleteKeys(arrays)
+// MatchDelete 删除匹配的Redis键，使用 SCAN 分批遍历，返回删除的数量
+func (u *UserCache) MatchDelete(key string) (int, error) {
+	var cursor uint64
+	var total int
+	for {
+		keys, next, err := u.client.Scan(cursor, key, common.ScanBatchCount)
+		if err != nil {
+			return total, err
+		}
+		if len(keys) > 0 {
+			if err := u.client.Del(keys...); err != nil {
+				return total, err
+			}
+			total += len(keys)
+		}
+		if cursor = next; cursor == 0 {
+			return total, nil
+		}
+	}
 }
 
 // NewUserCache 创建用户缓存实例
 func NewUserCache() *UserCache {
-	return &UserCache{redis: configs.REDIS}
+	return &UserCache{client: cache.Observe(configs.CACHE, "user")}
 }
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"blog/pkg/cache"
+	"blog/pkg/common"
+	"errors"
+	"testing"
+)
+
+func newTestUserService() *UserService {
+	return &UserService{cache: &UserCache{client: cache.NewMemoryCache(1000)}}
+}
+
+func TestLoginLock(t *testing.T) {
+	u := newTestUserService()
+
+	for i := 0; i < common.LoginUserMaxAttempt-1; i++ {
+		u.recordLoginFail("admin", "1.1.1.1", nil)
+	}
+	if err := u.checkLoginLock("admin", "1.1.1.1"); err != nil {
+		t.Fatalf("未达到阈值时不应锁定: %v", err)
+	}
+
+	u.recordLoginFail("admin", "1.1.1.1", nil)
+	var lockErr *LoginLockError
+	if err := u.checkLoginLock("admin", "2.2.2.2"); !errors.As(err, &lockErr) || lockErr.Code != common.AccountLocked {
+		t.Fatalf("账号应被锁定, err = %v", err)
+	}
+	if err := u.checkLoginLock("other", "1.1.1.1"); err != nil {
+		t.Fatalf("IP未达到阈值时不应锁定: %v", err)
+	}
+
+	locks := u.GetLoginLocks()
+	if len(locks) != 1 || locks[0].Type != common.LoginLockUser || locks[0].Target != "admin" {
+		t.Fatalf("锁定列表 = %+v", locks)
+	}
+
+	if err := u.ClearLoginLocks([]string{common.LoginLockUser + ":admin"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.checkLoginLock("admin", "1.1.1.1"); err != nil {
+		t.Fatalf("解除后不应锁定: %v", err)
+	}
+	if len(u.GetLoginLocks()) != 0 {
+		t.Fatal("解除后锁定列表应为空")
+	}
+}
+
+func TestLoginLockIp(t *testing.T) {
+	u := newTestUserService()
+
+	// 轮换账号也会累计到同一个IP
+	for i := 0; i < common.LoginIpMaxAttempt; i++ {
+		u.recordLoginFail(string(rune('a'+i)), "::1", nil)
+	}
+	var lockErr *LoginLockError
+	if err := u.checkLoginLock("new", "::1"); !errors.As(err, &lockErr) || lockErr.Code != common.IpLocked {
+		t.Fatalf("IP应被锁定, err = %v", err)
+	}
+
+	if err := u.ClearLoginLocks([]string{common.LoginLockIp + ":::1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.checkLoginLock("new", "::1"); err != nil {
+		t.Fatalf("解除后不应锁定: %v", err)
+	}
+	if err := u.ClearLoginLocks([]string{"other:x"}); err == nil {
+		t.Fatal("无效的锁定类型应返回错误")
+	}
+}
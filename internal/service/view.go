@@ -0,0 +1,430 @@
+package service
+
+import (
+	"blog/internal/dto/response"
+	"blog/internal/job"
+	"blog/internal/models"
+	"blog/internal/repository"
+	"blog/internal/utils"
+	"blog/pkg/background"
+	"blog/pkg/cache"
+	"blog/pkg/common"
+	"blog/pkg/configs"
+	"blog/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 浏览来源的统计维度
+const (
+	SourceReferrer = "referrer" // 来源域名
+	SourceCountry  = "country"  // 国家
+	SourceProvince = "province" // 省份
+	SourceOS       = "os"       // 操作系统
+	SourceBrowser  = "browser"  // 浏览器
+	SourceClient   = "client"   // 爬虫或真实访客
+)
+
+// ViewSourceDimensions 所有浏览来源的统计维度
+var ViewSourceDimensions = []string{SourceReferrer, SourceCountry, SourceProvince, SourceOS, SourceBrowser, SourceClient}
+
+// ViewVisitor 浏览者信息
+type ViewVisitor struct {
+	IP        string
+	UserAgent string
+	Referrer  string // 来源域名，使用 ReferrerHost 从来源页面地址中解析
+}
+
+// ViewService 浏览统计服务，浏览量、独立访客和来源先记录在缓存中，由定时任务写入数据库
+type ViewService struct {
+	repository *repository.ViewRepository
+	cache      *ViewCache
+}
+
+// RecordView 记录一次博客浏览
+func (v *ViewService) RecordView(id int64, visitor ViewVisitor) {
+	now := time.Now()
+	if err := v.cache.RecordView(now.Format(time.DateOnly), id, utils.HashToken(visitor.IP + "|" + visitor.UserAgent)[:16]); err != nil {
+		logger.Info("记录每日浏览统计失败", zap.Int64("id", id), zap.String("err", err.Error()))
+	}
+	if err := v.cache.RecordSources(now.Truncate(time.Hour).Unix(), viewSources(visitor)); err != nil {
+		logger.Info("记录浏览来源失败", zap.Int64("id", id), zap.String("err", err.Error()))
+	}
+}
+
+// viewSources 浏览者在各个维度上的取值，爬虫只统计在 client 维度
+func viewSources(visitor ViewVisitor) map[string]string {
+	if utils.IsBot(visitor.UserAgent) {
+		return map[string]string{SourceClient: "bot"}
+	}
+
+	country, province := utils.GetIpRegion(visitor.IP)
+	os, browser := utils.GetClientOSAndBrowser(visitor.UserAgent)
+	if os == "" {
+		os = "其他"
+	}
+	if browser == "" {
+		browser = "其他"
+	}
+
+	return map[string]string{
+		SourceClient:   "human",
+		SourceReferrer: visitor.Referrer,
+		SourceCountry:  country,
+		SourceProvince: province,
+		SourceOS:       os,
+		SourceBrowser:  browser,
+	}
+}
+
+// ReferrerHost 来源页面地址中的域名，没有来源时为"直接访问"；
+// 来源可以由客户端任意传入，不是 http(s) 地址、域名格式不合法或过长时统一归为"其他"，避免统计维度被随意写入的值撑大
+func ReferrerHost(referrer string) string {
+	referrer = strings.TrimSpace(referrer)
+	if referrer == "" {
+		return "直接访问"
+	}
+	if len(referrer) > common.ReferrerMaxLength {
+		return "其他"
+	}
+
+	u, err := url.Parse(referrer)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "其他"
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if !validHost(host) {
+		return "其他"
+	}
+	return host
+}
+
+// validHost 是否为合法的域名，至少包含两级，不接受IP地址
+func validHost(host string) bool {
+	if len(host) > common.ReferrerHostLength || net.ParseIP(host) != nil {
+		return false
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if label == "" || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// RollupViewSources 将最近几个小时的浏览来源汇总写入数据库，缓存中保存的是每小时累计值，重复执行结果相同
+func (v *ViewService) RollupViewSources() error {
+	var (
+		rollups []models.ViewRollup
+		current = time.Now().Truncate(time.Hour)
+	)
+	for i := common.ViewSourceSyncHours; i >= 0; i-- {
+		hour := current.Add(-time.Duration(i) * time.Hour).Unix()
+		hourly, err := v.cache.GetSources(hour)
+		if err != nil {
+			return err
+		}
+		rollups = append(rollups, hourly...)
+	}
+	return v.repository.SaveViewRollups(rollups)
+}
+
+// rollupViewSources 汇总浏览来源的定时任务
+func (v *ViewService) rollupViewSources(ctx context.Context) error {
+	return v.RollupViewSources()
+}
+
+// GetViewSources 获取日期范围内各维度浏览次数最多的取值，dimension 为空时返回所有维度
+func (v *ViewService) GetViewSources(dimension, start, end string, limit int) (map[string][]response.ViewSourceResponse, error) {
+	dimensions := ViewSourceDimensions
+	if dimension != "" {
+		if !slices.Contains(ViewSourceDimensions, dimension) {
+			return nil, fmt.Errorf("不支持的统计维度: %s", dimension)
+		}
+		dimensions = []string{dimension}
+	}
+
+	if limit <= 0 {
+		limit = common.ViewSourceTopCount
+	} else if limit > common.ViewSourceMaxCount {
+		limit = common.ViewSourceMaxCount
+	}
+
+	from, to, err := parseDateRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = make(map[string][]response.ViewSourceResponse, len(dimensions))
+	for _, dim := range dimensions {
+		list, err := v.repository.SumViewRollups(dim, from.Unix(), to.AddDate(0, 0, 1).Unix(), limit)
+		if err != nil {
+			return nil, err
+		}
+		result[dim] = list
+	}
+	return result, nil
+}
+
+// SyncDailyViews 将最近几天的浏览统计写入数据库，缓存中保存的是当天累计值，重复执行结果相同
+func (v *ViewService) SyncDailyViews() error {
+	var views []models.BlogDailyView
+	for _, date := range recentDates(common.DailyViewSyncDays) {
+		daily, err := v.cache.GetDailyViews(date)
+		if err != nil {
+			return err
+		}
+		views = append(views, daily...)
+	}
+	return v.repository.SaveDailyViews(views)
+}
+
+// GetViewTrend 获取博客或全站（blogID 为0）在日期范围内的浏览趋势，缓存中尚未过期的日期以缓存中的实时数据为准
+func (v *ViewService) GetViewTrend(blogID int64, start, end string) (response.ViewTrendResponse, error) {
+	from, to, err := parseDateRange(start, end)
+	if err != nil {
+		return response.ViewTrendResponse{}, err
+	}
+
+	rows, err := v.repository.FindDailyViews(blogID, from.Format(time.DateOnly), to.Format(time.DateOnly))
+	if err != nil {
+		return response.ViewTrendResponse{}, err
+	}
+
+	var saved = make(map[string]models.BlogDailyView, len(rows))
+	for _, row := range rows {
+		saved[row.Date] = row
+	}
+
+	live := time.Now().AddDate(0, 0, -common.DailyViewSyncDays).Format(time.DateOnly)
+
+	var trend = response.ViewTrendResponse{BlogID: blogID, Start: from.Format(time.DateOnly), End: to.Format(time.DateOnly)}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		item := response.DailyViewResponse{Date: date, PV: saved[date].PV, UV: saved[date].UV}
+
+		if date > live {
+			if cached, err := v.cache.GetDailyView(date, blogID); err == nil && cached.PV > item.PV {
+				item.PV, item.UV = cached.PV, cached.UV
+			}
+		}
+
+		trend.PV += item.PV
+		trend.Days = append(trend.Days, item)
+	}
+	return trend, nil
+}
+
+// parseDateRange 解析日期范围，默认查询最近30天
+func parseDateRange(start, end string) (time.Time, time.Time, error) {
+	today, _ := time.ParseInLocation(time.DateOnly, time.Now().Format(time.DateOnly), time.Local)
+
+	to := today
+	if end != "" {
+		t, err := time.ParseInLocation(time.DateOnly, end, time.Local)
+		if err != nil {
+			return to, to, errors.New("结束日期格式错误，应为 YYYY-MM-DD")
+		}
+		to = t
+	}
+
+	from := to.AddDate(0, 0, 1-common.ViewTrendDays)
+	if start != "" {
+		t, err := time.ParseInLocation(time.DateOnly, start, time.Local)
+		if err != nil {
+			return from, to, errors.New("开始日期格式错误，应为 YYYY-MM-DD")
+		}
+		from = t
+	}
+
+	if from.After(to) {
+		return from, to, errors.New("开始日期不能晚于结束日期")
+	}
+	if to.Sub(from) >= time.Hour*24*common.ViewTrendMaxDays {
+		return from, to, fmt.Errorf("最多查询%d天的数据", common.ViewTrendMaxDays)
+	}
+	return from, to, nil
+}
+
+// recentDates 包含今天在内最近几天的日期
+func recentDates(days int) []string {
+	var dates = make([]string, 0, days)
+	for i := days - 1; i >= 0; i-- {
+		dates = append(dates, time.Now().AddDate(0, 0, -i).Format(time.DateOnly))
+	}
+	return dates
+}
+
+// NewViewService 创建浏览统计服务实例
+func NewViewService() *ViewService {
+	var service = &ViewService{
+		repository: repository.NewViewRepository(),
+		cache:      NewViewCache(),
+	}
+
+	if configs.CONFIG.Server.Cron {
+		job.AddJob(job.Job{
+			Name:        "view_source_rollup",
+			Spec:        "5 * * * *",
+			Description: "汇总浏览来源",
+			Job:         service.rollupViewSources,
+		})
+	}
+
+	// 关闭时汇总最近几个小时的浏览来源
+	background.OnShutdown("view_source_rollup", service.rollupViewSources)
+
+	return service
+}
+
+// ViewCache 每日浏览统计缓存
+type ViewCache struct {
+	client cache.Cache
+}
+
+// RecordView 增加博客当天的浏览量，并把访客加入博客与全站当天的独立访客统计
+func (v *ViewCache) RecordView(date string, id int64, visitor string) error {
+	key := common.BlogDailyPvKey + date
+	count, err := v.client.HIncrOrInit(key, strconv.FormatInt(id, 10), 1)
+	if err != nil {
+		return err
+	}
+	if count == 1 {
+		if err := v.client.Expire(key, common.DailyViewExpire); err != nil {
+			return err
+		}
+	}
+
+	if err := v.client.PFAdd(blogUvKey(date, id), common.DailyViewExpire, visitor); err != nil {
+		return err
+	}
+	return v.client.PFAdd(common.SiteDailyUvKey+date, common.DailyViewExpire, visitor)
+}
+
+// RecordSources 增加某小时各维度取值的浏览次数
+func (v *ViewCache) RecordSources(hour int64, sources map[string]string) error {
+	key := common.ViewSourceKey + strconv.FormatInt(hour, 10)
+	for dim, value := range sources {
+		count, err := v.client.HIncrOrInit(key, dim+"|"+value, 1)
+		if err != nil {
+			return err
+		}
+		if count == 1 {
+			if err := v.client.Expire(key, common.ViewSourceExpire); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetSources 获取某小时的浏览来源统计
+func (v *ViewCache) GetSources(hour int64) ([]models.ViewRollup, error) {
+	counts, err := v.client.HGetAll(common.ViewSourceKey + strconv.FormatInt(hour, 10))
+	if err != nil && !errors.Is(err, cache.ErrNotFound) {
+		return nil, err
+	}
+
+	var rollups = make([]models.ViewRollup, 0, len(counts))
+	for field, count := range counts {
+		dim, value, ok := strings.Cut(field, "|")
+		if !ok {
+			continue
+		}
+		n, _ := strconv.ParseInt(count, 10, 64)
+		rollups = append(rollups, models.ViewRollup{Hour: hour, Dimension: dim, Value: value, Count: n})
+	}
+	return rollups, nil
+}
+
+// GetDailyViews 获取某天所有博客以及全站的浏览统计
+func (v *ViewCache) GetDailyViews(date string) ([]models.BlogDailyView, error) {
+	counts, err := v.client.HGetAll(common.BlogDailyPvKey + date)
+	if err != nil && !errors.Is(err, cache.ErrNotFound) {
+		return nil, err
+	}
+	if len(counts) == 0 {
+		return nil, nil
+	}
+
+	var (
+		views = make([]models.BlogDailyView, 0, len(counts)+1)
+		total int64
+	)
+	for field, count := range counts {
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			continue
+		}
+		pv, _ := strconv.ParseInt(count, 10, 64)
+		uv, err := v.client.PFCount(blogUvKey(date, id))
+		if err != nil {
+			return nil, err
+		}
+		total += pv
+		views = append(views, models.BlogDailyView{Date: date, BlogID: id, PV: pv, UV: uv})
+	}
+
+	uv, err := v.client.PFCount(common.SiteDailyUvKey + date)
+	if err != nil {
+		return nil, err
+	}
+	return append(views, models.BlogDailyView{Date: date, PV: total, UV: uv}), nil
+}
+
+// GetDailyView 获取某天单篇博客或全站（id 为0）的浏览统计
+func (v *ViewCache) GetDailyView(date string, id int64) (models.BlogDailyView, error) {
+	view := models.BlogDailyView{Date: date, BlogID: id}
+
+	if id == 0 {
+		counts, err := v.client.HVals(common.BlogDailyPvKey + date)
+		if err != nil && !errors.Is(err, cache.ErrNotFound) {
+			return view, err
+		}
+		for _, count := range counts {
+			pv, _ := strconv.ParseInt(count, 10, 64)
+			view.PV += pv
+		}
+		view.UV, err = v.client.PFCount(common.SiteDailyUvKey + date)
+		return view, err
+	}
+
+	count, err := v.client.HGet(common.BlogDailyPvKey+date, strconv.FormatInt(id, 10))
+	if errors.Is(err, cache.ErrNotFound) {
+		return view, nil
+	}
+	if err != nil {
+		return view, err
+	}
+	view.PV, _ = strconv.ParseInt(count, 10, 64)
+	view.UV, err = v.client.PFCount(blogUvKey(date, id))
+	return view, err
+}
+
+// blogUvKey 单篇博客某天独立访客的key
+func blogUvKey(date string, id int64) string {
+	return fmt.Sprintf("%s%s:%d", common.BlogDailyUvKey, date, id)
+}
+
+// NewViewCache 创建每日浏览统计缓存实例
+func NewViewCache() *ViewCache {
+	return &ViewCache{client: cache.Observe(configs.CACHE, "view")}
+}
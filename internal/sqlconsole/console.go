@@ -0,0 +1,256 @@
+package sqlconsole
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Column 结果列
+type Column struct {
+	Name string `json:"name"` // 列名
+	Type string `json:"type"` // 数据库类型，如 int8、text、timestamptz
+}
+
+// Result 语句执行结果
+type Result struct {
+	Keyword      string          `json:"keyword"`        // 语句的第一个关键字
+	Columns      []Column        `json:"columns"`        // 结果列，没有结果集时为空
+	Rows         [][]any         `json:"rows"`           // 结果行
+	Page         int             `json:"page,omitempty"` // 当前页，只有可分页的查询有
+	Size         int             `json:"size,omitempty"` // 每页行数
+	HasMore      bool            `json:"hasMore"`        // 是否还有更多行
+	RowsAffected int64           `json:"rowsAffected"`   // 写入模式下影响的行数
+	Plan         json.RawMessage `json:"plan,omitempty"` // EXPLAIN 的 JSON 执行计划
+	DryRun       bool            `json:"dryRun"`         // 写入模式下是否只预览
+	Committed    bool            `json:"committed"`      // 写入模式下是否已提交
+	Duration     int64           `json:"duration"`       // 执行耗时（毫秒）
+}
+
+// QueryOptions 只读查询选项
+type QueryOptions struct {
+	Page    int           // 第几页，从1开始
+	Size    int           // 每页行数，也是不能分页的语句最多返回的行数
+	Explain bool          // 返回执行计划而不是结果
+	Analyze bool          // 执行计划中包含实际执行的耗时，语句会被真正执行
+	Timeout time.Duration // 语句超时时间
+}
+
+// ExecOptions 写入模式选项
+type ExecOptions struct {
+	Commit  bool          // 为 false 时只预览，执行后回滚
+	Preview int           // 最多返回的影响行数
+	Timeout time.Duration // 语句超时时间
+}
+
+// errRollback 用于在执行完成后回滚事务
+var errRollback = errors.New("rollback")
+
+// Query 在只读事务中执行查询，可分页的查询作为子查询按页读取，EXPLAIN 返回 JSON 执行计划
+func Query(ctx context.Context, db *sql.DB, stmt Statement, opts QueryOptions) (*Result, error) {
+	if stmt.Kind == KindWrite && !opts.Explain {
+		return nil, fmt.Errorf("%s 语句会修改数据，请使用写入模式", stmt.Keyword)
+	}
+	if opts.Explain && stmt.Kind == KindShow {
+		return nil, fmt.Errorf("%s 语句不支持 EXPLAIN", stmt.Keyword)
+	}
+	// 只读事务拦不住这些函数的副作用，不执行的 EXPLAIN 除外
+	if name, ok := stmt.Volatile(); ok && (!opts.Explain || opts.Analyze) {
+		return nil, fmt.Errorf("只读查询不能调用 %s，请使用写入模式", strings.ToLower(name))
+	}
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+
+	result := &Result{Keyword: stmt.Keyword, Rows: [][]any{}}
+	start := time.Now()
+	err := inTx(ctx, db, &sql.TxOptions{ReadOnly: true}, opts.Timeout, func(tx *sql.Tx) error {
+		if opts.Explain {
+			return explain(ctx, tx, stmt, opts.Analyze, result)
+		}
+
+		query := stmt.SQL
+		limit := opts.Size
+		if stmt.Kind == KindRead {
+			// 换行避免语句末尾的注释影响外层查询，多取一行判断是否还有下一页
+			query = fmt.Sprintf("SELECT * FROM (\n%s\n) AS console LIMIT %d OFFSET %d", stmt.SQL, opts.Size+1, (opts.Page-1)*opts.Size)
+			result.Page, result.Size = opts.Page, opts.Size
+		}
+		rows, err := tx.QueryContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		_, err = scan(rows, limit, result)
+		return err
+	})
+	result.Duration = time.Since(start).Milliseconds()
+	if err != nil && !errors.Is(err, errRollback) {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Execute 在读写事务中执行语句，Commit 为 false 时执行后回滚作为预览，
+// 调用有副作用函数的语句和 DO、CALL、COPY、函数定义等无法检查的语句不能预览；
+// INSERT、UPDATE、DELETE 没有 RETURNING 时追加 RETURNING * 以返回影响的行
+func Execute(ctx context.Context, db *sql.DB, stmt Statement, opts ExecOptions) (*Result, error) {
+	if name, ok := stmt.Volatile(); ok && !opts.Commit {
+		return nil, fmt.Errorf("%s 的副作用不会随预览回滚，确认后请直接提交执行", strings.ToLower(name))
+	}
+	if name, ok := stmt.Opaque(); ok && !opts.Commit {
+		return nil, fmt.Errorf("无法检查 %s 中执行的内容，不能预览，确认后请直接提交执行", name)
+	}
+	result := &Result{Keyword: stmt.Keyword, Rows: [][]any{}, DryRun: !opts.Commit}
+	start := time.Now()
+	err := inTx(ctx, db, nil, opts.Timeout, func(tx *sql.Tx) error {
+		query := stmt.SQL
+		if dmlKeywords[stmt.Keyword] && !stmt.Returning {
+			query += "\nRETURNING *"
+		}
+
+		if stmt.Kind != KindWrite || dmlKeywords[stmt.Keyword] || stmt.Returning {
+			rows, err := tx.QueryContext(ctx, query)
+			if err != nil {
+				return err
+			}
+			if result.RowsAffected, err = scan(rows, opts.Preview, result); err != nil {
+				return err
+			}
+		} else {
+			res, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+			result.RowsAffected, _ = res.RowsAffected()
+		}
+
+		if !opts.Commit {
+			return errRollback
+		}
+		return nil
+	})
+	result.Duration = time.Since(start).Milliseconds()
+	if err != nil && !errors.Is(err, errRollback) {
+		return nil, err
+	}
+	result.Committed = err == nil
+	return result, nil
+}
+
+// inTx 在事务中执行 fn 并设置语句超时，fn 返回错误时回滚
+func inTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, timeout time.Duration, fn func(tx *sql.Tx) error) error {
+	if timeout > 0 {
+		// 语句超时由数据库中断查询，上下文多等一会以便拿到数据库返回的错误
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout+time.Second*5)
+		defer cancel()
+	}
+
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if timeout > 0 {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout.Milliseconds())); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// explain 返回语句的 JSON 执行计划
+func explain(ctx context.Context, tx *sql.Tx, stmt Statement, analyze bool, result *Result) error {
+	options := "FORMAT JSON"
+	if analyze {
+		options += ", ANALYZE, BUFFERS"
+	}
+	var plan []byte
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf("EXPLAIN (%s)\n%s", options, stmt.SQL)).Scan(&plan); err != nil {
+		return err
+	}
+	if !json.Valid(plan) {
+		return errors.New("执行计划不是有效的 JSON")
+	}
+	result.Plan = plan
+	return nil
+}
+
+// scan 读取结果集，最多保留 limit 行，返回读取的总行数
+func scan(rows *sql.Rows, limit int, result *Result) (int64, error) {
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+	result.Columns = make([]Column, len(types))
+	for i, t := range types {
+		result.Columns[i] = Column{Name: t.Name(), Type: strings.ToLower(t.DatabaseTypeName())}
+	}
+
+	var total int64
+	for rows.Next() {
+		total++
+		if len(result.Rows) >= limit {
+			result.HasMore = true
+			continue
+		}
+		values := make([]any, len(types))
+		pointers := make([]any, len(types))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return total, err
+		}
+		for i, v := range values {
+			values[i] = Normalize(v)
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	return total, rows.Err()
+}
+
+// Normalize 将驱动返回的值转为可以编码为 JSON 的值：
+// 文本使用字符串，二进制使用 Base64，时间使用 RFC3339，无法表示的浮点数和其他类型转为字符串
+func Normalize(value any) any {
+	switch v := value.(type) {
+	case nil, bool, string, int64, int32, int16, int8, int, uint32, uint16, uint8, map[string]any, []any:
+		return v
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Sprint(v)
+		}
+		return v
+	case float32:
+		return Normalize(float64(v))
+	case []byte:
+		if utf8.Valid(v) {
+			return string(v)
+		}
+		return base64.StdEncoding.EncodeToString(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case driver.Valuer:
+		if inner, err := v.Value(); err == nil {
+			if _, ok := inner.(driver.Valuer); !ok {
+				return Normalize(inner)
+			}
+		}
+	}
+	return fmt.Sprint(value)
+}
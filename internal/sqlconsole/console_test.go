@@ -0,0 +1,99 @@
+package sqlconsole
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// testDB 连接 BLOG_TEST_DSN 指定的 PostgreSQL 测试库，未设置时跳过
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("BLOG_TEST_DSN")
+	if dsn == "" {
+		t.Skip("未设置 BLOG_TEST_DSN")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sqlDB
+}
+
+func mustParse(t *testing.T, sql string) Statement {
+	t.Helper()
+	stmt, err := Parse(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stmt
+}
+
+func TestQueryAndExecute(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	drop := func() { db.Exec("DROP TABLE IF EXISTS sqlconsole_test") }
+	drop()
+	t.Cleanup(drop)
+	if _, err := db.Exec("CREATE TABLE sqlconsole_test (id int PRIMARY KEY, title text); INSERT INTO sqlconsole_test SELECT i, 't' || i FROM generate_series(1, 5) i"); err != nil {
+		t.Fatal(err)
+	}
+	count := func() (n int) {
+		db.QueryRow("SELECT count(*) FROM sqlconsole_test").Scan(&n)
+		return n
+	}
+
+	result, err := Query(ctx, db, mustParse(t, "SELECT id FROM sqlconsole_test ORDER BY id -- 注释"), QueryOptions{Page: 2, Size: 2, Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) != 2 || result.Rows[0][0] != int64(3) || !result.HasMore {
+		t.Fatalf("第2页 rows = %v, hasMore = %v", result.Rows, result.HasMore)
+	}
+
+	if _, err := Query(ctx, db, mustParse(t, "DELETE FROM sqlconsole_test"), QueryOptions{Size: 10}); err == nil {
+		t.Fatal("只读查询不能执行写入语句")
+	}
+	if _, err := Query(ctx, db, mustParse(t, "SELECT pg_sleep(0)"), QueryOptions{Size: 10}); err == nil {
+		t.Fatal("只读查询不能调用有副作用的函数")
+	}
+	// 只读事务拦住没有识别出的写入
+	if _, err := Query(ctx, db, mustParse(t, "WITH d AS (DELETE FROM sqlconsole_test RETURNING id) SELECT * FROM d"), QueryOptions{Size: 10}); err == nil || count() != 5 {
+		t.Fatalf("只读事务中不应删除数据, err = %v", err)
+	}
+
+	result, err = Execute(ctx, db, mustParse(t, "DELETE FROM sqlconsole_test WHERE id > 3"), ExecOptions{Preview: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.DryRun || result.Committed || result.RowsAffected != 2 || len(result.Rows) != 2 || count() != 5 {
+		t.Fatalf("预览 result = %+v, count = %d", result, count())
+	}
+
+	for _, sql := range []string{
+		"DO $$BEGIN DELETE FROM sqlconsole_test; END$$",
+		"COPY sqlconsole_test TO PROGRAM 'true'",
+		"SELECT setval('x', 1)",
+	} {
+		if _, err := Execute(ctx, db, mustParse(t, sql), ExecOptions{Preview: 10}); err == nil {
+			t.Fatalf("%q 不应允许预览", sql)
+		}
+	}
+
+	result, err = Execute(ctx, db, mustParse(t, "DELETE FROM sqlconsole_test WHERE id > 3"), ExecOptions{Commit: true, Preview: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Committed || result.RowsAffected != 2 || count() != 3 {
+		t.Fatalf("提交 result = %+v, count = %d", result, count())
+	}
+}
@@ -0,0 +1,280 @@
+package sqlconsole
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// Kind 语句类型
+type Kind string
+
+const (
+	KindRead  Kind = "read"  // SELECT、WITH、VALUES、TABLE，结果可以分页
+	KindShow  Kind = "show"  // SHOW、EXPLAIN，只读但结果不能作为子查询分页
+	KindWrite Kind = "write" // 其他语句，需要写入模式
+)
+
+var (
+	ErrEmpty    = errors.New("SQL 不能为空")
+	ErrMultiple = errors.New("一次只能执行一条语句")
+)
+
+// Statement 解析后的单条语句
+type Statement struct {
+	SQL       string // 去掉末尾分号的语句
+	Keyword   string // 第一个关键字，大写
+	Kind      Kind
+	Returning bool     // 是否包含 RETURNING 子句
+	Calls     []string // 调用的函数名，大写，不含模式名
+	Routine   bool     // 是否创建函数或过程
+	Dollar    bool     // 是否包含 $$ 字符串，DO 和函数体通常写在其中
+}
+
+// readKeywords 结果可以分页的只读语句
+var readKeywords = map[string]bool{"SELECT": true, "WITH": true, "VALUES": true, "TABLE": true}
+
+// showKeywords 结果不能分页的只读语句
+var showKeywords = map[string]bool{"SHOW": true, "EXPLAIN": true}
+
+// dmlKeywords 可以追加 RETURNING 预览影响行的语句
+var dmlKeywords = map[string]bool{"INSERT": true, "UPDATE": true, "DELETE": true}
+
+// opaqueKeywords 执行的内容无法检查或副作用不会随事务回滚的语句：
+// DO 执行任意代码，CALL 执行存储过程，COPY 可以读写服务器文件或执行程序
+var opaqueKeywords = map[string]bool{"DO": true, "CALL": true, "COPY": true}
+
+// volatileFuncs 只读事务也能产生副作用的函数：修改序列、结束其他连接、长时间占用连接、
+// 加咨询锁、读写服务器文件、访问其他数据库或执行动态 SQL
+var volatileFuncs = map[string]bool{
+	"NEXTVAL": true, "SETVAL": true, "SET_CONFIG": true, "PG_NOTIFY": true,
+	"PG_TERMINATE_BACKEND": true, "PG_CANCEL_BACKEND": true, "PG_RELOAD_CONF": true, "PG_ROTATE_LOGFILE": true,
+	"PG_SWITCH_WAL": true, "PG_CREATE_RESTORE_POINT": true, "PG_PROMOTE": true, "PG_LOGICAL_EMIT_MESSAGE": true,
+	"PG_LOG_BACKEND_MEMORY_CONTEXTS": true, "PG_STAT_FILE": true, "PG_STAT_RESET": true, "LOREAD": true, "LOWRITE": true,
+}
+
+// volatilePrefixes 按前缀匹配的同类函数
+var volatilePrefixes = []string{"PG_SLEEP", "PG_ADVISORY_", "PG_TRY_ADVISORY_", "PG_READ_", "PG_LS_", "PG_FILE_", "LO_", "DBLINK", "QUERY_TO_XML", "CURSOR_TO_XML"}
+
+// Volatile 返回语句中第一个会产生副作用的函数，只读查询不允许调用；
+// 自定义函数无法判断，生产环境应同时为控制台使用只有查询权限的数据库账号
+func (s Statement) Volatile() (string, bool) {
+	for _, name := range s.Calls {
+		if volatileFuncs[name] {
+			return name, true
+		}
+		for _, prefix := range volatilePrefixes {
+			if strings.HasPrefix(name, prefix) {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Opaque 返回语句中无法检查其中调用的函数、因而不能预览的部分：DO、CALL、COPY、函数定义和 $$ 字符串
+func (s Statement) Opaque() (string, bool) {
+	switch {
+	case opaqueKeywords[s.Keyword]:
+		return s.Keyword, true
+	case s.Routine:
+		return "CREATE FUNCTION", true
+	case s.Dollar:
+		return "$$", true
+	}
+	return "", false
+}
+
+// Parse 解析一条 PostgreSQL 语句，跳过字符串、引号标识符、注释和 $$ 字符串中的内容，
+// 包含多条语句时返回 ErrMultiple
+func Parse(sql string) (Statement, error) {
+	s := scanner{src: sql}
+	var (
+		stmt Statement
+		end  = -1 // 第一个分号的位置
+	)
+	for {
+		word, pos, ok := s.next()
+		if !ok {
+			break
+		}
+		if word == "(" && end < 0 {
+			continue
+		}
+		if word == ";" {
+			if end < 0 {
+				end = pos
+			}
+			continue
+		}
+		if end >= 0 {
+			return Statement{}, ErrMultiple
+		}
+		if stmt.Keyword == "" {
+			stmt.Keyword = word
+		}
+		if word == "RETURNING" {
+			stmt.Returning = true
+		}
+		if stmt.Keyword == "CREATE" && (word == "FUNCTION" || word == "PROCEDURE") {
+			stmt.Routine = true
+		}
+	}
+	if stmt.Keyword == "" {
+		return Statement{}, ErrEmpty
+	}
+
+	if end >= 0 {
+		sql = sql[:end]
+	}
+	stmt.SQL = strings.TrimRightFunc(sql, unicode.IsSpace)
+	stmt.Calls = s.calls
+	stmt.Dollar = s.dollar
+	switch {
+	case readKeywords[stmt.Keyword]:
+		stmt.Kind = KindRead
+	case showKeywords[stmt.Keyword]:
+		stmt.Kind = KindShow
+	default:
+		stmt.Kind = KindWrite
+	}
+	return stmt, nil
+}
+
+// scanner 按词扫描 SQL，只返回字符串和注释之外的关键字（大写）和分号，
+// 同时记录后面紧跟括号的词作为函数调用，中间的空白和注释不影响
+type scanner struct {
+	src    string
+	pos    int
+	escape bool     // 下一个字符串是 E'...'，反斜杠转义
+	prev   string   // 上一个词或引号标识符，遇到其他符号时清空
+	calls  []string // 函数调用
+	dollar bool     // 是否遇到过 $$ 字符串
+}
+
+// next 返回下一个关键字或分号及其位置，结束时 ok 为 false
+func (s *scanner) next() (string, int, bool) {
+	for s.pos < len(s.src) {
+		c := s.src[s.pos]
+		switch {
+		case c == ';':
+			s.prev = ""
+			s.pos++
+			return ";", s.pos - 1, true
+		case c == '(':
+			if s.prev != "" {
+				s.calls = append(s.calls, s.prev)
+			}
+			s.prev = ""
+			s.pos++
+			return "(", s.pos - 1, true
+		case c == '-' && strings.HasPrefix(s.src[s.pos:], "--"):
+			if i := strings.IndexByte(s.src[s.pos:], '\n'); i >= 0 {
+				s.pos += i + 1
+			} else {
+				s.pos = len(s.src)
+			}
+		case c == '/' && strings.HasPrefix(s.src[s.pos:], "/*"):
+			s.skipBlockComment()
+		case c == '\'':
+			s.skipQuoted('\'', s.escape)
+			s.escape = false
+			s.prev = ""
+		case c == '"':
+			start := s.pos
+			s.skipQuoted('"', false)
+			s.prev = strings.ToUpper(strings.ReplaceAll(strings.TrimSuffix(s.src[start+1:s.pos], `"`), `""`, `"`))
+		case c == '$':
+			s.skipDollar()
+			s.prev = ""
+		case isWordStart(c):
+			start := s.pos
+			for s.pos < len(s.src) && isWordPart(s.src[s.pos]) {
+				s.pos++
+			}
+			// E'...'、B'...' 等字符串的前缀交给字符串处理
+			if s.pos < len(s.src) && s.src[s.pos] == '\'' && s.pos-start == 1 {
+				s.escape = s.src[start] == 'E' || s.src[start] == 'e'
+				continue
+			}
+			s.prev = strings.ToUpper(s.src[start:s.pos])
+			return s.prev, start, true
+		case unicode.IsSpace(rune(c)):
+			s.pos++
+		default:
+			s.prev = ""
+			s.pos++
+		}
+	}
+	return "", s.pos, false
+}
+
+// skipBlockComment 跳过可以嵌套的 /* */ 注释
+func (s *scanner) skipBlockComment() {
+	depth := 0
+	for s.pos < len(s.src) {
+		switch {
+		case strings.HasPrefix(s.src[s.pos:], "/*"):
+			depth++
+			s.pos += 2
+		case strings.HasPrefix(s.src[s.pos:], "*/"):
+			depth--
+			s.pos += 2
+			if depth == 0 {
+				return
+			}
+		default:
+			s.pos++
+		}
+	}
+}
+
+// skipQuoted 跳过字符串或引号标识符，两个引号表示一个引号，E 字符串中反斜杠转义
+func (s *scanner) skipQuoted(quote byte, backslash bool) {
+	s.pos++
+	for s.pos < len(s.src) {
+		c := s.src[s.pos]
+		switch {
+		case backslash && c == '\\':
+			s.pos += 2
+		case c == quote && s.pos+1 < len(s.src) && s.src[s.pos+1] == quote:
+			s.pos += 2
+		case c == quote:
+			s.pos++
+			return
+		default:
+			s.pos++
+		}
+	}
+}
+
+// skipDollar 跳过 $tag$...$tag$ 字符串，$1 这样的参数只跳过 $
+func (s *scanner) skipDollar() {
+	end := s.pos + 1
+	for end < len(s.src) && (isWordStart(s.src[end]) || end > s.pos+1 && isDigit(s.src[end])) {
+		end++
+	}
+	if end >= len(s.src) || s.src[end] != '$' {
+		s.pos++
+		return
+	}
+	tag := s.src[s.pos : end+1]
+	s.dollar = true
+	if i := strings.Index(s.src[end+1:], tag); i >= 0 {
+		s.pos = end + 1 + i + len(tag)
+	} else {
+		s.pos = len(s.src)
+	}
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func isWordPart(c byte) bool {
+	return isWordStart(c) || isDigit(c) || c == '$'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,114 @@
+package sqlconsole
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		sql       string
+		keyword   string
+		kind      Kind
+		returning bool
+		stmt      string
+	}{
+		{sql: "select 1;", keyword: "SELECT", kind: KindRead, stmt: "select 1"},
+		{sql: "  -- 注释\n/* a /* b */ c */ WITH x AS (SELECT 1) SELECT * FROM x ; -- end", keyword: "WITH", kind: KindRead, stmt: "  -- 注释\n/* a /* b */ c */ WITH x AS (SELECT 1) SELECT * FROM x"},
+		{sql: "explain select 1", keyword: "EXPLAIN", kind: KindShow, stmt: "explain select 1"},
+		{sql: "SELECT 'a;b', \"c;d\", $$e;f$$, $tag$g;$$h$tag$ FROM t", keyword: "SELECT", kind: KindRead},
+		{sql: "SELECT E'it\\'s;' FROM t", keyword: "SELECT", kind: KindRead},
+		{sql: "SELECT 'it''s;' FROM t WHERE id = $1", keyword: "SELECT", kind: KindRead},
+		{sql: "UPDATE blogs SET title = 'returning' WHERE id = 1", keyword: "UPDATE", kind: KindWrite},
+		{sql: "delete from blogs returning id", keyword: "DELETE", kind: KindWrite, returning: true},
+		{sql: "DROP TABLE blogs", keyword: "DROP", kind: KindWrite},
+	}
+	for _, test := range tests {
+		stmt, err := Parse(test.sql)
+		if err != nil {
+			t.Fatalf("%q: %v", test.sql, err)
+		}
+		if stmt.Keyword != test.keyword || stmt.Kind != test.kind || stmt.Returning != test.returning {
+			t.Fatalf("%q: got %+v", test.sql, stmt)
+		}
+		if test.stmt != "" && stmt.SQL != test.stmt {
+			t.Fatalf("%q: sql = %q", test.sql, stmt.SQL)
+		}
+	}
+
+	for sql, want := range map[string]error{
+		"":                          ErrEmpty,
+		" ; -- nothing":             ErrEmpty,
+		"SELECT 1; DROP TABLE x":    ErrMultiple,
+		"COMMIT; DELETE FROM blogs": ErrMultiple,
+		"SELECT 'x'; SELECT 2":      ErrMultiple,
+	} {
+		if _, err := Parse(sql); !errors.Is(err, want) {
+			t.Fatalf("%q: err = %v, want %v", sql, err, want)
+		}
+	}
+}
+
+func TestVolatile(t *testing.T) {
+	for sql, want := range map[string]string{
+		"SELECT nextval('blogs_id_seq')":                                "NEXTVAL",
+		"select pg_catalog.pg_sleep (10)":                               "PG_SLEEP",
+		"SELECT pg_terminate_backend/* x */(pid) FROM pg_stat_activity": "PG_TERMINATE_BACKEND",
+		`SELECT "pg_advisory_lock"(1)`:                                  "PG_ADVISORY_LOCK",
+		"SELECT * FROM query_to_xml('select 1', true, true, '')":        "QUERY_TO_XML",
+		"SELECT count(*), lower(title) FROM blogs":                      "",
+		"SELECT 'pg_sleep(1)', $$nextval(1)$$ FROM t":                   "",
+		"SELECT pg_sleep FROM t WHERE id IN (1, 2)":                     "",
+	} {
+		stmt, err := Parse(sql)
+		if err != nil {
+			t.Fatalf("%q: %v", sql, err)
+		}
+		if got, _ := stmt.Volatile(); got != want {
+			t.Fatalf("%q: Volatile = %q, want %q", sql, got, want)
+		}
+	}
+}
+
+func TestOpaque(t *testing.T) {
+	for sql, want := range map[string]string{
+		"DO $$BEGIN PERFORM pg_sleep(10); END$$":                                "DO",
+		"COPY blogs TO PROGRAM 'rm -rf /'":                                      "COPY",
+		"copy blogs from '/etc/passwd'":                                         "COPY",
+		"CALL cleanup()":                                                        "CALL",
+		"CREATE OR REPLACE FUNCTION f() RETURNS int AS 'SELECT 1' LANGUAGE sql": "CREATE FUNCTION",
+		"UPDATE blogs SET title = $tag$x$tag$ WHERE id = 1":                     "$$",
+		"UPDATE blogs SET title = 'function' WHERE id = $1":                     "",
+		"CREATE TABLE t (id int)":                                               "",
+	} {
+		stmt, err := Parse(sql)
+		if err != nil {
+			t.Fatalf("%q: %v", sql, err)
+		}
+		if got, _ := stmt.Opaque(); got != want {
+			t.Fatalf("%q: Opaque = %q, want %q", sql, got, want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, test := range []struct {
+		value any
+		want  any
+	}{
+		{nil, nil},
+		{int64(3), int64(3)},
+		{[]byte("文本"), "文本"},
+		{[]byte{0xff, 0x00}, "/wA="},
+		{at, "2024-01-02T03:04:05Z"},
+		{math.Inf(1), "+Inf"},
+		{[2]int{1, 2}, "[1 2]"},
+	} {
+		if got := Normalize(test.value); got != test.want {
+			t.Fatalf("Normalize(%v) = %#v, want %#v", test.value, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,396 @@
+package transfer
+
+import (
+	"archive/zip"
+	"blog/internal/backup"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// 已存在数据的处理策略
+const (
+	Skip      = "skip"      // 保留已有数据
+	Overwrite = "overwrite" // 使用导入的数据覆盖
+	Merge     = "merge"     // 只补充已有数据中为空的列，计数列取较大值
+)
+
+// maxErrors 每张表在报告中保留的错误数量
+const maxErrors = 20
+
+// savepoint 导入每一行前创建的保存点，失败时只回滚该行
+const savepoint = "import_row"
+
+// deletedColumn 软删除列，合并时不会因导入的数据已删除而删除已有数据
+const deletedColumn = "deleted_at"
+
+// errDryRun 试运行结束时回滚事务
+var errDryRun = errors.New("dry run")
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	Strategy string // 已存在数据的处理策略，默认 skip
+	DryRun   bool   // 只生成报告，不提交
+}
+
+// Report 导入报告
+type Report struct {
+	Version   int           `json:"version"`
+	Strategy  string        `json:"strategy"`
+	DryRun    bool          `json:"dryRun"`
+	Tables    []TableReport `json:"tables"`
+	Unknown   []string      `json:"unknown,omitempty"` // 导出文件中不支持导入的表
+	Committed bool          `json:"committed"`
+}
+
+// TableReport 单张表的导入结果
+type TableReport struct {
+	Table    string   `json:"table"`
+	Total    int      `json:"total"`
+	Inserted int      `json:"inserted"`
+	Updated  int      `json:"updated"`
+	Skipped  int      `json:"skipped"`
+	Failed   int      `json:"failed"`
+	Ignored  []string `json:"ignored,omitempty"` // 目标数据库中不存在而忽略的列
+	Errors   []string `json:"errors,omitempty"`  // 失败的行，最多保留 maxErrors 条
+}
+
+// Import 在一个事务内按 tables 的顺序导入导出文件中的表；
+// 新行的自增主键由目标数据库生成，引用这些主键的列按新旧ID的对应关系替换，单行失败不影响其他行；
+// 试运行时完整执行后回滚，报告与实际导入的结果一致
+func Import(ctx context.Context, db *gorm.DB, zr *zip.Reader, tables []Table, opts ImportOptions) (*Report, error) {
+	if opts.Strategy == "" {
+		opts.Strategy = Skip
+	}
+	if !slices.Contains([]string{Skip, Overwrite, Merge}, opts.Strategy) {
+		return nil, fmt.Errorf("不支持的冲突处理策略: %s", opts.Strategy)
+	}
+
+	manifest, err := ReadManifest(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Version: manifest.Version, Strategy: opts.Strategy, DryRun: opts.DryRun}
+	present := make(map[string]bool, len(manifest.Tables))
+	for _, t := range manifest.Tables {
+		present[t.Name] = true
+		if !slices.ContainsFunc(tables, func(table Table) bool { return table.Name == t.Name }) {
+			report.Unknown = append(report.Unknown, t.Name)
+		}
+	}
+
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		im := &importer{tx: tx, opts: opts, present: present, ids: make(map[string]map[string]any)}
+		for _, table := range tables {
+			if !present[table.Name] {
+				continue
+			}
+			result, err := im.importTable(zr, table)
+			if err != nil {
+				return fmt.Errorf("导入表 %s 失败: %w", table.Name, err)
+			}
+			report.Tables = append(report.Tables, *result)
+			if err := backup.ResetSequence(tx, table.Name); err != nil {
+				return err
+			}
+		}
+		if opts.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		return report, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	report.Committed = true
+	return report, nil
+}
+
+// importer 一次导入的状态
+type importer struct {
+	tx      *gorm.DB
+	opts    ImportOptions
+	present map[string]bool           // 导出文件中包含的表
+	ids     map[string]map[string]any // 每张表导出文件中的主键与目标数据库中主键的对应关系
+}
+
+func (im *importer) importTable(zr *zip.Reader, table Table) (*TableReport, error) {
+	if !im.tx.Migrator().HasTable(table.Name) {
+		return nil, errors.New("目标数据库缺少该表，请先完成数据库迁移")
+	}
+	columnTypes, err := im.tx.Migrator().ColumnTypes(table.Name)
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]bool, len(columnTypes))
+	for _, column := range columnTypes {
+		columns[column.Name()] = true
+	}
+
+	f, err := zr.Open(table.Name + ".ndjson")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result := &TableReport{Table: table.Name}
+	im.ids[table.Name] = make(map[string]any)
+
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+	for line := 1; ; line++ {
+		var row map[string]any
+		if err := dec.Decode(&row); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("第 %d 行格式错误: %w", line, err)
+		}
+		result.Total++
+
+		for column, value := range row {
+			if _, ok := table.Secrets[column]; ok {
+				delete(row, column)
+				continue
+			}
+			if !columns[column] {
+				delete(row, column)
+				if !slices.Contains(result.Ignored, column) {
+					result.Ignored = append(result.Ignored, column)
+				}
+				continue
+			}
+			row[column] = normalize(value)
+		}
+
+		if err := im.tx.SavePoint(savepoint).Error; err != nil {
+			return nil, err
+		}
+		action, err := im.importRow(table, row)
+		if err != nil {
+			if err := im.tx.RollbackTo(savepoint).Error; err != nil {
+				return nil, err
+			}
+			result.Failed++
+			if len(result.Errors) < maxErrors {
+				result.Errors = append(result.Errors, fmt.Sprintf("第 %d 行: %v", line, err))
+			}
+			continue
+		}
+		if err := im.tx.Exec("RELEASE SAVEPOINT " + savepoint).Error; err != nil {
+			return nil, err
+		}
+
+		switch action {
+		case actionInserted:
+			result.Inserted++
+		case actionUpdated:
+			result.Updated++
+		default:
+			result.Skipped++
+		}
+	}
+	return result, nil
+}
+
+type action int
+
+const (
+	actionSkipped action = iota
+	actionInserted
+	actionUpdated
+)
+
+// importRow 替换引用列后按自然键查找已有数据，存在时按策略处理，否则写入新行
+func (im *importer) importRow(table Table, row map[string]any) (action, error) {
+	oldKey, hasKey := row[table.Key]
+
+	for column, ref := range table.Refs {
+		value, ok := row[column]
+		if !ok || isZero(value) || !im.present[ref.Table] {
+			// 引用的表不在导出文件中时保留原值，视为导入到同一个站点
+			continue
+		}
+		id, ok := im.ids[ref.Table][key(value)]
+		if !ok {
+			if ref.Nullable {
+				row[column] = nil
+				continue
+			}
+			return actionSkipped, fmt.Errorf("%s=%v 引用的 %s 数据不存在", column, value, ref.Table)
+		}
+		row[column] = id
+	}
+
+	existing, err := im.find(table, row)
+	if err != nil {
+		return actionSkipped, err
+	}
+
+	if existing != nil {
+		if hasKey && table.Key != "" {
+			im.ids[table.Name][key(oldKey)] = existing[table.Key]
+		}
+		updates := im.updates(table, existing, row)
+		if len(updates) == 0 {
+			return actionSkipped, nil
+		}
+		if err := im.tx.Table(table.Name).Where(naturalCond(table, row)).Updates(updates).Error; err != nil {
+			return actionSkipped, err
+		}
+		return actionUpdated, nil
+	}
+
+	for column, value := range table.Secrets {
+		row[column] = value
+	}
+	if table.Key == "" {
+		return actionInserted, im.tx.Table(table.Name).Create(row).Error
+	}
+
+	delete(row, table.Key)
+	id, err := im.insert(table, row)
+	if err != nil {
+		return actionSkipped, err
+	}
+	if hasKey {
+		im.ids[table.Name][key(oldKey)] = id
+	}
+	return actionInserted, nil
+}
+
+// find 按自然键查找已有数据，导入的行缺少自然键时视为不存在
+func (im *importer) find(table Table, row map[string]any) (map[string]any, error) {
+	cond := naturalCond(table, row)
+	if len(cond) == 0 || len(cond) != len(table.Natural) {
+		return nil, nil
+	}
+	var rows []map[string]any
+	if err := im.tx.Table(table.Name).Where(cond).Limit(1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return rows[0], nil
+}
+
+// updates 按策略计算需要更新的列，不更新主键、自然键和受保护的列，跳过策略和没有变化时返回空
+func (im *importer) updates(table Table, existing, row map[string]any) map[string]any {
+	updates := make(map[string]any)
+	for column, value := range row {
+		if column == table.Key || slices.Contains(table.Natural, column) || slices.Contains(table.Protected, column) {
+			continue
+		}
+		switch im.opts.Strategy {
+		case Overwrite:
+			updates[column] = value
+		case Merge:
+			current := existing[column]
+			if slices.Contains(table.Counters, column) {
+				if toFloat(value) > toFloat(current) {
+					updates[column] = value
+				}
+			} else if column != deletedColumn && isEmpty(current) && !isEmpty(value) {
+				updates[column] = value
+			}
+		}
+	}
+	return updates
+}
+
+// insert 写入新行并返回目标数据库生成的主键
+func (im *importer) insert(table Table, row map[string]any) (any, error) {
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	var (
+		quoted       = make([]string, len(columns))
+		placeholders = make([]string, len(columns))
+		values       = make([]any, len(columns))
+	)
+	for i, column := range columns {
+		quoted[i] = im.tx.Statement.Quote(column)
+		placeholders[i] = "?"
+		values[i] = row[column]
+	}
+
+	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING %s",
+		im.tx.Statement.Quote(table.Name), strings.Join(quoted, ", "), strings.Join(placeholders, ", "), im.tx.Statement.Quote(table.Key))
+	if len(columns) == 0 {
+		sql = fmt.Sprintf("INSERT INTO %s DEFAULT VALUES RETURNING %s", im.tx.Statement.Quote(table.Name), im.tx.Statement.Quote(table.Key))
+	}
+
+	var id any
+	if err := im.tx.Raw(sql, values...).Row().Scan(&id); err != nil {
+		return nil, err
+	}
+	return id, nil
+}
+
+func naturalCond(table Table, row map[string]any) map[string]any {
+	cond := make(map[string]any, len(table.Natural))
+	for _, column := range table.Natural {
+		if value, ok := row[column]; ok && value != nil {
+			cond[column] = value
+		}
+	}
+	return cond
+}
+
+// normalize 将 JSON 数字转换为整数或浮点数
+func normalize(value any) any {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+	if i, err := number.Int64(); err == nil {
+		return i
+	}
+	f, _ := number.Float64()
+	return f
+}
+
+// key 主键在对应关系中的键
+func key(value any) string {
+	return fmt.Sprint(value)
+}
+
+// isZero 引用列为空或0时表示没有引用
+func isZero(value any) bool {
+	return value == nil || key(value) == "0"
+}
+
+func isEmpty(value any) bool {
+	return value == nil || value == ""
+}
+
+func toFloat(value any) float64 {
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,206 @@
+package transfer
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestUpdates(t *testing.T) {
+	table := Table{Name: "blogs", Key: "id", Natural: []string{"title"}, Counters: []string{"eye_count"}}
+	existing := map[string]any{"id": int64(3), "title": "a", "description": "", "eye_count": int64(10), "deleted_at": nil, "content": "old"}
+	row := map[string]any{"id": int64(1), "title": "a", "description": "desc", "eye_count": int64(20), "deleted_at": "2024-01-01", "content": "new"}
+
+	im := &importer{opts: ImportOptions{Strategy: Merge}}
+	got := im.updates(table, existing, row)
+	want := map[string]any{"description": "desc", "eye_count": int64(20)}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("merge updates = %v, want %v", got, want)
+	}
+
+	im.opts.Strategy = Overwrite
+	if got := im.updates(table, existing, row); len(got) != 4 || got["content"] != "new" {
+		t.Fatalf("overwrite updates = %v", got)
+	}
+
+	im.opts.Strategy = Skip
+	if got := im.updates(table, existing, row); len(got) != 0 {
+		t.Fatalf("skip updates = %v", got)
+	}
+
+	// 受保护的列即使覆盖也不会更新
+	table.Protected = []string{"content"}
+	im.opts.Strategy = Overwrite
+	if got := im.updates(table, existing, row); len(got) != 3 || got["content"] != nil {
+		t.Fatalf("protected updates = %v", got)
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	for _, tc := range []struct {
+		manifest Manifest
+		ok       bool
+	}{
+		{Manifest{Format: Format, Version: Version}, true},
+		{Manifest{Format: Format, Version: Version + 1}, false},
+		{Manifest{Format: "other", Version: Version}, false},
+	} {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, _ := zw.Create(ManifestName)
+		json.NewEncoder(w).Encode(tc.manifest)
+		zw.Close()
+
+		zr, _ := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if _, err := ReadManifest(zr); (err == nil) != tc.ok {
+			t.Fatalf("manifest %+v err = %v", tc.manifest, err)
+		}
+	}
+}
+
+// testDB 连接 BLOG_TEST_DSN 指定的 PostgreSQL 测试库，未设置时跳过
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("BLOG_TEST_DSN")
+	if dsn == "" {
+		t.Skip("未设置 BLOG_TEST_DSN")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// exportFile 按导入顺序构造导出文件
+func exportFile(t *testing.T, tables []Table, rows map[string][]map[string]any) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	manifest := Manifest{Format: Format, Version: Version}
+	for _, table := range tables {
+		w, _ := zw.Create(table.Name + ".ndjson")
+		enc := json.NewEncoder(w)
+		for _, row := range rows[table.Name] {
+			enc.Encode(row)
+		}
+		manifest.Tables = append(manifest.Tables, ManifestTable{Name: table.Name, Rows: int64(len(rows[table.Name]))})
+	}
+	w, _ := zw.Create(ManifestName)
+	json.NewEncoder(w).Encode(manifest)
+	zw.Close()
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zr
+}
+
+func TestImport(t *testing.T) {
+	db := testDB(t)
+	drop := func() {
+		db.Exec("DROP TABLE IF EXISTS transfer_test_posts, transfer_test_users, transfer_test_roles")
+	}
+	drop()
+	t.Cleanup(drop)
+
+	for _, sql := range []string{
+		"CREATE TABLE transfer_test_roles (id serial PRIMARY KEY, name text UNIQUE)",
+		"CREATE TABLE transfer_test_users (id serial PRIMARY KEY, username text UNIQUE, password text NOT NULL, email text, nickname text, role_id int REFERENCES transfer_test_roles(id))",
+		"CREATE TABLE transfer_test_posts (id serial PRIMARY KEY, title text, views int, user_id int REFERENCES transfer_test_users(id))",
+		"INSERT INTO transfer_test_roles (name) VALUES ('admin'), ('user')",
+		"INSERT INTO transfer_test_users (username, password, email, nickname, role_id) VALUES ('alice', 'hash', 'a@example.com', 'old', 2)",
+	} {
+		if err := db.Exec(sql).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tables := []Table{
+		{Name: "transfer_test_roles", Key: "id", Natural: []string{"name"}},
+		{Name: "transfer_test_users", Key: "id", Natural: []string{"username"},
+			Refs:      map[string]Ref{"role_id": {Table: "transfer_test_roles"}},
+			Secrets:   map[string]any{"password": ""},
+			Protected: []string{"email", "role_id"}},
+		{Name: "transfer_test_posts", Key: "id", Natural: []string{"title"}, Counters: []string{"views"},
+			Refs: map[string]Ref{"user_id": {Table: "transfer_test_users"}}},
+	}
+	file := func(views int) *zip.Reader {
+		return exportFile(t, tables, map[string][]map[string]any{
+			"transfer_test_roles": {{"id": 5, "name": "user"}, {"id": 6, "name": "editor"}},
+			"transfer_test_users": {
+				{"id": 7, "username": "alice", "password": "evil", "email": "evil@example.com", "nickname": "new", "role_id": 6},
+				{"id": 8, "username": "bob", "password": "evil", "email": "b@example.com", "nickname": "bob", "role_id": 6},
+			},
+			"transfer_test_posts": {{"id": 9, "title": "post", "views": views, "user_id": 8}},
+		})
+	}
+
+	report, err := Import(context.Background(), db, file(10), tables, ImportOptions{Strategy: Overwrite, DryRun: true})
+	if err != nil || report.Committed {
+		t.Fatalf("试运行 report = %+v, err = %v", report, err)
+	}
+	var count int64
+	db.Table("transfer_test_users").Count(&count)
+	if count != 1 {
+		t.Fatalf("试运行不应写入, users = %d", count)
+	}
+
+	if _, err := Import(context.Background(), db, file(10), tables, ImportOptions{Strategy: Overwrite}); err != nil {
+		t.Fatal(err)
+	}
+
+	type user struct {
+		ID       int
+		Password string
+		Email    string
+		Nickname string
+		RoleID   int
+	}
+	var alice, bob user
+	db.Table("transfer_test_users").Where("username = ?", "alice").Take(&alice)
+	db.Table("transfer_test_users").Where("username = ?", "bob").Take(&bob)
+	if alice.Nickname != "new" || alice.Password != "hash" || alice.Email != "a@example.com" || alice.RoleID != 2 {
+		t.Errorf("覆盖已有用户时不应修改密码、邮箱和角色, alice = %+v", alice)
+	}
+
+	// 新的角色和用户由目标数据库生成主键，引用按新旧ID的对应关系替换
+	var editor int
+	db.Table("transfer_test_roles").Where("name = ?", "editor").Select("id").Scan(&editor)
+	if bob.ID != 2 || bob.RoleID != editor || editor != 3 || bob.Password != "" {
+		t.Errorf("bob = %+v, editor = %d", bob, editor)
+	}
+	var owner int
+	db.Table("transfer_test_posts").Where("title = ?", "post").Select("user_id").Scan(&owner)
+	if owner != bob.ID {
+		t.Errorf("post user_id = %d, 期望 %d", owner, bob.ID)
+	}
+
+	// 合并时计数列取较大值，跳过时不修改
+	for _, tc := range []struct {
+		strategy string
+		views    int
+		want     int
+	}{
+		{Merge, 5, 10},
+		{Merge, 20, 20},
+		{Skip, 30, 20},
+	} {
+		if _, err := Import(context.Background(), db, file(tc.views), tables, ImportOptions{Strategy: tc.strategy}); err != nil {
+			t.Fatal(err)
+		}
+		var views int
+		db.Table("transfer_test_posts").Where("title = ?", "post").Select("views").Scan(&views)
+		if views != tc.want {
+			t.Errorf("%s 导入 views=%d 后为 %d, 期望 %d", tc.strategy, tc.views, views, tc.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package transfer
+
+import (
+	"archive/zip"
+	"blog/internal/backup"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Format 导出文件的格式标识
+const Format = "blog-export"
+
+// Version 导出格式的版本，导入时拒绝更高的版本
+const Version = 1
+
+// ManifestName 导出清单在 zip 中的文件名
+const ManifestName = "manifest.json"
+
+// Ext 导出文件的扩展名
+const Ext = ".zip"
+
+// Ref 引用其他表自增主键的列，导入时替换为目标数据库中的ID
+type Ref struct {
+	Table    string
+	Nullable bool // 引用的数据不存在时置空，否则该行导入失败
+}
+
+// Table 可导出和导入的表
+type Table struct {
+	Name     string
+	Key      string         // 自增主键列，新行由目标数据库生成主键并记录新旧ID的对应关系；为空时按原值写入
+	Natural  []string       // 判断数据是否已存在的列，引用列使用替换后的值比较
+	Refs     map[string]Ref // 引用其他表的列
+	Counters []string       // 合并时取较大值的计数列
+
+	Secrets   map[string]any // 不导出的列，如密码；导入时忽略文件中的值，写入新行时使用这里的值
+	Protected []string       // 导入时不更新已有数据的列，如邮箱和角色
+}
+
+// Manifest 导出清单
+type Manifest struct {
+	Format    string          `json:"format"`
+	Version   int             `json:"version"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Tables    []ManifestTable `json:"tables"`
+}
+
+// ManifestTable 清单中的表，按导入顺序排列
+type ManifestTable struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+}
+
+// Export 在同一个快照中按顺序将每张表导出为 zip 中的一个 NDJSON 文件，最后写入清单
+func Export(ctx context.Context, db *gorm.DB, w io.Writer, tables []Table) (*Manifest, error) {
+	zw := zip.NewWriter(w)
+	manifest := &Manifest{Format: Format, Version: Version, CreatedAt: time.Now()}
+
+	err := backup.Snapshot(ctx, db, func(tx *gorm.DB) error {
+		for _, table := range tables {
+			fw, err := zw.Create(table.Name + ".ndjson")
+			if err != nil {
+				return err
+			}
+			query := tx.Table(table.Name)
+			if len(table.Secrets) > 0 {
+				columns, err := exportColumns(tx, table)
+				if err != nil {
+					return fmt.Errorf("导出表 %s 失败: %w", table.Name, err)
+				}
+				query = query.Select(columns)
+			}
+			if table.Key != "" {
+				query = query.Order(table.Key)
+			}
+			rows, err := query.Rows()
+			if err != nil {
+				return fmt.Errorf("导出表 %s 失败: %w", table.Name, err)
+			}
+			count, err := backup.WriteRows(rows, fw)
+			if err != nil {
+				return fmt.Errorf("导出表 %s 失败: %w", table.Name, err)
+			}
+			manifest.Tables = append(manifest.Tables, ManifestTable{Name: table.Name, Rows: count})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fw, err := zw.Create(ManifestName)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fw.Write(data); err != nil {
+		return nil, err
+	}
+	return manifest, zw.Close()
+}
+
+// exportColumns 表中除 Secrets 之外的列
+func exportColumns(tx *gorm.DB, table Table) ([]string, error) {
+	columnTypes, err := tx.Migrator().ColumnTypes(table.Name)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, 0, len(columnTypes))
+	for _, column := range columnTypes {
+		if _, ok := table.Secrets[column.Name()]; !ok {
+			columns = append(columns, column.Name())
+		}
+	}
+	return columns, nil
+}
+
+// ReadManifest 读取并检查导出清单
+func ReadManifest(zr *zip.Reader) (*Manifest, error) {
+	f, err := zr.Open(ManifestName)
+	if err != nil {
+		return nil, errors.New("不是有效的导出文件，缺少 " + ManifestName)
+	}
+	defer f.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(f).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("读取导出清单失败: %w", err)
+	}
+	if manifest.Format != Format {
+		return nil, fmt.Errorf("不支持的导出格式: %s", manifest.Format)
+	}
+	if manifest.Version > Version {
+		return nil, fmt.Errorf("导出文件版本 %d 高于当前支持的版本 %d，请先升级", manifest.Version, Version)
+	}
+	return &manifest, nil
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"bytes"
 	"crypto/md5"
 	"encoding/hex"
 	"mime/multipart"
@@ -34,3 +35,25 @@ func CalculateMD5(fileHeader *multipart.FileHeader) string {
 
 	return hex.EncodeToString(hash.Sum(nil)) // 返回MD5值
 }
+
+// NewFileHeader 将内存中的数据包装为上传文件，用于复用处理上传文件的逻辑
+func NewFileHeader(field, filename string, data []byte) (*multipart.FileHeader, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreateFormFile(field, filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	form, err := multipart.NewReader(&buf, writer.Boundary()).ReadForm(int64(len(data)) + 4096)
+	if err != nil {
+		return nil, err
+	}
+	return form.File[field][0], nil
+}
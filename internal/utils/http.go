@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress 请求的地址不是公网地址
+var ErrPrivateAddress = errors.New("不允许访问内网地址")
+
+// cgnat 运营商级NAT使用的共享地址段，同样不属于公网
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// NewPublicClient 只能访问公网地址的 HTTP 客户端，用于请求由用户提供的地址；
+// 在建立连接时检查解析后的IP，重定向和 DNS 重绑定都无法绕过，不使用环境变量中的代理
+func NewPublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !IsPublicAddress(host) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     time.Minute,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("重定向次数过多")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("不支持重定向到 %s", req.URL.Scheme)
+			}
+			if ip := net.ParseIP(req.URL.Hostname()); ip != nil && !IsPublicAddress(ip.String()) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, req.URL.Hostname())
+			}
+			return nil
+		},
+	}
+}
+
+// IsPublicAddress 是否为公网IP，回环、内网、链路本地、组播和未指定地址都不是公网地址
+func IsPublicAddress(host string) bool {
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !cgnat.Contains(addr)
+}
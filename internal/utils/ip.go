@@ -8,21 +8,10 @@ import (
 	"github.com/lionsoul2014/ip2region/binding/golang/xdb"
 )
 
-// GetIPAddress 从请求中获取客户端的IP地址
+// GetIPAddress 从请求中获取客户端的IP地址，只有来自信任代理的请求才读取 ProxyHeader，
+// 客户端自行携带的 X-Forwarded-For 等请求头无法伪造IP
 func GetIPAddress(request fiber.Ctx) string {
-	ipAddress := request.Get("X-Forwarded-For") // 尝试获取X-Forwarded-For头部
-
-	// 如果没有获取到IP地址，尝试其他头部
-	if ipAddress == "" || strings.ToLower(ipAddress) == "unknown" {
-		ipAddress = request.Get("Proxy-Client-IP")
-	}
-	if ipAddress == "" || strings.ToLower(ipAddress) == "unknown" {
-		ipAddress = request.Get("WL-Proxy-Client-IP")
-	}
-	if ipAddress == "" || strings.ToLower(ipAddress) == "unknown" {
-		ipAddress = request.IP() // 最后尝试获取直接的IP地址
-	}
-	return ipAddress
+	return request.IP()
 }
 
 func GetIpAndCitp(ctx fiber.Ctx) (string, string) {
@@ -55,25 +44,62 @@ func GetIpCity(ip string) string {
 	return strings.ReplaceAll(split[0]+" "+split[2]+" "+split[3], "0", "") // 返回城市信息
 }
 
-// GetClientPlatformInfo 获取客户端平台信息
-func GetClientPlatformInfo(userAgent string) string {
+// GetIpRegion 根据IP地址获取国家和省份，查询失败或未知时返回"未知"
+func GetIpRegion(ip string) (string, string) {
+	region, err := searcher.SearchByStr(ip)
+	if err != nil {
+		return "未知", "未知"
+	}
+
+	var split = strings.Split(region, "|")
+	if len(split) < 3 {
+		return "未知", "未知"
+	}
+
+	country, province := split[0], split[2]
+	if country == "0" || country == "" {
+		country = "未知"
+	}
+	if province == "0" || province == "" {
+		province = "未知"
+	}
+	return country, province
+}
+
+// botKeywords 爬虫和脚本客户端的特征
+var botKeywords = []string{"bot", "spider", "crawl", "slurp", "curl", "wget", "python", "java/", "go-http-client", "headless", "lighthouse", "feedfetcher", "facebookexternalhit", "okhttp", "httpclient"}
+
+// IsBot 根据用户代理判断是否为爬虫或脚本
+func IsBot(userAgent string) bool {
 	if userAgent == "" {
-		return "" // 如果用户代理为空，返回空字符串
+		return true
 	}
+	userAgent = strings.ToLower(userAgent)
+	for _, keyword := range botKeywords {
+		if strings.Contains(userAgent, keyword) {
+			return true
+		}
+	}
+	return false
+}
 
+// GetClientOSAndBrowser 获取客户端的操作系统和浏览器，未匹配到时为空字符串
+func GetClientOSAndBrowser(userAgent string) (string, string) {
 	userAgent = strings.ToLower(userAgent) // 转为小写以便于匹配
 
 	var os, browser string
-	// 匹配操作系统
+	// 匹配操作系统，iPhone/iPad 的用户代理中也包含 mac，需要先匹配
 	switch {
 	case strings.Contains(userAgent, "windows"):
 		os = "Windows"
+	case strings.Contains(userAgent, "iphone") || strings.Contains(userAgent, "ipad"):
+		os = "iOS"
 	case strings.Contains(userAgent, "mac"):
 		os = "Mac"
 	case strings.Contains(userAgent, "android"):
 		os = "Android"
-	case strings.Contains(userAgent, "iphone") || strings.Contains(userAgent, "ipad"):
-		os = "iOS"
+	case strings.Contains(userAgent, "linux"):
+		os = "Linux"
 	}
 	// 匹配浏览器
 	switch {
@@ -88,11 +114,21 @@ func GetClientPlatformInfo(userAgent string) string {
 	case strings.Contains(userAgent, "safari"):
 		browser = "Safari"
 	}
+	return os, browser
+}
+
+// GetClientPlatformInfo 获取客户端平台信息
+func GetClientPlatformInfo(userAgent string) string {
+	if userAgent == "" {
+		return "" // 如果用户代理为空，返回空字符串
+	}
+
+	os, browser := GetClientOSAndBrowser(userAgent)
 
 	// 返回操作系统和浏览器信息
 	if os != "" && browser != "" {
 		return fmt.Sprintf("%s %s", os, browser)
 	} else {
-		return userAgent // 如果未匹配到，返回原始用户代理
+		return strings.ToLower(userAgent) // 如果未匹配到，返回原始用户代理
 	}
 }
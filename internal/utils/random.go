@@ -1,6 +1,9 @@
 package utils
 
 import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"math/rand"
 	"strconv"
 )
@@ -9,3 +12,18 @@ import (
 func RandomNumberCode() string {
 	return strconv.Itoa(rand.Intn(900000) + 100000)
 }
+
+// RandomToken 生成 n 字节的安全随机数，以十六进制字符串返回
+func RandomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := crand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// HashToken 计算令牌的 SHA256 摘要
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"blog/internal/models"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// NewSystemLog 根据请求创建操作日志，填充请求信息和当前登录的用户
+func NewSystemLog(ctx fiber.Ctx, module, action, message string) *models.SystemLogInfo {
+	ip, city := GetIpAndCitp(ctx)
+	logInfo := &models.SystemLogInfo{
+		Module:     module,
+		Action:     action,
+		IP:         ip,
+		Location:   city,
+		RequestURL: ctx.OriginalURL(),
+		Method:     ctx.Method(),
+		Message:    message,
+		UserAgent:  GetClientPlatformInfo(ctx.Get("User-Agent")),
+	}
+
+	if user, ok := ctx.Locals("user").(*models.User); ok {
+		logInfo.OperatorID = user.ID
+		logInfo.OperatorName = user.NickName
+		logInfo.Email = user.Email
+	}
+	return logInfo
+}
@@ -3,64 +3,34 @@ package main
 import (
 	"blog/internal/job"
 	"blog/internal/router"
-	"blog/internal/utils"
+	"blog/internal/service"
 	"blog/pkg/configs"
 	"blog/pkg/logger"
-	"blog/pkg/smail"
-	"log"
+	"context"
+	"flag"
 	"os"
-)
-
-func init() {
-
-	// 加载全局配置
-	configs.LoadGlobalConfig("application.yml")
-
-	// 初始化日志系统
-	logger.InitLogger(configs.CONFIG.Logger)
-
-	// 确保 LOGGER 已经初始化
-	if logger.Logger == nil {
-		log.Fatal("Logger is not initialized")
-	}
 
-	logger.Info("全局配置加载成功")
-	logger.Info("日志初始化成功")
-
-	// 初始化SMTP邮箱
-	smail.InitSmtp(configs.CONFIG.Mail)
-	logger.Info("SMTP邮箱初始化成功")
-
-	if configs.CONFIG.Env != "dev" {
-		// 初始化数据库
-		log.Println("加载数据库")
-		configs.LoadDBConfig(configs.CONFIG.Db)
-		log.Println("数据库加载完毕")
+	"go.uber.org/zap"
+)
 
+// 程序入口，不带参数时启动服务，带参数时执行对应的子命令
+func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
 	}
+	os.Exit(runCommand("serve", nil))
+}
 
-	log.Println("加载Redis")
-	configs.LoadRedis(configs.CONFIG.Redis)
-	log.Println("Redis加载完毕")
-
-	// 加载IP数据库
-	log.Println("加载IP数据库")
-	utils.LoadIpDB(configs.CONFIG.IpDbPath)
-	log.Println("IP数据库加载完毕")
-
-	// 创建上传目录
-	if err := os.MkdirAll(configs.CONFIG.Upload.Path, os.ModePerm); err != nil {
-		log.Fatalf("创建上传目录失败: %v", err)
+// serveCommand 启动服务
+// 用法: blog serve
+func serveCommand(args []string) int {
+	if code, ok := parseFlags(flag.NewFlagSet("serve", flag.ContinueOnError), args); !ok {
+		return code
 	}
-	log.Println("上传目录创建完毕")
 
-	// 加载搜索配置
-	configs.LoadSearchConfig(configs.CONFIG.Search)
-	log.Println("搜索配置加载完毕")
-}
+	// 检查数据库结构，数据库不是最新版本时拒绝启动
+	prepareDatabase()
 
-// 程序入口
-func main() {
 	// 获取服务器配置
 	var config = &configs.CONFIG.Server
 
@@ -77,11 +47,29 @@ func main() {
 
 	// 启动服务器
 	server.Run()
+	return exitOK
+}
+
+// prepareDatabase 开启 autoCreate 时自动执行未执行的迁移，否则只检查数据库是否为最新版本
+func prepareDatabase() {
+	if configs.DB == nil {
+		return
+	}
+
+	if err := service.NewMigrationService().Prepare(context.Background(), configs.CONFIG.Db.AutoCreate); err != nil {
+		logger.Fatal("数据库结构检查失败，请先执行 blog migrate up", zap.Error(err))
+	}
+	logger.Info("数据库结构检查通过")
+
+	service.NewUserService().ExpireLegacyAdmin()
 }
 
 // registerRouters 注册所有路由
 func registerRouters(server *router.Server) {
+	server.AddRouter(router.RegisterSetupRouter)
 	server.AddRouter(router.RegisterUserRouter)
+	server.AddRouter(router.RegisterAccessTokenRouter)
+	server.AddRouter(router.RegisterOAuthRouter)
 	server.AddRouter(router.RegisterFileRouter)
 	server.AddRouter(router.RegisterBlogRouter)
 	server.AddRouter(router.RegisterCategoryRouter)
@@ -89,4 +77,7 @@ func registerRouters(server *router.Server) {
 	server.AddRouter(router.RegisterTopicRouter)
 	server.AddRouter(router.RegisterDataBaseRouter)
 	server.AddRouter(router.RegisterConsoleRouter)
+	server.AddRouter(router.RegisterJobRouter)
+	server.AddRouter(router.RegisterBackupRouter)
+	server.AddRouter(router.RegisterHealthRouter)
 }
@@ -0,0 +1,115 @@
+package background
+
+import (
+	"blog/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// hook 关闭时执行的收尾操作
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+var (
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	closed bool
+	hooks  []hook
+
+	draining atomic.Bool
+)
+
+// Drain 标记服务开始关闭，在停止接收请求之前调用，就绪检查随即返回不可用
+func Drain() {
+	draining.Store(true)
+}
+
+// Draining 服务是否已经开始关闭
+func Draining() bool {
+	return draining.Load()
+}
+
+// Go 在后台执行任务，服务关闭时会等待任务完成；关闭开始后直接在当前协程执行
+func Go(fn func()) {
+	mu.Lock()
+	if closed {
+		mu.Unlock()
+		run(fn)
+		return
+	}
+	wg.Add(1)
+	mu.Unlock()
+
+	go func() {
+		defer wg.Done()
+		run(fn)
+	}()
+}
+
+// OnShutdown 注册关闭时的收尾操作，在后台任务全部完成后按注册顺序执行，同名操作只注册一次
+func OnShutdown(name string, fn func(ctx context.Context) error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, h := range hooks {
+		if h.name == name {
+			return
+		}
+	}
+	hooks = append(hooks, hook{name: name, fn: fn})
+}
+
+// Shutdown 等待后台任务完成并执行收尾操作，ctx 到期时不再等待剩余任务；
+// 每个收尾操作使用独立的 hookTimeout，等待任务超时后仍能写入缓冲的数据
+func Shutdown(ctx context.Context, hookTimeout time.Duration) error {
+	mu.Lock()
+	closed = true
+	list := hooks
+	mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	var errs []error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("等待后台任务超时: %w", ctx.Err()))
+	}
+
+	for _, h := range list {
+		if err := runHook(h, hookTimeout); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runHook 在独立的超时时间内执行收尾操作
+func runHook(h hook, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return h.fn(ctx)
+}
+
+// run 执行任务并恢复 panic，避免后台任务导致进程退出
+func run(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("后台任务发生panic", zap.Any("panic", r), zap.String("stack", string(debug.Stack())))
+		}
+	}()
+	fn()
+}
@@ -0,0 +1,38 @@
+package background
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShutdownWaitsForTasks(t *testing.T) {
+	var finished, flushed atomic.Bool
+
+	Go(func() {
+		time.Sleep(time.Millisecond * 30)
+		finished.Store(true)
+	})
+	OnShutdown("flush", func(ctx context.Context) error {
+		// 收尾操作在后台任务完成之后执行，使用自己的超时时间
+		flushed.Store(finished.Load() && ctx.Err() == nil)
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := Shutdown(ctx, time.Second); err != nil {
+		t.Fatalf("Shutdown err = %v", err)
+	}
+	if !finished.Load() || !flushed.Load() {
+		t.Fatalf("finished = %v, flushed = %v", finished.Load(), flushed.Load())
+	}
+
+	// 关闭后提交的任务同步执行
+	var ran bool
+	Go(func() { ran = true })
+	if !ran {
+		t.Fatal("关闭后的任务未同步执行")
+	}
+}
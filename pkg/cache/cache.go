@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound 键或字段不存在
+	ErrNotFound = errors.New("cache: key not found")
+	// ErrUnavailable 缓存服务不可用，如 Redis 断开连接
+	ErrUnavailable = errors.New("cache: unavailable")
+	// ErrWrongType 对键执行了类型不匹配的操作
+	ErrWrongType = errors.New("cache: wrong type")
+)
+
+// Cache 缓存接口，语义与 Redis 对应命令保持一致，expire 小于等于 0 表示永不过期
+type Cache interface {
+	// Get 获取字符串，不存在时返回 ErrNotFound
+	Get(key string) (string, error)
+	// Set 设置字符串
+	Set(key string, value string, expire time.Duration) error
+	// SetNX 键不存在时才设置，返回是否设置成功
+	SetNX(key string, value string, expire time.Duration) (bool, error)
+	// GetDel 获取并删除字符串，不存在时返回 ErrNotFound
+	GetDel(key string) (string, error)
+	// Del 删除键
+	Del(keys ...string) error
+	// Expire 设置过期时间
+	Expire(key string, expire time.Duration) error
+	// Incr 自增并返回新值，expire 大于 0 时同时刷新过期时间
+	Incr(key string, expire time.Duration) (int64, error)
+	// IncrBy 增加 delta 并返回新值，expire 大于 0 时同时刷新过期时间
+	IncrBy(key string, delta int64, expire time.Duration) (int64, error)
+	// CompareAndDelete 值等于 value 时才删除键，返回是否删除
+	CompareAndDelete(key, value string) (bool, error)
+	// CompareAndExpire 值等于 value 时才刷新过期时间，返回是否刷新
+	CompareAndExpire(key, value string, expire time.Duration) (bool, error)
+	// Scan 按游标分批遍历匹配的键，count 为每批检查的键数量，返回的游标为 0 时表示遍历结束
+	Scan(cursor uint64, match string, count int64) ([]string, uint64, error)
+
+	// HGet 获取哈希字段，不存在时返回 ErrNotFound
+	HGet(key, field string) (string, error)
+	// HSet 设置哈希字段
+	HSet(key, field, value string) error
+	// HDel 删除哈希字段
+	HDel(key string, fields ...string) error
+	// HGetAll 获取哈希的所有字段
+	HGetAll(key string) (map[string]string, error)
+	// HVals 获取哈希的所有值
+	HVals(key string) ([]string, error)
+	// HIncrOrInit 哈希字段自增，字段不存在时初始化为 init 并返回 init
+	HIncrOrInit(key, field string, init int64) (int64, error)
+	// HIncrBy 哈希字段增加 delta 并返回新值
+	HIncrBy(key, field string, delta int64) (int64, error)
+
+	// SAdd 添加集合成员
+	SAdd(key string, members ...string) error
+	// SRandMemberN 随机获取 n 个不重复的集合成员
+	SRandMemberN(key string, n int64) ([]string, error)
+
+	// PFAdd 向基数统计（HyperLogLog）添加元素，expire 大于 0 时同时刷新过期时间
+	PFAdd(key string, expire time.Duration, elements ...string) error
+	// PFCount 统计一个或多个基数统计合并后的不重复元素数量，Redis 实现为近似值
+	PFCount(keys ...string) (int64, error)
+
+	// Ping 检查缓存是否可用
+	Ping() error
+	// Close 关闭连接
+	Close() error
+}
+
+// expireAt 计算过期时间点，零值表示永不过期
+func expireAt(expire time.Duration) time.Time {
+	if expire <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(expire)
+}
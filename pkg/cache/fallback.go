@@ -0,0 +1,486 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// FallbackCache Redis 故障时自动降级到进程内缓存，恢复后切回
+// 降级期间写过的键会在恢复时从 Redis 中删除，避免读到过期的旧数据；
+// 计数器（Incr、HIncrOrInit、PFAdd）不删除，恢复时把降级期间的增量合并到 Redis，浏览量等计数不会丢失；
+// 锁相关的操作（SetNX、CompareAndDelete、CompareAndExpire）不降级，每个实例的进程内缓存互不相通，降级后会让所有实例同时取得锁
+type FallbackCache struct {
+	primary   Cache
+	secondary *MemoryCache
+	interval  time.Duration
+
+	healthy  atomic.Bool
+	mu       sync.Mutex
+	dirty    map[string]struct{}
+	counters map[string]*counter
+}
+
+// counter 降级期间计数器的增量
+type counter struct {
+	incr   int64            // Incr 增加的值
+	fields map[string]int64 // HIncrOrInit 各字段的自增次数
+	pf     bool             // 是否为基数统计
+	expire time.Duration    // 最近一次设置的过期时间
+}
+
+// NewFallbackCache 创建可降级的缓存，interval 为故障后探测主缓存的间隔
+func NewFallbackCache(primary Cache, secondary *MemoryCache, interval time.Duration) *FallbackCache {
+	f := &FallbackCache{
+		primary:   primary,
+		secondary: secondary,
+		interval:  interval,
+		dirty:     make(map[string]struct{}),
+		counters:  make(map[string]*counter),
+	}
+	f.healthy.Store(true)
+	return f
+}
+
+// Healthy 主缓存是否可用
+func (f *FallbackCache) Healthy() bool {
+	return f.healthy.Load()
+}
+
+// MarkDown 标记主缓存不可用并开始后台探测
+func (f *FallbackCache) MarkDown(err error) {
+	if !f.healthy.CompareAndSwap(true, false) {
+		return
+	}
+	zap.L().Warn("缓存服务不可用，已降级到进程内缓存", zap.Error(err))
+	go f.probe()
+}
+
+// probe 定时探测主缓存，恢复后清理降级期间写过的键、合并计数器再切回
+func (f *FallbackCache) probe() {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := f.primary.Ping(); err != nil {
+			continue
+		}
+
+		deleted, merged, err := f.sync()
+		if err != nil {
+			zap.L().Warn("同步降级期间的缓存失败", zap.Error(err))
+			continue
+		}
+
+		f.secondary.Flush()
+		zap.L().Info("缓存服务已恢复", zap.Int("deleted", deleted), zap.Int("merged", merged))
+		return
+	}
+}
+
+// sync 把降级期间的变更同步到主缓存，没有剩余变更时切回主缓存，返回删除的键和合并的计数器数量；
+// 失败时未同步的变更保留到下次探测
+func (f *FallbackCache) sync() (int, int, error) {
+	var deleted, merged int
+	for {
+		f.mu.Lock()
+		if len(f.dirty) == 0 && len(f.counters) == 0 {
+			f.healthy.Store(true)
+			f.mu.Unlock()
+			return deleted, merged, nil
+		}
+		dirty, counters := f.dirty, f.counters
+		f.dirty, f.counters = make(map[string]struct{}), make(map[string]*counter)
+		f.mu.Unlock()
+
+		keys := make([]string, 0, len(dirty))
+		for key := range dirty {
+			keys = append(keys, key)
+		}
+		if err := f.primary.Del(keys...); err != nil {
+			f.restore(dirty, counters)
+			return deleted, merged, err
+		}
+		deleted += len(keys)
+
+		for key, c := range counters {
+			if err := f.merge(key, c); err != nil {
+				f.restore(nil, counters)
+				return deleted, merged, err
+			}
+			delete(counters, key)
+			merged++
+		}
+	}
+}
+
+// restore 放回未同步的变更，与同步期间新产生的变更合并
+func (f *FallbackCache) restore(dirty map[string]struct{}, counters map[string]*counter) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for key := range dirty {
+		f.dirty[key] = struct{}{}
+	}
+	for key, c := range counters {
+		current, ok := f.counters[key]
+		if !ok {
+			f.counters[key] = c
+			continue
+		}
+		current.incr += c.incr
+		current.pf = current.pf || c.pf
+		for field, n := range c.fields {
+			if current.fields == nil {
+				current.fields = make(map[string]int64)
+			}
+			current.fields[field] += n
+		}
+	}
+}
+
+// merge 把一个计数器在降级期间的增量合并到主缓存，已合并的部分会从 c 中清除，失败后重试不会重复计数
+func (f *FallbackCache) merge(key string, c *counter) error {
+	if c.pf {
+		if members := f.secondary.pfMembers(key); len(members) > 0 {
+			return f.primary.PFAdd(key, c.expire, members...)
+		}
+		return nil
+	}
+
+	if c.incr != 0 {
+		if _, err := f.primary.IncrBy(key, c.incr, c.expire); err != nil {
+			return err
+		}
+		c.incr = 0
+	}
+
+	for field, n := range c.fields {
+		if err := f.mergeField(key, field, n); err != nil {
+			return err
+		}
+		delete(c.fields, field)
+	}
+	if c.fields != nil && c.expire > 0 {
+		return f.primary.Expire(key, c.expire)
+	}
+	return nil
+}
+
+// mergeField 合并哈希字段的增量，主缓存中不存在该字段时使用进程内缓存中的值，以保留 HIncrOrInit 的初始值
+func (f *FallbackCache) mergeField(key, field string, n int64) error {
+	_, err := f.primary.HGet(key, field)
+	if err == nil {
+		_, err = f.primary.HIncrBy(key, field, n)
+		return err
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	val, err := f.secondary.HGet(key, field)
+	if err != nil {
+		return nil
+	}
+	return f.primary.HSet(key, field, val)
+}
+
+// failed 判断主缓存是否因不可用而失败
+func (f *FallbackCache) failed(err error) bool {
+	if errors.Is(err, ErrUnavailable) {
+		f.MarkDown(err)
+		return true
+	}
+	return false
+}
+
+// touch 记录降级期间写过的键，计数器的键不删除
+func (f *FallbackCache) touch(keys ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, key := range keys {
+		if _, ok := f.counters[key]; !ok {
+			f.dirty[key] = struct{}{}
+		}
+	}
+}
+
+// count 记录降级期间计数器的增量
+func (f *FallbackCache) count(key string, update func(c *counter)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, ok := f.counters[key]
+	if !ok {
+		c = &counter{}
+		f.counters[key] = c
+	}
+	update(c)
+}
+
+// forget 计数器在降级期间被删除时，已经读取的增量不再合并
+func (f *FallbackCache) forget(key string, fields ...string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, ok := f.counters[key]
+	if !ok {
+		return false
+	}
+	if len(fields) == 0 {
+		delete(f.counters, key)
+		return true
+	}
+	for _, field := range fields {
+		delete(c.fields, field)
+	}
+	return true
+}
+
+func (f *FallbackCache) Get(key string) (string, error) {
+	if f.Healthy() {
+		if val, err := f.primary.Get(key); !f.failed(err) {
+			return val, err
+		}
+	}
+	return f.secondary.Get(key)
+}
+
+func (f *FallbackCache) Set(key string, value string, expire time.Duration) error {
+	if f.Healthy() {
+		if err := f.primary.Set(key, value, expire); !f.failed(err) {
+			return err
+		}
+	}
+	f.touch(key)
+	return f.secondary.Set(key, value, expire)
+}
+
+// SetNX 常用于加锁，主缓存不可用时返回 ErrUnavailable，不降级
+func (f *FallbackCache) SetNX(key string, value string, expire time.Duration) (bool, error) {
+	if !f.Healthy() {
+		return false, ErrUnavailable
+	}
+	ok, err := f.primary.SetNX(key, value, expire)
+	f.failed(err)
+	return ok, err
+}
+
+func (f *FallbackCache) GetDel(key string) (string, error) {
+	if f.Healthy() {
+		if val, err := f.primary.GetDel(key); !f.failed(err) {
+			return val, err
+		}
+	}
+	f.touch(key)
+	return f.secondary.GetDel(key)
+}
+
+func (f *FallbackCache) Del(keys ...string) error {
+	if f.Healthy() {
+		if err := f.primary.Del(keys...); !f.failed(err) {
+			return err
+		}
+	}
+	for _, key := range keys {
+		if !f.forget(key) {
+			f.touch(key)
+		}
+	}
+	return f.secondary.Del(keys...)
+}
+
+func (f *FallbackCache) Expire(key string, expire time.Duration) error {
+	if f.Healthy() {
+		if err := f.primary.Expire(key, expire); !f.failed(err) {
+			return err
+		}
+	}
+	f.mu.Lock()
+	if c, ok := f.counters[key]; ok {
+		c.expire = expire
+	} else {
+		f.dirty[key] = struct{}{}
+	}
+	f.mu.Unlock()
+	return f.secondary.Expire(key, expire)
+}
+
+func (f *FallbackCache) Incr(key string, expire time.Duration) (int64, error) {
+	return f.IncrBy(key, 1, expire)
+}
+
+func (f *FallbackCache) IncrBy(key string, delta int64, expire time.Duration) (int64, error) {
+	if f.Healthy() {
+		if val, err := f.primary.IncrBy(key, delta, expire); !f.failed(err) {
+			return val, err
+		}
+	}
+	f.count(key, func(c *counter) {
+		c.incr += delta
+		if expire > 0 {
+			c.expire = expire
+		}
+	})
+	return f.secondary.IncrBy(key, delta, expire)
+}
+
+// CompareAndDelete 用于释放锁，主缓存不可用时返回 ErrUnavailable，不降级
+func (f *FallbackCache) CompareAndDelete(key, value string) (bool, error) {
+	if !f.Healthy() {
+		return false, ErrUnavailable
+	}
+	ok, err := f.primary.CompareAndDelete(key, value)
+	f.failed(err)
+	return ok, err
+}
+
+// CompareAndExpire 用于续期锁，主缓存不可用时返回 ErrUnavailable，不降级
+func (f *FallbackCache) CompareAndExpire(key, value string, expire time.Duration) (bool, error) {
+	if !f.Healthy() {
+		return false, ErrUnavailable
+	}
+	ok, err := f.primary.CompareAndExpire(key, value, expire)
+	f.failed(err)
+	return ok, err
+}
+
+func (f *FallbackCache) Scan(cursor uint64, match string, count int64) ([]string, uint64, error) {
+	if f.Healthy() {
+		if keys, next, err := f.primary.Scan(cursor, match, count); !f.failed(err) {
+			return keys, next, err
+		}
+	}
+	return f.secondary.Scan(cursor, match, count)
+}
+
+func (f *FallbackCache) HGet(key, field string) (string, error) {
+	if f.Healthy() {
+		if val, err := f.primary.HGet(key, field); !f.failed(err) {
+			return val, err
+		}
+	}
+	return f.secondary.HGet(key, field)
+}
+
+func (f *FallbackCache) HSet(key, field, value string) error {
+	if f.Healthy() {
+		if err := f.primary.HSet(key, field, value); !f.failed(err) {
+			return err
+		}
+	}
+	f.touch(key)
+	return f.secondary.HSet(key, field, value)
+}
+
+func (f *FallbackCache) HDel(key string, fields ...string) error {
+	if f.Healthy() {
+		if err := f.primary.HDel(key, fields...); !f.failed(err) {
+			return err
+		}
+	}
+	if !f.forget(key, fields...) {
+		f.touch(key)
+	}
+	return f.secondary.HDel(key, fields...)
+}
+
+func (f *FallbackCache) HGetAll(key string) (map[string]string, error) {
+	if f.Healthy() {
+		if val, err := f.primary.HGetAll(key); !f.failed(err) {
+			return val, err
+		}
+	}
+	return f.secondary.HGetAll(key)
+}
+
+func (f *FallbackCache) HVals(key string) ([]string, error) {
+	if f.Healthy() {
+		if val, err := f.primary.HVals(key); !f.failed(err) {
+			return val, err
+		}
+	}
+	return f.secondary.HVals(key)
+}
+
+func (f *FallbackCache) HIncrOrInit(key, field string, init int64) (int64, error) {
+	if f.Healthy() {
+		if val, err := f.primary.HIncrOrInit(key, field, init); !f.failed(err) {
+			return val, err
+		}
+	}
+	f.count(key, func(c *counter) {
+		if c.fields == nil {
+			c.fields = make(map[string]int64)
+		}
+		c.fields[field]++
+	})
+	return f.secondary.HIncrOrInit(key, field, init)
+}
+
+func (f *FallbackCache) HIncrBy(key, field string, delta int64) (int64, error) {
+	if f.Healthy() {
+		if val, err := f.primary.HIncrBy(key, field, delta); !f.failed(err) {
+			return val, err
+		}
+	}
+	f.count(key, func(c *counter) {
+		if c.fields == nil {
+			c.fields = make(map[string]int64)
+		}
+		c.fields[field] += delta
+	})
+	return f.secondary.HIncrBy(key, field, delta)
+}
+
+func (f *FallbackCache) SAdd(key string, members ...string) error {
+	if f.Healthy() {
+		if err := f.primary.SAdd(key, members...); !f.failed(err) {
+			return err
+		}
+	}
+	f.touch(key)
+	return f.secondary.SAdd(key, members...)
+}
+
+func (f *FallbackCache) SRandMemberN(key string, n int64) ([]string, error) {
+	if f.Healthy() {
+		if val, err := f.primary.SRandMemberN(key, n); !f.failed(err) {
+			return val, err
+		}
+	}
+	return f.secondary.SRandMemberN(key, n)
+}
+
+func (f *FallbackCache) PFAdd(key string, expire time.Duration, elements ...string) error {
+	if f.Healthy() {
+		if err := f.primary.PFAdd(key, expire, elements...); !f.failed(err) {
+			return err
+		}
+	}
+	f.count(key, func(c *counter) {
+		c.pf = true
+		if expire > 0 {
+			c.expire = expire
+		}
+	})
+	return f.secondary.PFAdd(key, expire, elements...)
+}
+
+func (f *FallbackCache) PFCount(keys ...string) (int64, error) {
+	if f.Healthy() {
+		if val, err := f.primary.PFCount(keys...); !f.failed(err) {
+			return val, err
+		}
+	}
+	return f.secondary.PFCount(keys...)
+}
+
+// Ping 只检查主缓存，降级状态下返回不可用，供健康检查使用
+func (f *FallbackCache) Ping() error {
+	return f.primary.Ping()
+}
+
+func (f *FallbackCache) Close() error {
+	return f.primary.Close()
+}
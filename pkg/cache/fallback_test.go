@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyCache 可以模拟断开的主缓存
+type flakyCache struct {
+	*MemoryCache
+	down atomic.Bool
+}
+
+func (c *flakyCache) err() error {
+	if c.down.Load() {
+		return ErrUnavailable
+	}
+	return nil
+}
+
+func (c *flakyCache) Get(key string) (string, error) {
+	if err := c.err(); err != nil {
+		return "", err
+	}
+	return c.MemoryCache.Get(key)
+}
+
+func (c *flakyCache) Set(key string, value string, expire time.Duration) error {
+	if err := c.err(); err != nil {
+		return err
+	}
+	return c.MemoryCache.Set(key, value, expire)
+}
+
+func (c *flakyCache) SetNX(key string, value string, expire time.Duration) (bool, error) {
+	if err := c.err(); err != nil {
+		return false, err
+	}
+	return c.MemoryCache.SetNX(key, value, expire)
+}
+
+func (c *flakyCache) Del(keys ...string) error {
+	if err := c.err(); err != nil {
+		return err
+	}
+	return c.MemoryCache.Del(keys...)
+}
+
+func (c *flakyCache) Incr(key string, expire time.Duration) (int64, error) {
+	return c.IncrBy(key, 1, expire)
+}
+
+func (c *flakyCache) IncrBy(key string, delta int64, expire time.Duration) (int64, error) {
+	if err := c.err(); err != nil {
+		return 0, err
+	}
+	return c.MemoryCache.IncrBy(key, delta, expire)
+}
+
+func (c *flakyCache) HGet(key, field string) (string, error) {
+	if err := c.err(); err != nil {
+		return "", err
+	}
+	return c.MemoryCache.HGet(key, field)
+}
+
+func (c *flakyCache) HSet(key, field, value string) error {
+	if err := c.err(); err != nil {
+		return err
+	}
+	return c.MemoryCache.HSet(key, field, value)
+}
+
+func (c *flakyCache) HIncrOrInit(key, field string, init int64) (int64, error) {
+	if err := c.err(); err != nil {
+		return 0, err
+	}
+	return c.MemoryCache.HIncrOrInit(key, field, init)
+}
+
+func (c *flakyCache) HIncrBy(key, field string, delta int64) (int64, error) {
+	if err := c.err(); err != nil {
+		return 0, err
+	}
+	return c.MemoryCache.HIncrBy(key, field, delta)
+}
+
+func (c *flakyCache) PFAdd(key string, expire time.Duration, elements ...string) error {
+	if err := c.err(); err != nil {
+		return err
+	}
+	return c.MemoryCache.PFAdd(key, expire, elements...)
+}
+
+func (c *flakyCache) CompareAndDelete(key, value string) (bool, error) {
+	if err := c.err(); err != nil {
+		return false, err
+	}
+	return c.MemoryCache.CompareAndDelete(key, value)
+}
+
+func (c *flakyCache) Ping() error {
+	return c.err()
+}
+
+// waitHealthy 等待降级的缓存切回主缓存
+func waitHealthy(t *testing.T, f *FallbackCache) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !f.Healthy() {
+		if time.Now().After(deadline) {
+			t.Fatal("缓存未恢复")
+		}
+		time.Sleep(time.Millisecond * 5)
+	}
+}
+
+func TestFallbackCacheRecover(t *testing.T) {
+	primary := &flakyCache{MemoryCache: NewMemoryCache(100)}
+	f := NewFallbackCache(primary, NewMemoryCache(100), time.Millisecond*10)
+
+	f.Set("cached", "old", 0)
+	f.HIncrOrInit("views", "1", 10)
+	f.Incr("pv", 0)
+	f.PFAdd("uv", 0, "a")
+
+	primary.down.Store(true)
+	if _, err := f.Get("cached"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("降级后应读取进程内缓存, err = %v", err)
+	}
+	if f.Healthy() {
+		t.Fatal("主缓存不可用时应降级")
+	}
+
+	f.Set("cached", "new", 0)
+	f.HIncrOrInit("views", "1", 8)
+	f.HIncrOrInit("views", "1", 8)
+	f.HIncrOrInit("views", "2", 3)
+	for i := 0; i < 3; i++ {
+		f.Incr("pv", 0)
+	}
+	f.PFAdd("uv", 0, "a", "b", "c")
+
+	// 降级期间读取后删除的计数器已经被使用，不再合并，也不删除主缓存中的值
+	f.HIncrOrInit("flushed", "1", 1)
+	f.Del("flushed")
+
+	primary.down.Store(false)
+	waitHealthy(t, f)
+
+	if _, err := primary.Get("cached"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("降级期间写过的键应从主缓存删除, err = %v", err)
+	}
+	for field, want := range map[string]string{"1": "12", "2": "3"} {
+		if val, _ := primary.HGet("views", field); val != want {
+			t.Errorf("views[%s] = %q, 期望 %q", field, val, want)
+		}
+	}
+	if val, _ := primary.Get("pv"); val != "4" {
+		t.Errorf("pv = %q, 期望 4", val)
+	}
+	if n, _ := primary.PFCount("uv"); n != 3 {
+		t.Errorf("uv = %d, 期望 3", n)
+	}
+	if _, err := f.secondary.HGet("views", "1"); !errors.Is(err, ErrNotFound) {
+		t.Error("恢复后应清空进程内缓存")
+	}
+}
+
+func TestFallbackCacheLockFailClosed(t *testing.T) {
+	primary := &flakyCache{MemoryCache: NewMemoryCache(100)}
+	f := NewFallbackCache(primary, NewMemoryCache(100), time.Hour)
+
+	primary.down.Store(true)
+	if ok, err := f.SetNX("lock", "1", time.Minute); ok || !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("主缓存不可用时加锁应失败, ok = %v, err = %v", ok, err)
+	}
+	if f.Healthy() {
+		t.Fatal("主缓存不可用时应降级")
+	}
+	if ok, err := f.SetNX("lock", "1", time.Minute); ok || !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("降级后加锁仍应失败, ok = %v, err = %v", ok, err)
+	}
+	if _, err := Acquire(f, "job", time.Minute); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("降级后不应取得租约, err = %v", err)
+	}
+	if _, err := f.CompareAndDelete("lock", "1"); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("降级后释放锁应失败, err = %v", err)
+	}
+}
+
+func TestLeaseCheckFallback(t *testing.T) {
+	primary := &flakyCache{MemoryCache: NewMemoryCache(100)}
+	f := NewFallbackCache(primary, NewMemoryCache(100), time.Hour)
+
+	lease, err := Acquire(f, "job", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lease.Check(); err != nil {
+		t.Fatalf("持有租约时检查应通过: %v", err)
+	}
+
+	primary.down.Store(true)
+	f.Get("other")
+	if err := lease.Check(); !errors.Is(err, ErrLeaseLost) {
+		t.Fatalf("降级后无法确认租约, err = %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrMissing 回源确认数据不存在，会被写入负缓存
+var ErrMissing = errors.New("cache: data missing")
+
+// entry 带软过期时间的缓存值
+type entry[T any] struct {
+	Data       T     `json:"data"`
+	Miss       bool  `json:"miss,omitempty"` // 负缓存标记，表示数据不存在
+	SoftExpire int64 `json:"softExpire"`     // 软过期时间（毫秒时间戳），过期后仍可返回旧值
+}
+
+// Loader 缓存回源加载器：
+// 并发的缓存未命中合并为一次回源（singleflight）；
+// 软过期后先返回旧值，只由一个协程在后台刷新；
+// 回源返回 ErrMissing 时写入短期的负缓存，避免不存在的数据反复穿透到数据库
+type Loader struct {
+	group singleflight.Group
+}
+
+// NewLoader 创建回源加载器
+func NewLoader() *Loader {
+	return &Loader{}
+}
+
+// Source 一个缓存值的读写与回源方式
+type Source[T any] struct {
+	Key     string                 // 合并回源请求使用的唯一标识
+	Get     func() (string, error) // 读取缓存
+	Set     func(val string) error // 写入缓存
+	SetMiss func(val string) error // 写入负缓存，为空时使用 Set；哈希表等不能单独过期的缓存需要写到独立的带过期时间的键
+	Load    func() (T, error)      // 回源，数据不存在时返回 ErrMissing
+	SoftTTL time.Duration          // 软过期时间
+	MissTTL time.Duration          // 负缓存时间，为0时不缓存不存在的数据
+}
+
+// Fetch 获取缓存值，未命中或负缓存过期时同步回源，软过期时返回旧值并在后台刷新
+func Fetch[T any](l *Loader, src Source[T]) (T, error) {
+	if str, err := src.Get(); err == nil && str != "" {
+		var e entry[T]
+		if json.Unmarshal([]byte(str), &e) == nil && e.SoftExpire > 0 {
+			fresh := time.Now().UnixMilli() < e.SoftExpire
+			if e.Miss && fresh {
+				return e.Data, ErrMissing
+			}
+			if !e.Miss {
+				if !fresh {
+					l.group.DoChan(src.Key, func() (any, error) {
+						return load(src)
+					})
+				}
+				return e.Data, nil
+			}
+		}
+	}
+
+	return Refresh(l, src)
+}
+
+// Refresh 立即回源并写入缓存，同一 Key 的并发回源只执行一次
+func Refresh[T any](l *Loader, src Source[T]) (T, error) {
+	val, err, _ := l.group.Do(src.Key, func() (any, error) {
+		return load(src)
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return val.(T), nil
+}
+
+// load 回源并写入缓存，写缓存失败只记录日志，不影响返回结果
+func load[T any](src Source[T]) (any, error) {
+	data, err := src.Load()
+
+	e := entry[T]{Data: data}
+	switch {
+	case errors.Is(err, ErrMissing):
+		if src.MissTTL <= 0 {
+			return data, err
+		}
+		e.Miss = true
+		e.SoftExpire = time.Now().Add(src.MissTTL).UnixMilli()
+	case err != nil:
+		return data, err
+	default:
+		e.SoftExpire = time.Now().Add(src.SoftTTL).UnixMilli()
+	}
+
+	set := src.Set
+	if e.Miss && src.SetMiss != nil {
+		set = src.SetMiss
+	}
+	if str, merr := json.Marshal(e); merr == nil {
+		if serr := set(string(str)); serr != nil {
+			zap.L().Warn("写入缓存失败", zap.String("key", src.Key), zap.Error(serr))
+		}
+	}
+	return data, err
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoaderCoalesceAndNegative(t *testing.T) {
+	var (
+		m      = NewMemoryCache(10)
+		l      = NewLoader()
+		loads  atomic.Int32
+		exists atomic.Bool
+	)
+
+	src := Source[string]{
+		Key: "blog:1",
+		Get: func() (string, error) { return m.Get("blog:1") },
+		Set: func(val string) error { return m.Set("blog:1", val, 0) },
+		Load: func() (string, error) {
+			loads.Add(1)
+			time.Sleep(time.Millisecond * 20)
+			if !exists.Load() {
+				return "", ErrMissing
+			}
+			return "hello", nil
+		},
+		SoftTTL: time.Millisecond * 30,
+		MissTTL: time.Hour,
+	}
+
+	// 并发未命中只回源一次，并写入负缓存
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Fetch(l, src); !errors.Is(err, ErrMissing) {
+				t.Errorf("Fetch err = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if _, err := Fetch(l, src); !errors.Is(err, ErrMissing) || loads.Load() != 1 {
+		t.Fatalf("负缓存未生效, err = %v, loads = %d", err, loads.Load())
+	}
+
+	// 软过期后返回旧值，并只在后台刷新一次
+	exists.Store(true)
+	m.Del("blog:1")
+	if val, err := Fetch(l, src); err != nil || val != "hello" {
+		t.Fatalf("Fetch = %q, %v", val, err)
+	}
+	time.Sleep(time.Millisecond * 40)
+
+	before := loads.Load()
+	for i := 0; i < 5; i++ {
+		if val, err := Fetch(l, src); err != nil || val != "hello" {
+			t.Fatalf("软过期应返回旧值, got %q, %v", val, err)
+		}
+	}
+	time.Sleep(time.Millisecond * 40)
+	if got := loads.Load() - before; got != 1 {
+		t.Fatalf("后台刷新次数 = %d, want 1", got)
+	}
+}
+
+func TestLoaderSetMiss(t *testing.T) {
+	var (
+		m      = NewMemoryCache(10)
+		l      = NewLoader()
+		exists bool
+	)
+
+	src := Source[string]{
+		Key: "blog:2",
+		Get: func() (string, error) {
+			if val, err := m.HGet("blogs", "2"); err == nil {
+				return val, nil
+			}
+			return m.Get("miss:2")
+		},
+		Set:     func(val string) error { return m.HSet("blogs", "2", val) },
+		SetMiss: func(val string) error { return m.Set("miss:2", val, time.Hour) },
+		Load: func() (string, error) {
+			if !exists {
+				return "", ErrMissing
+			}
+			return "hello", nil
+		},
+		SoftTTL: time.Hour,
+		MissTTL: time.Hour,
+	}
+
+	// 负缓存写入独立的键，不进入哈希表
+	if _, err := Fetch(l, src); !errors.Is(err, ErrMissing) {
+		t.Fatalf("Fetch err = %v", err)
+	}
+	if _, err := m.HGet("blogs", "2"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("负缓存不应写入哈希表, err = %v", err)
+	}
+	if _, err := m.Get("miss:2"); err != nil {
+		t.Fatalf("负缓存未写入, err = %v", err)
+	}
+
+	exists = true
+	m.Del("miss:2")
+	if val, err := Fetch(l, src); err != nil || val != "hello" {
+		t.Fatalf("Fetch = %q, %v", val, err)
+	}
+	if _, err := m.HGet("blogs", "2"); err != nil {
+		t.Fatalf("命中结果应写入哈希表, err = %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	lockKey  = "LOCK:"       // 锁的key，值为持有者的令牌
+	fenceKey = "LOCK_FENCE:" // 锁的令牌计数器
+)
+
+var (
+	// ErrLocked 锁已被其他持有者占用
+	ErrLocked = errors.New("cache: locked")
+	// ErrLeaseLost 租约已过期或被其他持有者取得
+	ErrLeaseLost = errors.New("cache: lease lost")
+)
+
+// Lease 带过期时间的分布式锁租约
+// 每次加锁都会取得一个单调递增的令牌（fencing token），锁的值就是这个令牌，
+// 写数据库时由写入方比较令牌拒绝过期持有者的写入（见 job.Transaction），
+// 其他有副作用的操作前调用 Check 尽量确认租约仍然有效
+type Lease struct {
+	cache Cache
+	name  string
+	token int64
+	ttl   time.Duration
+}
+
+// Acquire 尝试加锁，锁被占用时返回 ErrLocked
+func Acquire(c Cache, name string, ttl time.Duration) (*Lease, error) {
+	token, err := c.Incr(fenceKey+name, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := c.SetNX(lockKey+name, strconv.FormatInt(token, 10), ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrLocked
+	}
+
+	return &Lease{cache: c, name: name, token: token, ttl: ttl}, nil
+}
+
+// Token 本次加锁的令牌，越晚取得的租约令牌越大
+func (l *Lease) Token() int64 {
+	return l.token
+}
+
+// Refresh 续期，租约已失效时返回 ErrLeaseLost
+func (l *Lease) Refresh() error {
+	ok, err := l.cache.CompareAndExpire(lockKey+l.name, l.value(), l.ttl)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Name 锁的名称
+func (l *Lease) Name() string {
+	return l.name
+}
+
+// Check 确认租约仍由自己持有，缓存处于降级状态时无法确认，按租约已失效处理
+func (l *Lease) Check() error {
+	if h, ok := l.cache.(interface{ Healthy() bool }); ok && !h.Healthy() {
+		return ErrLeaseLost
+	}
+	val, err := l.cache.Get(lockKey + l.name)
+	if errors.Is(err, ErrNotFound) || (err == nil && val != l.value()) {
+		return ErrLeaseLost
+	}
+	return err
+}
+
+// Release 释放锁，只会删除自己持有的锁
+func (l *Lease) Release() error {
+	_, err := l.cache.CompareAndDelete(lockKey+l.name, l.value())
+	return err
+}
+
+func (l *Lease) value() string {
+	return strconv.FormatInt(l.token, 10)
+}
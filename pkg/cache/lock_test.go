@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLeaseFencing(t *testing.T) {
+	m := NewMemoryCache(10)
+
+	first, err := Acquire(m, "job", time.Millisecond*20)
+	if err != nil {
+		t.Fatalf("Acquire err = %v", err)
+	}
+	if _, err := Acquire(m, "job", time.Millisecond*20); !errors.Is(err, ErrLocked) {
+		t.Fatalf("锁被占用时应返回 ErrLocked, err = %v", err)
+	}
+
+	// 租约过期后被其他持有者取得，旧租约不能续期也不能释放新锁
+	time.Sleep(time.Millisecond * 30)
+	second, err := Acquire(m, "job", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire err = %v", err)
+	}
+	if second.Token() <= first.Token() {
+		t.Fatalf("令牌应递增, first = %d, second = %d", first.Token(), second.Token())
+	}
+	if err := first.Check(); !errors.Is(err, ErrLeaseLost) {
+		t.Fatalf("旧租约 Check err = %v", err)
+	}
+	if err := first.Refresh(); !errors.Is(err, ErrLeaseLost) {
+		t.Fatalf("旧租约 Refresh err = %v", err)
+	}
+	first.Release()
+	if err := second.Check(); err != nil {
+		t.Fatalf("旧租约释放不应影响新锁, err = %v", err)
+	}
+
+	second.Release()
+	if _, err := Acquire(m, "job", time.Minute); err != nil {
+		t.Fatalf("释放后应能重新加锁, err = %v", err)
+	}
+}
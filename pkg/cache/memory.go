@@ -0,0 +1,488 @@
+package cache
+
+import (
+	"container/list"
+	"math/rand"
+	"path"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MemoryCache 进程内缓存，按 LRU 淘汰并支持过期时间，用于无 Redis 的单机环境和 Redis 故障时降级
+type MemoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	lru      *list.List
+}
+
+// memoryItem 缓存项，value 为 string、map[string]string 或 map[string]struct{}
+type memoryItem struct {
+	key      string
+	value    any
+	expireAt time.Time
+}
+
+func (i *memoryItem) expired(now time.Time) bool {
+	return !i.expireAt.IsZero() && now.After(i.expireAt)
+}
+
+// NewMemoryCache 创建进程内缓存，capacity 为最多保存的键数量
+func NewMemoryCache(capacity int) *MemoryCache {
+	if capacity <= 0 {
+		capacity = 10000
+	}
+	return &MemoryCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// get 获取未过期的缓存项并标记为最近使用，调用方需持有锁
+func (m *MemoryCache) get(key string) *memoryItem {
+	elem, ok := m.items[key]
+	if !ok {
+		return nil
+	}
+	item := elem.Value.(*memoryItem)
+	if item.expired(time.Now()) {
+		m.remove(elem)
+		return nil
+	}
+	m.lru.MoveToFront(elem)
+	return item
+}
+
+// put 写入缓存项，超出容量时淘汰最久未使用的键，调用方需持有锁
+func (m *MemoryCache) put(key string, value any, expireAt time.Time) *memoryItem {
+	if elem, ok := m.items[key]; ok {
+		item := elem.Value.(*memoryItem)
+		item.value = value
+		item.expireAt = expireAt
+		m.lru.MoveToFront(elem)
+		return item
+	}
+
+	item := &memoryItem{key: key, value: value, expireAt: expireAt}
+	m.items[key] = m.lru.PushFront(item)
+
+	for m.lru.Len() > m.capacity {
+		m.remove(m.lru.Back())
+	}
+	return item
+}
+
+func (m *MemoryCache) remove(elem *list.Element) {
+	m.lru.Remove(elem)
+	delete(m.items, elem.Value.(*memoryItem).key)
+}
+
+// hash 获取哈希，create 为 true 时不存在则创建，调用方需持有锁
+func (m *MemoryCache) hash(key string, create bool) (map[string]string, error) {
+	item := m.get(key)
+	if item == nil {
+		if !create {
+			return nil, nil
+		}
+		h := make(map[string]string)
+		m.put(key, h, time.Time{})
+		return h, nil
+	}
+	h, ok := item.value.(map[string]string)
+	if !ok {
+		return nil, ErrWrongType
+	}
+	return h, nil
+}
+
+// set 获取集合，create 为 true 时不存在则创建，调用方需持有锁
+func (m *MemoryCache) set(key string, create bool) (map[string]struct{}, error) {
+	item := m.get(key)
+	if item == nil {
+		if !create {
+			return nil, nil
+		}
+		s := make(map[string]struct{})
+		m.put(key, s, time.Time{})
+		return s, nil
+	}
+	s, ok := item.value.(map[string]struct{})
+	if !ok {
+		return nil, ErrWrongType
+	}
+	return s, nil
+}
+
+func (m *MemoryCache) Get(key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item := m.get(key)
+	if item == nil {
+		return "", ErrNotFound
+	}
+	val, ok := item.value.(string)
+	if !ok {
+		return "", ErrWrongType
+	}
+	return val, nil
+}
+
+func (m *MemoryCache) Set(key string, value string, expire time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.put(key, value, expireAt(expire))
+	return nil
+}
+
+func (m *MemoryCache) SetNX(key string, value string, expire time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.get(key) != nil {
+		return false, nil
+	}
+	m.put(key, value, expireAt(expire))
+	return true, nil
+}
+
+func (m *MemoryCache) GetDel(key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item := m.get(key)
+	if item == nil {
+		return "", ErrNotFound
+	}
+	val, ok := item.value.(string)
+	if !ok {
+		return "", ErrWrongType
+	}
+	m.remove(m.items[key])
+	return val, nil
+}
+
+func (m *MemoryCache) Del(keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := m.items[key]; ok {
+			m.remove(elem)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryCache) Expire(key string, expire time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if item := m.get(key); item != nil {
+		item.expireAt = expireAt(expire)
+	}
+	return nil
+}
+
+func (m *MemoryCache) Incr(key string, expire time.Duration) (int64, error) {
+	return m.IncrBy(key, 1, expire)
+}
+
+func (m *MemoryCache) IncrBy(key string, delta int64, expire time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var count int64
+	var deadline time.Time
+
+	if item := m.get(key); item != nil {
+		str, ok := item.value.(string)
+		if !ok {
+			return 0, ErrWrongType
+		}
+		n, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		count = n
+		deadline = item.expireAt
+	}
+
+	if expire > 0 {
+		deadline = expireAt(expire)
+	}
+
+	count += delta
+	m.put(key, strconv.FormatInt(count, 10), deadline)
+	return count, nil
+}
+
+func (m *MemoryCache) CompareAndDelete(key, value string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item := m.get(key)
+	if item == nil || item.value != value {
+		return false, nil
+	}
+	m.remove(m.items[key])
+	return true, nil
+}
+
+func (m *MemoryCache) CompareAndExpire(key, value string, expire time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item := m.get(key)
+	if item == nil || item.value != value {
+		return false, nil
+	}
+	item.expireAt = expireAt(expire)
+	return true, nil
+}
+
+// Scan 按键名排序后以下标作为游标，遍历期间新增的键可能被跳过，与 Redis SCAN 的保证一致
+func (m *MemoryCache) Scan(cursor uint64, match string, count int64) ([]string, uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if count <= 0 {
+		count = 10
+	}
+
+	all := make([]string, 0, len(m.items))
+	for key := range m.items {
+		all = append(all, key)
+	}
+	slices.Sort(all)
+
+	now := time.Now()
+	keys := make([]string, 0)
+	end := min(cursor+uint64(count), uint64(len(all)))
+	for i := cursor; i < end; i++ {
+		if m.items[all[i]].Value.(*memoryItem).expired(now) {
+			continue
+		}
+		if ok, _ := path.Match(match, all[i]); match == "" || ok {
+			keys = append(keys, all[i])
+		}
+	}
+
+	if end >= uint64(len(all)) {
+		end = 0
+	}
+	return keys, end, nil
+}
+
+func (m *MemoryCache) HGet(key, field string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, err := m.hash(key, false)
+	if err != nil {
+		return "", err
+	}
+	val, ok := h[field]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return val, nil
+}
+
+func (m *MemoryCache) HSet(key, field, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, err := m.hash(key, true)
+	if err != nil {
+		return err
+	}
+	h[field] = value
+	return nil
+}
+
+func (m *MemoryCache) HDel(key string, fields ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, err := m.hash(key, false)
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		delete(h, field)
+	}
+	if h != nil && len(h) == 0 {
+		m.remove(m.items[key])
+	}
+	return nil
+}
+
+func (m *MemoryCache) HGetAll(key string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, err := m.hash(key, false)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string, len(h))
+	for field, val := range h {
+		result[field] = val
+	}
+	return result, nil
+}
+
+func (m *MemoryCache) HVals(key string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, err := m.hash(key, false)
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]string, 0, len(h))
+	for _, val := range h {
+		vals = append(vals, val)
+	}
+	return vals, nil
+}
+
+func (m *MemoryCache) HIncrOrInit(key, field string, init int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, err := m.hash(key, true)
+	if err != nil {
+		return 0, err
+	}
+
+	count := init
+	if val, ok := h[field]; ok {
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		count = n + 1
+	}
+	h[field] = strconv.FormatInt(count, 10)
+	return count, nil
+}
+
+func (m *MemoryCache) SAdd(key string, members ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, err := m.set(key, true)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		s[member] = struct{}{}
+	}
+	return nil
+}
+
+func (m *MemoryCache) SRandMemberN(key string, n int64) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, err := m.set(key, false)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]string, 0, len(s))
+	for member := range s {
+		members = append(members, member)
+	}
+	rand.Shuffle(len(members), func(i, j int) { members[i], members[j] = members[j], members[i] })
+	if int64(len(members)) > n {
+		members = members[:n]
+	}
+	return members, nil
+}
+
+func (m *MemoryCache) HIncrBy(key, field string, delta int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, err := m.hash(key, true)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if val, ok := h[field]; ok {
+		if count, err = strconv.ParseInt(val, 10, 64); err != nil {
+			return 0, err
+		}
+	}
+	count += delta
+	h[field] = strconv.FormatInt(count, 10)
+	return count, nil
+}
+
+// PFAdd 进程内缓存使用集合精确计数
+func (m *MemoryCache) PFAdd(key string, expire time.Duration, elements ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, err := m.set(key, true)
+	if err != nil {
+		return err
+	}
+	for _, element := range elements {
+		s[element] = struct{}{}
+	}
+	if expire > 0 {
+		m.get(key).expireAt = expireAt(expire)
+	}
+	return nil
+}
+
+func (m *MemoryCache) PFCount(keys ...string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	union := make(map[string]struct{})
+	for _, key := range keys {
+		s, err := m.set(key, false)
+		if err != nil {
+			return 0, err
+		}
+		for element := range s {
+			union[element] = struct{}{}
+		}
+	}
+	return int64(len(union)), nil
+}
+
+// pfMembers 基数统计中的所有元素，用于合并到其他缓存
+func (m *MemoryCache) pfMembers(key string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, _ := m.set(key, false)
+	members := make([]string, 0, len(s))
+	for member := range s {
+		members = append(members, member)
+	}
+	return members
+}
+
+// Flush 清空所有缓存
+func (m *MemoryCache) Flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.items = make(map[string]*list.Element)
+	m.lru.Init()
+}
+
+func (m *MemoryCache) Ping() error { return nil }
+
+func (m *MemoryCache) Close() error { return nil }
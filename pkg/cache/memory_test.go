@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheExpireAndEvict(t *testing.T) {
+	m := NewMemoryCache(2)
+
+	m.Set("a", "1", 0)
+	m.Set("b", "2", time.Millisecond*20)
+
+	// 访问 a 使 b 成为最久未使用的键
+	if val, err := m.Get("a"); err != nil || val != "1" {
+		t.Fatalf("Get(a) = %q, %v", val, err)
+	}
+
+	m.Set("c", "3", 0)
+	if _, err := m.Get("b"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("b 应该被淘汰, err = %v", err)
+	}
+
+	m.Set("d", "4", time.Millisecond*10)
+	time.Sleep(time.Millisecond * 20)
+	if _, err := m.Get("d"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("d 应该已过期, err = %v", err)
+	}
+}
+
+func TestMemoryCacheTypes(t *testing.T) {
+	m := NewMemoryCache(0)
+
+	if n, _ := m.Incr("count", time.Minute); n != 1 {
+		t.Fatalf("Incr = %d", n)
+	}
+	if n, _ := m.Incr("count", 0); n != 2 {
+		t.Fatalf("Incr = %d", n)
+	}
+
+	if n, _ := m.HIncrOrInit("eye", "1", 10); n != 10 {
+		t.Fatalf("HIncrOrInit 首次应返回初始值, got %d", n)
+	}
+	if n, _ := m.HIncrOrInit("eye", "1", 10); n != 11 {
+		t.Fatalf("HIncrOrInit = %d", n)
+	}
+
+	if _, err := m.Get("eye"); !errors.Is(err, ErrWrongType) {
+		t.Fatalf("对哈希执行 Get 应返回 ErrWrongType, err = %v", err)
+	}
+
+	m.SAdd("tags", "a", "b", "c")
+	if members, _ := m.SRandMemberN("tags", 2); len(members) != 2 {
+		t.Fatalf("SRandMemberN = %v", members)
+	}
+
+	if ok, _ := m.SetNX("lock", "1", time.Minute); !ok {
+		t.Fatal("SetNX 首次应成功")
+	}
+	if ok, _ := m.SetNX("lock", "2", time.Minute); ok {
+		t.Fatal("SetNX 重复设置应失败")
+	}
+
+	var keys []string
+	var cursor uint64
+	for {
+		batch, next, _ := m.Scan(cursor, "*o*", 1)
+		keys = append(keys, batch...)
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+	if len(keys) != 2 || keys[0] != "count" || keys[1] != "lock" {
+		t.Fatalf("Scan = %v", keys)
+	}
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// namespaceVersionKey 命名空间当前版本号的key
+const namespaceVersionKey = "NS_VERSION:"
+
+// Namespace 带版本号的命名空间，键名中包含当前版本号，
+// 失效整个命名空间只需更换版本号，旧版本的键随过期时间自然淘汰，无需 KEYS 扫描
+type Namespace struct {
+	cache Cache
+	name  string
+}
+
+// NewNamespace 创建命名空间
+func NewNamespace(c Cache, name string) *Namespace {
+	return &Namespace{cache: c, name: name}
+}
+
+// Key 返回当前版本下的完整键名
+func (n *Namespace) Key(suffix string) (string, error) {
+	version, err := n.version()
+	if err != nil {
+		return "", err
+	}
+	return n.name + ":" + version + ":" + suffix, nil
+}
+
+// Invalidate 更换版本号，使命名空间下的所有键失效
+// 版本号使用时间戳而不是自增计数，Redis 故障恢复后版本号被清理也不会与旧键冲突
+func (n *Namespace) Invalidate() error {
+	return n.cache.Set(namespaceVersionKey+n.name, newVersion(), 0)
+}
+
+// version 获取当前版本号，不存在时初始化
+func (n *Namespace) version() (string, error) {
+	key := namespaceVersionKey + n.name
+
+	version, err := n.cache.Get(key)
+	if err == nil {
+		return version, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return "", err
+	}
+
+	version = newVersion()
+	if ok, err := n.cache.SetNX(key, version, 0); err != nil || ok {
+		return version, err
+	}
+	// 并发初始化时以先写入的为准
+	return n.cache.Get(key)
+}
+
+func newVersion() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 36)
+}
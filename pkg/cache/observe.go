@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"blog/pkg/metrics"
+	"errors"
+)
+
+// observed 统计读取命中率的缓存，name 为指标中的缓存名称
+type observed struct {
+	Cache
+	name string
+}
+
+// Observe 包装缓存，记录 Get、HGet 的命中与未命中次数
+func Observe(c Cache, name string) Cache {
+	return &observed{Cache: c, name: name}
+}
+
+func (o *observed) Get(key string) (string, error) {
+	val, err := o.Cache.Get(key)
+	o.record(err)
+	return val, err
+}
+
+func (o *observed) HGet(key, field string) (string, error) {
+	val, err := o.Cache.HGet(key, field)
+	o.record(err)
+	return val, err
+}
+
+func (o *observed) record(err error) {
+	switch {
+	case err == nil:
+		metrics.CacheRequests.WithLabelValues(o.name, "hit").Inc()
+	case errors.Is(err, ErrNotFound):
+		metrics.CacheRequests.WithLabelValues(o.name, "miss").Inc()
+	}
+}
@@ -0,0 +1,240 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// RedisCache 基于 Redis 的缓存实现
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache 创建 Redis 缓存
+func NewRedisCache(client *redis.Client) *RedisCache {
+	return &RedisCache{client: client}
+}
+
+// hIncrOrInitScript 字段第一次自增时设置为初始值
+const hIncrOrInitScript = `
+	local count = redis.call("HINCRBY", KEYS[1], ARGV[1], 1)
+	if count == 1 then
+		redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+		return tonumber(ARGV[2])
+	end
+	return count
+`
+
+// compareAndDeleteScript 值匹配时删除键
+const compareAndDeleteScript = `
+	if redis.call("GET", KEYS[1]) == ARGV[1] then
+		return redis.call("DEL", KEYS[1])
+	end
+	return 0
+`
+
+// compareAndExpireScript 值匹配时刷新过期时间
+const compareAndExpireScript = `
+	if redis.call("GET", KEYS[1]) == ARGV[1] then
+		return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	end
+	return 0
+`
+
+// wrap 统一转换 Redis 错误
+func wrap(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case err == redis.Nil:
+		return ErrNotFound
+	case isUnavailable(err):
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	case strings.HasPrefix(err.Error(), "WRONGTYPE"):
+		return fmt.Errorf("%w: %v", ErrWrongType, err)
+	default:
+		return err
+	}
+}
+
+// isUnavailable 判断是否为连接类错误
+func isUnavailable(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "connection pool timeout") ||
+		strings.Contains(msg, "client is closed") ||
+		strings.Contains(msg, "connection refused") ||
+		strings.Contains(msg, "LOADING")
+}
+
+func (r *RedisCache) Get(key string) (string, error) {
+	val, err := r.client.Get(key).Result()
+	return val, wrap(err)
+}
+
+func (r *RedisCache) Set(key string, value string, expire time.Duration) error {
+	return wrap(r.client.Set(key, value, max(expire, 0)).Err())
+}
+
+func (r *RedisCache) SetNX(key string, value string, expire time.Duration) (bool, error) {
+	ok, err := r.client.SetNX(key, value, max(expire, 0)).Result()
+	return ok, wrap(err)
+}
+
+func (r *RedisCache) GetDel(key string) (string, error) {
+	pipe := r.client.TxPipeline()
+	get := pipe.Get(key)
+	pipe.Del(key)
+	if _, err := pipe.Exec(); err != nil {
+		return "", wrap(err)
+	}
+	return get.Val(), nil
+}
+
+func (r *RedisCache) Del(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return wrap(r.client.Del(keys...).Err())
+}
+
+func (r *RedisCache) Expire(key string, expire time.Duration) error {
+	if expire <= 0 {
+		return wrap(r.client.Persist(key).Err())
+	}
+	return wrap(r.client.Expire(key, expire).Err())
+}
+
+func (r *RedisCache) Incr(key string, expire time.Duration) (int64, error) {
+	if expire <= 0 {
+		val, err := r.client.Incr(key).Result()
+		return val, wrap(err)
+	}
+	pipe := r.client.TxPipeline()
+	incr := pipe.Incr(key)
+	pipe.Expire(key, expire)
+	if _, err := pipe.Exec(); err != nil {
+		return 0, wrap(err)
+	}
+	return incr.Val(), nil
+}
+
+func (r *RedisCache) IncrBy(key string, delta int64, expire time.Duration) (int64, error) {
+	if expire <= 0 {
+		val, err := r.client.IncrBy(key, delta).Result()
+		return val, wrap(err)
+	}
+	pipe := r.client.TxPipeline()
+	incr := pipe.IncrBy(key, delta)
+	pipe.Expire(key, expire)
+	if _, err := pipe.Exec(); err != nil {
+		return 0, wrap(err)
+	}
+	return incr.Val(), nil
+}
+
+func (r *RedisCache) Scan(cursor uint64, match string, count int64) ([]string, uint64, error) {
+	keys, next, err := r.client.Scan(cursor, match, count).Result()
+	return keys, next, wrap(err)
+}
+
+func (r *RedisCache) HGet(key, field string) (string, error) {
+	val, err := r.client.HGet(key, field).Result()
+	return val, wrap(err)
+}
+
+func (r *RedisCache) HSet(key, field, value string) error {
+	return wrap(r.client.HSet(key, field, value).Err())
+}
+
+func (r *RedisCache) HDel(key string, fields ...string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return wrap(r.client.HDel(key, fields...).Err())
+}
+
+func (r *RedisCache) HGetAll(key string) (map[string]string, error) {
+	val, err := r.client.HGetAll(key).Result()
+	return val, wrap(err)
+}
+
+func (r *RedisCache) HVals(key string) ([]string, error) {
+	val, err := r.client.HVals(key).Result()
+	return val, wrap(err)
+}
+
+func (r *RedisCache) HIncrOrInit(key, field string, init int64) (int64, error) {
+	val, err := r.client.Eval(hIncrOrInitScript, []string{key}, field, init).Int64()
+	return val, wrap(err)
+}
+
+func (r *RedisCache) HIncrBy(key, field string, delta int64) (int64, error) {
+	val, err := r.client.HIncrBy(key, field, delta).Result()
+	return val, wrap(err)
+}
+
+func (r *RedisCache) CompareAndDelete(key, value string) (bool, error) {
+	n, err := r.client.Eval(compareAndDeleteScript, []string{key}, value).Int64()
+	return n == 1, wrap(err)
+}
+
+func (r *RedisCache) CompareAndExpire(key, value string, expire time.Duration) (bool, error) {
+	n, err := r.client.Eval(compareAndExpireScript, []string{key}, value, expire.Milliseconds()).Int64()
+	return n == 1, wrap(err)
+}
+
+func (r *RedisCache) SAdd(key string, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+	values := make([]interface{}, len(members))
+	for i, member := range members {
+		values[i] = member
+	}
+	return wrap(r.client.SAdd(key, values...).Err())
+}
+
+func (r *RedisCache) SRandMemberN(key string, n int64) ([]string, error) {
+	val, err := r.client.SRandMemberN(key, n).Result()
+	return val, wrap(err)
+}
+
+func (r *RedisCache) PFAdd(key string, expire time.Duration, elements ...string) error {
+	if len(elements) == 0 {
+		return nil
+	}
+	values := make([]interface{}, len(elements))
+	for i, element := range elements {
+		values[i] = element
+	}
+	pipe := r.client.TxPipeline()
+	pipe.PFAdd(key, values...)
+	if expire > 0 {
+		pipe.Expire(key, expire)
+	}
+	_, err := pipe.Exec()
+	return wrap(err)
+}
+
+func (r *RedisCache) PFCount(keys ...string) (int64, error) {
+	val, err := r.client.PFCount(keys...).Result()
+	return val, wrap(err)
+}
+
+func (r *RedisCache) Ping() error {
+	return wrap(r.client.Ping().Err())
+}
+
+func (r *RedisCache) Close() error {
+	return r.client.Close()
+}
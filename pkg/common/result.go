@@ -25,6 +25,9 @@ const (
 	TokenExpireError Code = 1004        //Token错误
 	LoginFail        Code = 1005        //登录失败
 	LockBlog         Code = 1007        //博客加锁
+	AccountLocked    Code = 1008        //账号已被临时锁定
+	IpLocked         Code = 1009        //IP已被临时锁定
+	EmailUnverified  Code = 1010        //邮箱未验证
 )
 
 var resultMaps = map[Code]string{
@@ -41,6 +44,9 @@ var resultMaps = map[Code]string{
 	Unauthorized:     "认证失败",
 	LoginFail:        "登录失败",
 	LockBlog:         "博客加锁",
+	AccountLocked:    "登录失败次数过多，账号已被临时锁定",
+	IpLocked:         "登录失败次数过多，IP已被临时锁定",
+	EmailUnverified:  "请先验证邮箱",
 }
 
 func (c Code) DoData(data interface{}) R {
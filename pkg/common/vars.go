@@ -5,28 +5,141 @@ import (
 	"time"
 )
 
-const EnableEmailCode = false
-
 const PageRequest = "page_request"
 
 const AdminRequest = "admin_request"
 
 const (
-	PageInfoPrefixKey = "PAGE_INFO" //缓存博客列表的key
+	PageInfoPrefixKey = "PAGE_INFO" //缓存博客列表的命名空间
 )
 
 // 用户缓存键集合
 const (
-	UserTokenKey       = "USER_TOKEN:"    //缓存用户Token的Key
-	EmailCodeKey       = "EMAIL_CODE:"    //缓存注册邮箱验证码的key
-	EmailCodeKeyExpire = time.Minute      //邮箱验证码过期实际
-	UserInfoKey        = "USER_INFO:"     //缓存用户信息的key
-	UserInfoKeyExpire  = time.Minute * 30 //用户信息过期时间
+	UserTokenKey        = "USER_TOKEN:"      //缓存用户Token的Key
+	EmailCodeKey        = "EMAIL_CODE:"      //缓存注册邮箱验证码的key
+	EmailCodeExpire     = time.Minute * 10   //邮箱验证码默认过期时间
+	EmailResendKey      = "EMAIL_RESEND:"    //邮箱验证码发送冷却的key
+	EmailResendInterval = time.Minute        //邮箱验证码默认发送间隔
+	EmailCodeFailKey    = "EMAIL_CODE_FAIL:" //邮箱验证码输错次数的key
+	EmailCodeMaxAttempt = 5                  //验证码输错多少次后失效
+	UserInfoKey         = "USER_INFO:"       //缓存用户信息的key
+	UserInfoKeyExpire   = time.Minute * 30   //用户信息过期时间
+)
+
+// 登录保护缓存键集合
+const (
+	LoginFailUserKey    = "LOGIN_FAIL_USER:" //账号登录失败次数的key
+	LoginFailIpKey      = "LOGIN_FAIL_IP:"   //IP登录失败次数的key
+	LoginFailExpire     = time.Hour * 24     //登录失败次数的统计周期
+	LoginLockKey        = "LOGIN_LOCK:"      //登录锁定信息的key，后接 类型:目标，锁定到期后自动删除
+	LoginLockUser       = "user"             //按账号锁定
+	LoginLockIp         = "ip"               //按IP锁定
+	LoginUserMaxAttempt = 5                  //账号连续失败多少次后锁定
+	LoginIpMaxAttempt   = 20                 //IP连续失败多少次后锁定
+	LoginLockBase       = time.Minute        //首次锁定时长，之后每多失败一次翻倍
+	LoginLockMax        = time.Hour * 24     //最长锁定时长
+)
+
+// 个人访问令牌
+const (
+	AccessTokenPrefix        = "blog_pat_"      //个人访问令牌前缀
+	AccessTokenKey           = "ACCESS_TOKEN:"  //缓存个人访问令牌的key
+	AccessTokenKeyExpire     = time.Minute * 10 //个人访问令牌缓存过期时间
+	AccessTokenTouchInterval = time.Minute      //最后使用时间的更新间隔
+	AccessTokenMaxCount      = 20               //每个用户最多拥有的令牌数量
+	AccessTokenAllScope      = "*"              //全部权限
+)
+
+// AccessTokenScopes 个人访问令牌可选的权限范围，按路由分组划分读写
+var AccessTokenScopes = []string{
+	AccessTokenAllScope,
+	"blog:read", "blog:write",
+	"file:read", "file:write",
+	"tag:read", "tag:write",
+	"category:read", "category:write",
+	"topic:read", "topic:write",
+	"system:read", "system:write",
+	"database:read", "database:write",
+	"user:read", "user:write",
+	"job:read", "job:write",
+	"backup:read", "backup:write",
+}
+
+// AccessTokenDeniedGroups 不接受个人访问令牌的路由分组，令牌管理和第三方绑定只能使用登录令牌，
+// 首次安装接口不需要登录，不在权限范围内
+var AccessTokenDeniedGroups = []string{"token", "oauth"}
+
+// AccessTokenDeniedRoutes 不接受个人访问令牌的路由，修改密码、邮箱、角色和重新验证身份只能使用登录令牌，
+// 令牌泄露后无法借此长期接管账号
+var AccessTokenDeniedRoutes = []string{
+	"/user/auth/reset",
+	"/user/auth/send_verify",
+	"/user/auth/verify",
+	"/user/admin/update",
+	"/user/admin/update_role",
+	"/user/admin/login_locks",
+	"/backup/admin/reauth",
+}
+
+// 第三方登录
+const (
+	OAuthStateKey    = "OAUTH_STATE:"   //第三方登录state的key
+	OAuthStateExpire = time.Minute * 10 //第三方登录state过期时间
+	OAuthNonceCookie = "oauth_nonce"    //发起授权的浏览器保存的随机数，回调时与state校验
+)
+
+// 定时任务相关
+const (
+	JobPausedKey    = "JOB_PAUSED"     //旧版本保存已暂停任务的key，启动时迁移到数据库
+	JobLockExpire   = time.Minute      //任务锁的租约时间，执行期间每1/3租约时间续期一次
+	JobLeaderLock   = "job_leader"     //选举调度实例使用的锁名称
+	JobLeaderExpire = time.Second * 30 //调度实例的租约时间
+	JobSlotKey      = "JOB_SLOT:"      //定时任务每个调度时间点的执行权，后接 任务名称:时间戳，保留到下一个时间点
+)
+
+// 备份相关
+const (
+	BackupLock           = "backup"                 //定时备份的任务名称，也是备份和恢复共用的任务锁
+	BackupProgressKey    = "BACKUP_PROGRESS:"       //备份进度，后接备份ID
+	BackupProgressExpire = time.Hour * 24           //备份进度的保留时间
+	BackupDownloadKey    = "BACKUP_DOWNLOAD:"       //重新验证身份后签发的一次性下载令牌，后接令牌
+	BackupDownloadExpire = time.Minute * 5          //下载令牌的有效期
+	BackupPassphraseEnv  = "BLOG_BACKUP_PASSPHRASE" //备份加密口令的环境变量，优先于配置文件
+)
+
+// 首次安装相关
+const (
+	SetupTokenEnv       = "BLOG_SETUP_TOKEN" //首次安装令牌的环境变量，为空时启动时随机生成并打印到日志
+	SetupTokenKey       = "SETUP_TOKEN"      //随机生成的安装令牌，多个实例共用
+	SetupLockKey        = 7_326_189_042      //创建站长账号时使用的 PostgreSQL 事务级咨询锁，多个实例同时安装时只有一个成功
+	LegacyAdminUsername = "2528959216"       //旧版本启动时写入的超级管理员账号
+)
+
+// 文章导入导出相关
+const (
+	RemoteImageTimeout = time.Second * 30 //导入和导出时下载远程图片的超时时间
+	ImportTextLimit    = 255              //导入的标题和描述的最大长度（字符数）
+)
+
+// SQL 控制台
+const (
+	SQLConsoleTimeout     = time.Second * 10 //语句默认超时时间
+	SQLConsolePageSize    = 50               //查询结果默认每页行数
+	SQLConsolePreviewRows = 20               //写入模式最多返回的影响行数
+)
+
+// 健康检查与关闭
+const (
+	HealthCheckTimeout  = time.Second * 3  //每个依赖的探测超时时间
+	HealthCacheExpire   = time.Second * 5  //就绪检查结果的缓存时间，避免频繁请求反复探测依赖
+	ShutdownTimeout     = time.Second * 30 //关闭时等待请求和后台任务完成的默认时间
+	ShutdownHookTimeout = time.Second * 10 //关闭时每个收尾操作（如写入浏览量）的默认时间
 )
 
 // 博客相关缓存
 const (
 	BlogMapKey         = "BLOG_MAP"       //缓存博客详情的key
+	BlogMissKey        = "BLOG_MISS:"     //不存在的博客ID的负缓存，后接博客ID
 	RecommendKey       = "RECOMMEND_BLOG" //缓存推荐博客的key
 	HotBlogKey         = "HOT_BLOG"       //缓存热门博客的key
 	HotBlogExpire      = time.Hour * 24   //热门博客过期时间
@@ -36,6 +149,28 @@ const (
 	BlogEyeCountMapKey = "EYE_MAP"        //缓存博客的浏览量
 	EyeView            = "EYE_VIEW"       //统计今日浏览量
 	PinnedBlog         = "PINNED_BLOG"    //置顶博客
+	BlogDailyPvKey     = "BLOG_PV:"       //每日各博客的浏览量，后接日期
+	BlogDailyUvKey     = "BLOG_UV:"       //每日单篇博客的独立访客，后接日期:博客ID
+	SiteDailyUvKey     = "SITE_UV:"       //每日全站的独立访客，后接日期
+
+	DailyViewExpire   = time.Hour * 24 * 3 //每日浏览统计在缓存中的保留时间
+	DailyViewSyncDays = 3                  //每次落库同步最近几天的浏览统计
+	ViewTrendDays     = 30                 //浏览趋势默认查询的天数
+	ViewTrendMaxDays  = 366                //浏览趋势最多查询的天数
+
+	ViewSourceKey       = "VIEW_SOURCE:" //每小时的浏览来源统计，后接小时开始的时间戳
+	ViewSourceExpire    = time.Hour * 24 //浏览来源统计在缓存中的保留时间
+	ViewSourceSyncHours = 3              //每次汇总同步最近几个小时的浏览来源
+	ViewSourceTopCount  = 10             //浏览来源默认返回的条数
+	ViewSourceMaxCount  = 100            //浏览来源最多返回的条数
+	ReferrerMaxLength   = 2048           //来源页面地址的最大长度，超过时不解析
+	ReferrerHostLength  = 64             //来源域名的最大长度，超过时归为其他
+
+	BlogInfoSoftExpire   = time.Minute * 30 //博客详情软过期时间，过期后返回旧值并在后台刷新
+	BlogMissExpire       = time.Minute      //不存在的博客ID的负缓存时间
+	HotBlogSoftExpire    = time.Hour        //热门博客软过期时间
+	LatestBlogSoftExpire = time.Minute * 10 //最新博客软过期时间
+	PageInfoSoftExpire   = time.Minute * 10 //博客列表软过期时间
 )
 
 // 分类缓存键集合
@@ -54,13 +189,15 @@ const (
 
 // 专题缓存集合
 const (
-	TopicPageKey    = "TOPIC_PAGE:"      //缓存专题页的key
+	TopicPageKey    = "TOPIC_PAGE"       //缓存专题页的命名空间
 	TopicPageExpire = time.Hour * 24 * 3 //专题页过期时间
 	TopicMapKey     = "TOPIC_MAP"        //专题简要信息的key
 )
 
 // Count
 const (
+	ScanBatchCount      = 100  //SCAN 每批检查的键数量
+	ScanMaxCount        = 1000 //SCAN 每批最多检查的键数量
 	RecommendBlogCount  = 4
 	TopicPageCount      = 20
 	ArchivePageCount    = 15
@@ -93,10 +230,13 @@ var GetToken = func(id int) string {
 	return ""
 }
 
+// GetAccessToken 根据个人访问令牌获取用户信息和权限范围的函数变量
+var GetAccessToken = func(token string, ip string) (*models.User, []string) {
+	return nil, nil
+}
+
 const (
 	WebSiteConfigKey = "WEB_SITE_CONFIG" //缓存网站配置信息
 
 	TmpBlogKey = "TMP_BLOG:" //缓存临时的博客
-
-	TarKey = "TAR:"
 )
@@ -0,0 +1,44 @@
+package configs
+
+import (
+	"blog/pkg/common"
+	"os"
+)
+
+// BackupConfig 备份配置
+type BackupConfig struct {
+	Dir         string             `yaml:"dir" json:"dir"`                 //备份文件保存目录，默认 backups
+	Compression string             `yaml:"compression" json:"compression"` //压缩方式 gzip 或 zstd，默认 gzip
+	Include     []string           `yaml:"include" json:"include"`         //只备份匹配的文件，为空时备份全部
+	Exclude     []string           `yaml:"exclude" json:"exclude"`         //排除匹配的文件
+	Schedule    string             `yaml:"schedule" json:"schedule"`       //定时备份的cron表达式，为空时不定时备份
+	KeepDaily   int                `yaml:"keepDaily" json:"keepDaily"`     //保留最近几天每天最新的一份备份
+	KeepWeekly  int                `yaml:"keepWeekly" json:"keepWeekly"`   //保留最近几周每周最新的一份备份，与 keepDaily 都为0时不清理
+	Remote      BackupRemoteConfig `yaml:"remote" json:"remote"`           //备份完成后复制到的第二存储位置
+	Passphrase  string             `yaml:"passphrase" json:"-"`            //加密口令，不为空时备份使用 AES-256-GCM 加密
+}
+
+// BackupRemoteConfig 备份的第二存储位置
+type BackupRemoteConfig struct {
+	Type     string `yaml:"type" json:"type"`  //存储类型 dir 或 webdav，为空时不复制
+	Dir      string `yaml:"dir" json:"dir"`    //type 为 dir 时的目录
+	Url      string `yaml:"url" json:"url"`    //type 为 webdav 时的目录地址
+	Username string `yaml:"username" json:"-"` //webdav 用户名
+	Password string `yaml:"password" json:"-"` //webdav 密码
+}
+
+// GetDir 备份文件保存目录，未配置时使用默认值
+func (b BackupConfig) GetDir() string {
+	if b.Dir == "" {
+		return "backups"
+	}
+	return b.Dir
+}
+
+// GetPassphrase 备份加密口令，环境变量优先于配置文件，都为空时不加密
+func (b BackupConfig) GetPassphrase() string {
+	if passphrase := os.Getenv(common.BackupPassphraseEnv); passphrase != "" {
+		return passphrase
+	}
+	return b.Passphrase
+}
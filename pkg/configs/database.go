@@ -1,10 +1,8 @@
 package configs
 
 import (
-	"blog/internal/models"
-	"blog/internal/utils"
-	"blog/pkg/common"
 	"blog/pkg/helper"
+	"blog/pkg/metrics"
 	"fmt"
 	"strings"
 	"time"
@@ -28,7 +26,7 @@ type DbConfig struct {
 	Username   string `yaml:"username" json:"username"`     // 数据库用户名
 	Password   string `yaml:"password" json:"password"`     // 数据库密码
 	Dbname     string `yaml:"dbname" json:"dbname"`         // 数据库名称
-	AutoCreate bool   `yaml:"autoCreate" json:"autoCreate"` // 是否自动创建表
+	AutoCreate bool   `yaml:"autoCreate" json:"autoCreate"` // 启动时是否自动执行未执行的迁移，关闭时需要先执行 migrate up
 }
 
 // getDataBaseDSN 根据 DbConfig 返回数据库连接字符串
@@ -99,50 +97,22 @@ func LoadDBConfig(dbConfig DbConfig) {
 	// 设置最大连接数
 	connection.SetMaxOpenConns(dbConfig.MaxSize)
 
+	// 记录数据库操作耗时
+	helper.CheckError(db.Use(metrics.GormPlugin{}), "注册数据库指标插件失败")
+
 	DB = db // 将数据库实例赋值给全局变量
+}
 
-	if dbConfig.AutoCreate {
-		DB.AutoMigrate(&models.User{},
-			&models.Role{},
-			&models.FileInfo{},
-			&models.Blog{},
-			&models.EyeView{},
-			&models.SystemLogInfo{},
-			&models.EditBlog{},
-		)
-
-		var roles = []models.Role{
-			{
-				ID:          uint(common.UserRoleId),
-				Name:        "USER",
-				Description: "普通用户",
-			},
-			{
-				ID:          uint(common.AdminRoleId),
-				Name:        "ADMIN",
-				Description: "管理员",
-			},
-			{
-				ID:          uint(common.SuperAdminRoleId),
-				Name:        "SUPER_ADMIN",
-				Description: "超级管理员",
-			},
-		}
-
-		DB.Model(&models.Role{}).Save(&roles)
-
-		var user = models.User{
-			Username: "2528959216",
-			Password: utils.EncryptPassword("shuyu2001"),
-			Email:    "shuyuzheng19@gmail.com",
-			NickName: "书宇",
-			Avatar:   "https://www.shuyuz.com/avatar.jpg",
-			RoleID:   3,
-			Status:   true,
-		}
-
-		DB.Model(&models.User{}).Save(&user)
+// CloseDB 关闭数据库连接池
+func CloseDB() error {
+	if DB == nil {
+		return nil
+	}
+	db, err := DB.DB()
+	if err != nil {
+		return err
 	}
+	return db.Close()
 }
 
 func DeleteData(tabeName string, uid *int, ids []int64) error {
@@ -0,0 +1,17 @@
+package configs
+
+// MetricsConfig Prometheus 指标配置
+type MetricsConfig struct {
+	Enable   bool     `yaml:"enable" json:"enable"`     //是否开启指标接口
+	Path     string   `yaml:"path" json:"path"`         //指标接口路径，默认 /metrics
+	Token    string   `yaml:"token" json:"-"`           //访问令牌，通过 Authorization: Bearer 或 token 参数传入
+	AllowIps []string `yaml:"allowIps" json:"allowIps"` //允许访问的IP或网段，令牌和IP都未配置时只允许本机访问
+}
+
+// GetPath 指标接口路径，未配置时使用默认值
+func (m MetricsConfig) GetPath() string {
+	if m.Path == "" {
+		return "/metrics"
+	}
+	return m.Path
+}
@@ -33,6 +33,12 @@ type GlobalConfig struct {
 	//搜索配置
 	Search      MeiliSearchConfig `yaml:"meilisearch" json:"meilisearch"`
 	DataBaseKey string            `yaml:"databaseKey" json:"-"`
+	//第三方登录配置
+	OAuth OAuthConfig `yaml:"oauth" json:"oauth"`
+	//Prometheus指标配置
+	Metrics MetricsConfig `yaml:"metrics" json:"metrics"`
+	//备份配置
+	Backup BackupConfig `yaml:"backup" json:"backup"`
 }
 
 // LoadGlobalConfig 加载全局配置
@@ -0,0 +1,47 @@
+package configs
+
+import (
+	"blog/internal/oauth"
+	"log"
+)
+
+// OAuthConfig 第三方登录配置
+type OAuthConfig struct {
+	CallbackURL string                `yaml:"callbackUrl" json:"callbackUrl"` //回调地址前缀，如 https://blog.shuyuz.com/api/v1/oauth
+	RedirectURL string                `yaml:"redirectUrl" json:"redirectUrl"` //登录完成后跳转的前端地址，为空时直接返回JSON
+	Providers   []OAuthProviderConfig `yaml:"providers" json:"providers"`
+}
+
+// OAuthProviderConfig 第三方登录提供方配置
+type OAuthProviderConfig struct {
+	Name         string   `yaml:"name" json:"name"`     //提供方名称，会出现在路由中
+	Type         string   `yaml:"type" json:"type"`     //类型 oidc 或 github
+	Issuer       string   `yaml:"issuer" json:"issuer"` //OIDC issuer，仅 oidc 类型需要
+	ClientID     string   `yaml:"clientId" json:"clientId"`
+	ClientSecret string   `yaml:"clientSecret" json:"-"`
+	Scopes       []string `yaml:"scopes" json:"scopes"`
+	TrustEmail   bool     `yaml:"trustEmail" json:"trustEmail"` //是否信任提供方验证过的邮箱，信任时首次登录按邮箱绑定已注册的普通用户
+}
+
+var OAUTH = map[string]oauth.Provider{}
+
+// OAUTH_TRUST_EMAIL 信任邮箱的提供方，未列出的提供方不按邮箱自动绑定
+var OAUTH_TRUST_EMAIL = map[string]bool{}
+
+// LoadOAuthConfig 加载第三方登录提供方
+func LoadOAuthConfig(conf OAuthConfig) {
+	for _, p := range conf.Providers {
+		var provider oauth.Provider
+		switch p.Type {
+		case "github":
+			provider = oauth.NewGithubProvider(p.ClientID, p.ClientSecret)
+		case "oidc":
+			provider = oauth.NewOIDCProvider(p.Name, p.Issuer, p.ClientID, p.ClientSecret, p.Scopes)
+		default:
+			log.Printf("未知的第三方登录类型: name=%s type=%s", p.Name, p.Type)
+			continue
+		}
+		OAUTH[provider.Name()] = provider
+		OAUTH_TRUST_EMAIL[provider.Name()] = p.TrustEmail
+	}
+}
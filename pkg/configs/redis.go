@@ -1,27 +1,42 @@
 package configs
 
 import (
-	"blog/pkg/helper"
+	"blog/pkg/cache"
+	"blog/pkg/metrics"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/go-redis/redis"
 )
 
 type RedisConfig struct {
-	Host     string `yaml:"host" json:"host"`
-	Port     int    `yaml:"port" json:"port"`
-	Password string `yaml:"password" json:"password"`
-	Db       int    `yaml:"db" json:"db"`
-	MaxSize  int    `yaml:"max_size" json:"maxSize"`
-	MinIdle  int    `yaml:"min_idle" json:"minIdle"`
-	Timeout  int    `yaml:"timeout"`
+	Host       string `yaml:"host" json:"host"`
+	Port       int    `yaml:"port" json:"port"`
+	Password   string `yaml:"password" json:"password"`
+	Db         int    `yaml:"db" json:"db"`
+	MaxSize    int    `yaml:"max_size" json:"maxSize"`
+	MinIdle    int    `yaml:"min_idle" json:"minIdle"`
+	Timeout    int    `yaml:"timeout"`
+	Disable    bool   `yaml:"disable" json:"disable"`        //不使用redis，只使用进程内缓存，适合开发和单机部署
+	MemorySize int    `yaml:"memory_size" json:"memorySize"` //进程内缓存最多保存的键数量
 }
 
 var REDIS *redis.Client
 
+// CACHE 全局缓存，Redis 不可用时自动降级到进程内缓存
+var CACHE cache.Cache
+
 // LoadRedis 加载redis配置
 func LoadRedis(redisConfig RedisConfig) {
+	memory := cache.NewMemoryCache(redisConfig.MemorySize)
+
+	if redisConfig.Disable {
+		log.Println("未启用redis，使用进程内缓存")
+		CACHE = memory
+		return
+	}
+
 	REDIS = redis.NewClient(&redis.Options{
 		Addr:         fmt.Sprintf("%s:%d", redisConfig.Host, redisConfig.Port),
 		DB:           redisConfig.Db,
@@ -31,11 +46,45 @@ func LoadRedis(redisConfig RedisConfig) {
 		PoolTimeout:  time.Duration(redisConfig.Timeout) * time.Second,
 	})
 
-	var err = REDIS.Ping().Err()
+	observeRedisErrors(REDIS)
+
+	fallback := cache.NewFallbackCache(cache.NewRedisCache(REDIS), memory, time.Second*5)
+
+	if err := REDIS.Ping().Err(); err != nil {
+		fallback.MarkDown(fmt.Errorf("连接redis失败: %w", err))
+	}
+
+	CACHE = fallback
+}
 
-	if err != nil {
-		helper.CheckError(err, "连接redis失败")
+// CloseRedis 关闭缓存和redis连接池
+func CloseRedis() error {
+	if CACHE == nil {
+		return nil
 	}
+	return CACHE.Close()
+}
 
-	// REDIS.Del(REDIS.Keys("*").Val()...)
+// observeRedisErrors 统计 Redis 命令错误，键不存在不计入
+func observeRedisErrors(client *redis.Client) {
+	client.WrapProcess(func(old func(cmd redis.Cmder) error) func(cmd redis.Cmder) error {
+		return func(cmd redis.Cmder) error {
+			err := old(cmd)
+			if err != nil && err != redis.Nil {
+				metrics.RedisErrors.WithLabelValues(cmd.Name()).Inc()
+			}
+			return err
+		}
+	})
+	client.WrapProcessPipeline(func(old func(cmds []redis.Cmder) error) func(cmds []redis.Cmder) error {
+		return func(cmds []redis.Cmder) error {
+			err := old(cmds)
+			for _, cmd := range cmds {
+				if cmdErr := cmd.Err(); cmdErr != nil && cmdErr != redis.Nil {
+					metrics.RedisErrors.WithLabelValues(cmd.Name()).Inc()
+				}
+			}
+			return err
+		}
+	})
 }
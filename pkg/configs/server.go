@@ -1,16 +1,42 @@
 package configs
 
+import (
+	"blog/pkg/common"
+	"time"
+)
+
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Cron         bool       `yaml:"cron" json:"cron"`                 //是否开启定时任务
-	Addr         string     `yaml:"addr" json:"addr"`                 //要监听的IP端口
-	ApiPrefix    string     `yaml:"apiPrefix" json:"apiPrefix"`       //全局API的前缀
-	ReadTimeOut  int        `yaml:"readTimeOut" json:"readTimeOut"`   //读取超时时间
-	WriteTimeOut int        `yaml:"writeTimeOut" json:"writeTimeOut"` //写入超时时间
-	Name         string     `yaml:"name" json:"name"`                 //APP名称
-	MaxSize      int        `yaml:"maxSize" json:"maxSize"`           //请求体最大大小
-	Cors         CorsConfig `yaml:"cors" json:"-"`
-	Env          string     `yaml:"env"`
+	Cron                bool       `yaml:"cron" json:"cron"`                               //是否开启定时任务
+	CronMode            string     `yaml:"cronMode" json:"cronMode"`                       //多实例时的调度方式：lock 每个实例抢占任务锁，leader 选举一个实例调度
+	Addr                string     `yaml:"addr" json:"addr"`                               //要监听的IP端口
+	ApiPrefix           string     `yaml:"apiPrefix" json:"apiPrefix"`                     //全局API的前缀
+	ReadTimeOut         int        `yaml:"readTimeOut" json:"readTimeOut"`                 //读取超时时间
+	WriteTimeOut        int        `yaml:"writeTimeOut" json:"writeTimeOut"`               //写入超时时间
+	Name                string     `yaml:"name" json:"name"`                               //APP名称
+	MaxSize             int        `yaml:"maxSize" json:"maxSize"`                         //请求体最大大小
+	ShutdownTimeout     int        `yaml:"shutdownTimeout" json:"shutdownTimeout"`         //关闭时等待请求和后台任务完成的最长时间，单位秒
+	ShutdownHookTimeout int        `yaml:"shutdownHookTimeout" json:"shutdownHookTimeout"` //关闭时每个收尾操作的最长时间，单位秒，不占用 ShutdownTimeout
+	ProxyHeader         string     `yaml:"proxyHeader" json:"proxyHeader"`                 //反向代理传递客户端IP的请求头，如 X-Real-IP
+	TrustedProxies      []string   `yaml:"trustedProxies" json:"trustedProxies"`           //信任的反向代理IP或网段，只有来自这些地址的请求才读取 ProxyHeader
+	Cors                CorsConfig `yaml:"cors" json:"-"`
+	Env                 string     `yaml:"env"`
+}
+
+// GetShutdownTimeout 关闭时的等待时间，未配置时使用默认值
+func (s ServerConfig) GetShutdownTimeout() time.Duration {
+	if s.ShutdownTimeout <= 0 {
+		return common.ShutdownTimeout
+	}
+	return time.Duration(s.ShutdownTimeout) * time.Second
+}
+
+// GetShutdownHookTimeout 关闭时每个收尾操作的等待时间，未配置时使用默认值
+func (s ServerConfig) GetShutdownHookTimeout() time.Duration {
+	if s.ShutdownHookTimeout <= 0 {
+		return common.ShutdownHookTimeout
+	}
+	return time.Duration(s.ShutdownHookTimeout) * time.Second
 }
 
 type CorsConfig struct {
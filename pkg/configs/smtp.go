@@ -1,8 +1,32 @@
 package configs
 
+import (
+	"blog/pkg/common"
+	"time"
+)
+
 type EmailConfig struct {
-	Username string `yaml:"username" json:"username"`
-	Password string `yaml:"password" json:"password"`
-	Host     string `yaml:"host" json:"host"`
-	Addr     string `yaml:"addr" json:"addr"`
+	Username       string `yaml:"username" json:"username"`
+	Password       string `yaml:"password" json:"password"`
+	Host           string `yaml:"host" json:"host"`
+	Addr           string `yaml:"addr" json:"addr"`
+	Verify         bool   `yaml:"verify" json:"verify"`                 //是否开启邮箱验证
+	CodeExpire     int    `yaml:"codeExpire" json:"codeExpire"`         //验证码有效期，单位分钟
+	ResendInterval int    `yaml:"resendInterval" json:"resendInterval"` //重新发送验证码的间隔，单位秒
+}
+
+// GetCodeExpire 验证码有效期，未配置时使用默认值
+func (e EmailConfig) GetCodeExpire() time.Duration {
+	if e.CodeExpire <= 0 {
+		return common.EmailCodeExpire
+	}
+	return time.Duration(e.CodeExpire) * time.Minute
+}
+
+// GetResendInterval 重新发送验证码的间隔，未配置时使用默认值
+func (e EmailConfig) GetResendInterval() time.Duration {
+	if e.ResendInterval <= 0 {
+		return common.EmailResendInterval
+	}
+	return time.Duration(e.ResendInterval) * time.Second
 }
@@ -13,6 +13,9 @@ var Logger *zap.Logger
 func InitLogger(config configs.LoggerConfig) {
 	Logger = configs.LoadLogger(config) // 调用之前的 LoadLogger 函数
 
+	// 替换 zap 的全局日志，pkg/cache 等不能引用本包的底层包通过 zap.L() 写入同一个日志
+	zap.ReplaceGlobals(Logger)
+
 	defer Logger.Sync()
 }
 
@@ -50,3 +53,10 @@ func InfoView(msg string) {
 func Fatal(msg string, fields ...zap.Field) {
 	Logger.Fatal(msg, fields...)
 }
+
+// Sync 将缓冲的日志写入文件，退出前调用
+func Sync() {
+	if Logger != nil {
+		Logger.Sync()
+	}
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Registry 应用的指标注册表，通过 /metrics 以 Prometheus 文本格式导出
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests HTTP 请求数
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "blog_http_requests_total",
+		Help: "HTTP 请求数",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration HTTP 请求耗时
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "blog_http_request_duration_seconds",
+		Help:    "HTTP 请求耗时",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// DBQueryDuration 数据库操作耗时
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "blog_db_query_duration_seconds",
+		Help:    "数据库操作耗时",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	// DBQueryErrors 数据库操作错误数，不包含记录不存在
+	DBQueryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "blog_db_query_errors_total",
+		Help: "数据库操作错误数",
+	}, []string{"operation", "table"})
+
+	// RedisErrors Redis 命令错误数，不包含键不存在
+	RedisErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "blog_redis_errors_total",
+		Help: "Redis 命令错误数",
+	}, []string{"command"})
+
+	// CacheRequests 缓存读取次数，result 为 hit 或 miss
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "blog_cache_requests_total",
+		Help: "缓存读取次数",
+	}, []string{"cache", "result"})
+
+	// JobRuns 定时任务执行次数
+	JobRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "blog_job_runs_total",
+		Help: "定时任务执行次数",
+	}, []string{"job", "status"})
+
+	// JobDuration 定时任务执行耗时
+	JobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "blog_job_duration_seconds",
+		Help:    "定时任务执行耗时",
+		Buckets: []float64{.1, .5, 1, 5, 15, 60, 300, 900},
+	}, []string{"job"})
+
+	// UploadBytes 上传文件字节数
+	UploadBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "blog_upload_bytes_total",
+		Help: "上传文件字节数",
+	}, []string{"backend"})
+
+	// SearchDuration Meilisearch 请求耗时，请求失败时 status 为 error
+	SearchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "blog_search_request_duration_seconds",
+		Help:    "Meilisearch 请求耗时",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "status"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests, HTTPDuration,
+		DBQueryDuration, DBQueryErrors,
+		RedisErrors, CacheRequests,
+		JobRuns, JobDuration,
+		UploadBytes, SearchDuration,
+	)
+}
+
+// Since 返回从 start 到现在经过的秒数
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
@@ -2,7 +2,10 @@ package smail
 
 import (
 	"blog/pkg/configs"
+	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/smtp"
 
 	"github.com/jordan-wright/email"
@@ -38,3 +41,26 @@ func SendEmail(to string, subject string, isHTML bool, text string) error {
 
 	return nil
 }
+
+// Ping 检查 SMTP 服务器是否可连接，只读取欢迎信息不做认证
+func Ping(ctx context.Context) error {
+	if config.Addr == "" {
+		return errors.New("未配置SMTP服务器")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", config.Addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"blog/internal/utils"
+	"blog/pkg/configs"
+	"blog/pkg/logger"
+	"blog/pkg/smail"
+	"fmt"
+	"log"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// resource 子命令执行前需要初始化的资源
+type resource uint
+
+const (
+	useDB     resource = 1 << iota // 数据库，dev 环境不连接
+	useCache                       // Redis，未启用时使用进程内缓存
+	useMail                        // SMTP邮箱
+	useIpDB                        // IP数据库
+	useUpload                      // 上传目录
+	useSearch                      // 搜索
+	useOAuth                       // 第三方登录
+
+	useAll = useDB | useCache | useMail | useIpDB | useUpload | useSearch | useOAuth
+)
+
+// setup 加载全局配置和日志，再按需初始化子命令用到的连接和资源
+func setup(needs resource) {
+	// 加载全局配置
+	configs.LoadGlobalConfig("application.yml")
+
+	// 初始化日志系统
+	logger.InitLogger(configs.CONFIG.Logger)
+
+	// 确保 LOGGER 已经初始化
+	if logger.Logger == nil {
+		log.Fatal("Logger is not initialized")
+	}
+
+	logger.Info("全局配置加载成功")
+	logger.Info("日志初始化成功")
+
+	if needs&useMail != 0 {
+		// 初始化SMTP邮箱
+		smail.InitSmtp(configs.CONFIG.Mail)
+		logger.Info("SMTP邮箱初始化成功")
+	}
+
+	if needs&useDB != 0 && configs.CONFIG.Env != "dev" {
+		// 初始化数据库
+		log.Println("加载数据库")
+		configs.LoadDBConfig(configs.CONFIG.Db)
+		log.Println("数据库加载完毕")
+	}
+
+	if needs&useCache != 0 {
+		log.Println("加载Redis")
+		configs.LoadRedis(configs.CONFIG.Redis)
+		log.Println("Redis加载完毕")
+	}
+
+	if needs&useIpDB != 0 {
+		// 加载IP数据库
+		log.Println("加载IP数据库")
+		utils.LoadIpDB(configs.CONFIG.IpDbPath)
+		log.Println("IP数据库加载完毕")
+	}
+
+	if needs&useUpload != 0 {
+		// 创建上传目录
+		if err := os.MkdirAll(configs.CONFIG.Upload.Path, os.ModePerm); err != nil {
+			log.Fatalf("创建上传目录失败: %v", err)
+		}
+		log.Println("上传目录创建完毕")
+	}
+
+	if needs&useSearch != 0 {
+		// 加载搜索配置
+		configs.LoadSearchConfig(configs.CONFIG.Search)
+		log.Println("搜索配置加载完毕")
+	}
+
+	if needs&useOAuth != 0 {
+		// 加载第三方登录配置
+		configs.LoadOAuthConfig(configs.CONFIG.OAuth)
+		log.Println("第三方登录配置加载完毕")
+	}
+}
+
+// teardown 子命令执行完成后关闭连接并写入缓冲的日志
+func teardown() {
+	if err := configs.CloseDB(); err != nil {
+		logger.Error("关闭数据库连接失败", zap.Error(err))
+	}
+	if err := configs.CloseRedis(); err != nil {
+		logger.Error("关闭缓存连接失败", zap.Error(err))
+	}
+	logger.Sync()
+}
+
+// requireDB 子命令需要数据库但未连接时输出提示
+func requireDB() bool {
+	if configs.DB == nil {
+		fmt.Fprintln(os.Stderr, "未连接数据库，请检查配置文件中的环境和数据库配置")
+		return false
+	}
+	return true
+}